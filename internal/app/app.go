@@ -6,25 +6,38 @@ import (
 	"fmt"
 	jwt "monitoring_backend/internal/auth"
 	"monitoring_backend/internal/config"
+	"monitoring_backend/internal/domain"
+	"monitoring_backend/internal/http/handlers/attendance_policy"
 	"monitoring_backend/internal/http/handlers/auth"
+	"monitoring_backend/internal/http/handlers/camera"
+	"monitoring_backend/internal/http/handlers/class"
+	"monitoring_backend/internal/http/handlers/class_session"
+	"monitoring_backend/internal/http/handlers/dead_letter"
 	"monitoring_backend/internal/http/handlers/service/dataset"
 	"monitoring_backend/internal/http/handlers/visits"
 	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/ingest"
 	"monitoring_backend/internal/service/services"
 
+	"monitoring_backend/internal/http/handlers/attendance_override"
 	"monitoring_backend/internal/http/handlers/department"
 	"monitoring_backend/internal/http/handlers/group"
 	lecture2 "monitoring_backend/internal/http/handlers/lecture"
-	"monitoring_backend/internal/http/handlers/practice"
+	"monitoring_backend/internal/http/handlers/recognition_threshold"
+	"monitoring_backend/internal/http/handlers/room"
+	"monitoring_backend/internal/http/handlers/schedule"
+	"monitoring_backend/internal/http/handlers/sighting_review"
 	"monitoring_backend/internal/http/handlers/student_group"
 	"monitoring_backend/internal/http/handlers/subject"
 	"monitoring_backend/internal/http/handlers/user"
 	"monitoring_backend/internal/lecture"
+	"monitoring_backend/internal/rabbit"
 	"monitoring_backend/internal/repository/postgres"
 
 	"monitoring_backend/internal/service"
 	"monitoring_backend/internal/ws"
 	"net/http"
+	"sync"
 	"time"
 
 	httpHandler "monitoring_backend/internal/http/handlers"
@@ -37,6 +50,21 @@ type App struct {
 	cfg    *config.Config
 	db     *pgxpool.Pool
 	server *http.Server
+	// у каждого вида занятий свой менеджер консьюмеров, writer и (в режиме topic) общая очередь
+	managers      *lecture.Registry
+	writers       []*ingest.BatchWriter
+	topicSources  []*rabbit.TopicSource // пусто в режиме queue
+	wsHub         *ws.Hub
+	pgBroadcaster *ws.PGBroadcaster // nil при ws.broadcaster = "memory"
+	deadLetters   postgres.DeadLetterRepository
+	broker        *rabbit.Broker
+	scheduler     *lecture.Scheduler // nil, если scheduler.enabled = false
+	cameraMonitor *lecture.CameraMonitor
+
+	// фоновые консьюмеры, не привязанные к лекциям: архиватор dead-letter, общая topic-очередь,
+	// слушатель LISTEN/NOTIFY для WebSocket, планировщик занятий и контроль камер
+	bgCancel context.CancelFunc
+	bg       sync.WaitGroup
 }
 
 func New(cfg *config.Config, db *pgxpool.Pool, jwtManager *jwt.JWTManager) *App {
@@ -48,24 +76,60 @@ func New(cfg *config.Config, db *pgxpool.Pool, jwtManager *jwt.JWTManager) *App
 	groupRepo := postgres.NewGroupRepository(db)
 	sgRepo := postgres.NewStudentGroupRepository(db)
 	subjRepo := postgres.NewSubjectRepository(db)
-	lecRepo := postgres.NewLectureRepository(db)
-	lecGroupRepo := postgres.NewLectureGroupRepository(db)
-	pracRepo := postgres.NewPracticeRepository(db)
-	pracGroupRepo := postgres.NewPracticeGroupRepository(db)
+	classRepo := postgres.NewClassRepository(db)
+	classGroupRepo := postgres.NewClassGroupRepository(db)
 	datasetRepo := postgres.NewDatasetRepository(db)
-	lectureVisitsRepo := postgres.NewLectureVisitsRepository(db)
+	lectureVisitsRepo := postgres.NewClassVisitsRepository(db, domain.ClassLecture)
+	practiceVisitsRepo := postgres.NewClassVisitsRepository(db, domain.ClassPractice)
+	deadLetterRepo := postgres.NewDeadLetterRepository(db)
+	attendancePolicyRepo := postgres.NewAttendancePolicyRepository(db)
+	scheduleRepo := postgres.NewClassScheduleRepository(db)
+	roomRepo := postgres.NewRoomRepository(db)
+	cameraStatusRepo := postgres.NewCameraStatusRepository(db)
+	thresholdRepo := postgres.NewRecognitionThresholdRepository(db)
+	sightingReviewRepo := postgres.NewSightingReviewRepository(db)
+	attendanceOverrideRepo := postgres.NewAttendanceOverrideRepository(db)
 
 	// services
-	visitsServ := service.NewVisitService(lectureVisitsRepo)
+	attendancePolicyServ := service.NewAttendancePolicyService(attendancePolicyRepo, domain.AttendancePolicy{
+		LateAfter:          cfg.Attendance.LateAfter,
+		LeftEarlyAfter:     cfg.Attendance.LeftEarlyAfter,
+		MinCoveragePercent: cfg.Attendance.MinCoveragePercent,
+	})
+	visitsServ := service.NewVisitService(domain.ClassLecture, lectureVisitsRepo, classRepo, attendancePolicyServ, attendanceOverrideRepo)
+	practiceVisitsServ := service.NewVisitService(domain.ClassPractice, practiceVisitsRepo, classRepo, attendancePolicyServ, attendanceOverrideRepo)
 	userServ := service.NewUserService(userRepo)
 	deptServ := service.NewDepartmentService(deptRepo)
 	groupServ := service.NewGroupService(groupRepo)
 	sgServ := service.NewStudentGroupService(sgRepo)
 	subjServ := service.NewSubjectService(db, subjRepo)
-	lecServ := service.NewLectureService(db, lecRepo, lecGroupRepo)
-	pracServ := service.NewPracticeService(db, pracRepo, pracGroupRepo)
+	lecServ := service.NewLectureService(db, classRepo, classGroupRepo)
+	classServ := service.NewClassService(classRepo, classGroupRepo)
+	roomServ := service.NewRoomService(roomRepo)
+	cameraServ := service.NewCameraService(cameraStatusRepo, cfg.Cameras.HeartbeatTimeout)
 	datasetServ := services.NewDatasetService(datasetRepo)
 	authServ := service.NewAuthService(userRepo, jwtManager)
+	classAccessServ := service.NewClassAccessService(classRepo)
+	thresholdServ := service.NewRecognitionThresholdService(thresholdRepo, domain.RecognitionThresholds{
+		Accept:        cfg.Recognition.AcceptThreshold,
+		Review:        cfg.Recognition.ReviewThreshold,
+		AcceptMissing: cfg.Recognition.AcceptWithoutConfidence,
+	})
+	attendanceOverrideServ := service.NewAttendanceOverrideService(classRepo, attendanceOverrideRepo)
+	scheduleServ := service.NewScheduleService(classRepo, scheduleRepo, cfg.Scheduler.StartBefore, cfg.Scheduler.StopAfter)
+
+	wsHub := ws.NewHub()
+	// при нескольких репликах события рассылаются через Postgres, иначе — прямо в hub
+	var (
+		broadcaster   ws.Broadcaster = wsHub
+		pgBroadcaster *ws.PGBroadcaster
+	)
+	if cfg.WS.Broadcaster == config.WSBroadcasterPostgres {
+		pgBroadcaster = ws.NewPGBroadcaster(db, wsHub, cfg.WS.NotifyChannel)
+		broadcaster = pgBroadcaster
+	}
+
+	sightingReviewServ := service.NewSightingReviewService(classRepo, sightingReviewRepo, broadcaster)
 
 	// handlers
 	userHandler := user.NewUserHandler(userServ)
@@ -74,28 +138,123 @@ func New(cfg *config.Config, db *pgxpool.Pool, jwtManager *jwt.JWTManager) *App
 	sgHandler := student_group.NewStudentGroupHandler(sgServ)
 	subjHandler := subject.NewSubjectHandler(subjServ)
 	lecHandler := lecture2.NewLectureHandler(lecServ)
-	pracHandler := practice.NewPracticeHandler(pracServ)
+	classHandler := class.NewClassHandler(classServ)
+	roomHandler := room.NewRoomHandler(roomServ)
+	cameraHandler := camera.NewCameraHandler(cameraServ)
+	attendancePolicyHandler := attendance_policy.NewAttendancePolicyHandler(attendancePolicyServ)
+	scheduleHandler := schedule.NewScheduleHandler(scheduleServ)
+	thresholdHandler := recognition_threshold.NewRecognitionThresholdHandler(thresholdServ)
+	sightingReviewHandler := sighting_review.NewSightingReviewHandler(sightingReviewServ)
+	attendanceOverrideHandler := attendance_override.NewAttendanceOverrideHandler(attendanceOverrideServ)
 	datasetHandler := dataset.NewDatasetHandler(datasetServ)
 	authHandler := auth.NewAuthHandler(authServ)
 	visitsHandler := visits.NewVisitsHandler(visitsServ)
+	classVisitsHandler := visits.NewClassVisitsHandler()
+	visitHistoryHandler := visits.NewVisitHistoryHandler(visitsServ, classAccessServ)
 
-	wsHub := ws.NewHub(visitsServ)
-	lectureManager := lecture.NewManager(wsHub, cfg.Rabbit.AMPQURL)
+	broker := rabbit.NewBroker(cfg.Rabbit)
+
+	// конвейер снапшотов собирается для каждого вида занятий одинаково
+	var (
+		managers     []*lecture.Manager
+		writers      []*ingest.BatchWriter
+		topicSources []*rabbit.TopicSource
+		pipeline     *ingest.Pipeline // конвейер лекций, его статистику отдаёт GET /api/service/ingest/stats
+		handlers     = make(map[domain.ClassKind]service.Replayer, len(domain.ClassKinds))
+	)
+	for _, kind := range domain.ClassKinds {
+		visitsRepo := lectureVisitsRepo
+		switch kind {
+		case domain.ClassLecture:
+			classVisitsHandler.Register(kind, visitsServ)
+		case domain.ClassPractice:
+			visitsRepo = practiceVisitsRepo
+			classVisitsHandler.Register(kind, practiceVisitsServ)
+		default:
+			visitsRepo = postgres.NewClassVisitsRepository(db, kind)
+			classVisitsHandler.Register(kind, service.NewVisitService(kind, visitsRepo, classRepo, attendancePolicyServ, attendanceOverrideRepo))
+		}
+
+		// события рассылаются только по лекциям: WebSocket-дашборд есть только у них
+		var (
+			ingestPublisher  ingest.Publisher
+			managerPublisher lecture.Publisher
+		)
+		if kind == domain.ClassLecture {
+			ingestPublisher = broadcaster
+			managerPublisher = broadcaster
+		}
+
+		writer := ingest.NewBatchWriter(visitsRepo, thresholdServ, cfg.Ingest.BatchSize, cfg.Ingest.FlushInterval)
+		kindPipeline := ingest.NewPipeline(writer, ingestPublisher, cameraStatusRepo, cfg.Ingest.MaxClockSkew, cfg.Ingest.MaxEventAge)
+		if kind == domain.ClassLecture {
+			pipeline = kindPipeline
+		}
+
+		var source lecture.Source
+		if cfg.Rabbit.Mode == config.RabbitModeTopic {
+			kindCfg := cfg.Rabbit
+			kindCfg.Queue = cfg.Rabbit.ClassQueue(string(kind))
+			topicSource := rabbit.NewTopicSource(broker, kindCfg, kind, kindPipeline)
+			topicSources = append(topicSources, topicSource)
+			source = topicSource
+		} else {
+			source = rabbit.NewQueueSource(broker, cfg.Rabbit, kind, kindPipeline)
+		}
+
+		sessions := postgres.NewClassSessionRepository(db, kind)
+		managers = append(managers, lecture.NewManager(kind, source, roomServ, sessions, managerPublisher))
+		writers = append(writers, writer)
+		handlers[kind] = kindPipeline
+	}
+	classManagers := lecture.NewRegistry(managers...)
+
+	var scheduler *lecture.Scheduler
+	if cfg.Scheduler.Enabled {
+		scheduler = lecture.NewScheduler(
+			classManagers,
+			scheduleRepo,
+			cfg.Scheduler.Interval,
+			cfg.Scheduler.StartBefore,
+			cfg.Scheduler.StopAfter,
+		)
+	}
+
+	// camera_down получают подписчики лекций в аудитории отказавшей камеры
+	cameraMonitor := lecture.NewCameraMonitor(cameraStatusRepo, broadcaster, cfg.Cameras.CheckInterval, cfg.Cameras.HeartbeatTimeout)
+
+	classSessionServ := service.NewClassSessionService(classRepo, scheduleRepo, classManagers)
+	classSessionHandler := class_session.NewClassSessionHandler(classSessionServ)
+
+	deadLetterServ := service.NewDeadLetterService(deadLetterRepo, handlers)
+	deadLetterHandler := dead_letter.NewDeadLetterHandler(deadLetterServ)
 
 	r := httpRouter.New(httpRouter.Dependencies{
-		AuthHandler:    authHandler,
-		Health:         health,
-		Department:     deptHandler,
-		Group:          groupHandler,
-		StudentGroup:   sgHandler,
-		Subject:        subjHandler,
-		Lecture:        lecHandler,
-		Practice:       pracHandler,
-		User:           userHandler,
-		WsHub:          wsHub,
-		LectureManager: lectureManager,
-		DataSet:        datasetHandler,
-		VisitsHandler:  visitsHandler,
+		AuthHandler:           authHandler,
+		Health:                health,
+		Department:            deptHandler,
+		Group:                 groupHandler,
+		StudentGroup:          sgHandler,
+		Subject:               subjHandler,
+		Lecture:               lecHandler,
+		Class:                 classHandler,
+		Room:                  roomHandler,
+		Camera:                cameraHandler,
+		User:                  userHandler,
+		WS:                    ws.Handler(wsHub, jwtManager, classAccessServ, lectureVisitsRepo, cfg.WS),
+		Events:                ws.EventsHandler(wsHub, jwtManager, classAccessServ, lectureVisitsRepo, cfg.WS),
+		Ingest:                pipeline,
+		ClassSessions:         classSessionHandler,
+		Schedule:              scheduleHandler,
+		SightingReviews:       sightingReviewHandler,
+		AttendanceOverrides:   attendanceOverrideHandler,
+		DeadLetters:           deadLetterHandler,
+		AttendancePolicies:    attendancePolicyHandler,
+		RecognitionThresholds: thresholdHandler,
+		DataSet:               datasetHandler,
+		VisitsHandler:         visitsHandler,
+		ClassVisitsHandler:    classVisitsHandler,
+		VisitHistory:          visitHistoryHandler,
 
 		JWTManager: jwtManager,
 	})
@@ -106,14 +265,23 @@ func New(cfg *config.Config, db *pgxpool.Pool, jwtManager *jwt.JWTManager) *App
 	corsMiddleware := middleware.NewCORS(middleware.CORSConfig{
 		AllowedOrigins: []string{"*"}, // в продакшене укажи домены
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "Last-Event-ID"},
 	})
 
 	handler = corsMiddleware(handler)
 
-	return &App{
-		cfg: cfg,
-		db:  db,
+	app := &App{
+		cfg:           cfg,
+		db:            db,
+		managers:      classManagers,
+		writers:       writers,
+		topicSources:  topicSources,
+		wsHub:         wsHub,
+		pgBroadcaster: pgBroadcaster,
+		deadLetters:   deadLetterRepo,
+		broker:        broker,
+		scheduler:     scheduler,
+		cameraMonitor: cameraMonitor,
 		server: &http.Server{
 			Addr:         fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port),
 			Handler:      handler,
@@ -122,9 +290,45 @@ func New(cfg *config.Config, db *pgxpool.Pool, jwtManager *jwt.JWTManager) *App
 			IdleTimeout:  10 * time.Second,
 		},
 	}
+	// SSE-запросы не завершаются сами, без этого server.Shutdown ждал бы их до таймаута
+	app.server.RegisterOnShutdown(wsHub.CloseStreams)
+
+	return app
 }
 
 func (a *App) Run(ctx context.Context) error {
+	// поднимаем консьюмеры занятий, которые были запущены до рестарта
+	if err := a.managers.Restore(ctx); err != nil {
+		return err
+	}
+
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	a.bgCancel = bgCancel
+
+	// переносим dead-letter queue в БД, чтобы упавшие сообщения можно было разобрать через API
+	a.goBackground(func() {
+		rabbit.StartDeadLetterArchiver(bgCtx, a.broker, a.cfg.Rabbit, a.deadLetters)
+	})
+	for _, topicSource := range a.topicSources {
+		a.goBackground(func() {
+			topicSource.Run(bgCtx)
+		})
+	}
+	if a.pgBroadcaster != nil {
+		a.goBackground(func() {
+			a.pgBroadcaster.Run(bgCtx)
+		})
+	}
+	// запускаем и останавливаем консьюмеры занятий по расписанию
+	if a.scheduler != nil {
+		a.goBackground(func() {
+			a.scheduler.Run(bgCtx)
+		})
+	}
+	a.goBackground(func() {
+		a.cameraMonitor.Run(bgCtx)
+	})
+
 	errCh := make(chan error, 1)
 
 	go func() {
@@ -133,14 +337,73 @@ func (a *App) Run(ctx context.Context) error {
 
 	select {
 	case <-ctx.Done():
-		shCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		return a.server.Shutdown(shCtx)
+		return a.shutdown()
 
 	case err := <-errCh:
 		if errors.Is(err, http.ErrServerClosed) {
-			return nil
+			err = nil
 		}
-		return err
+		return errors.Join(err, a.shutdown())
 	}
 }
+
+// shutdown останавливает приложение по порядку: HTTP-сервер перестаёт принимать запросы,
+// фоновые консьюмеры останавливаются, консьюмеры занятий дообрабатывают текущие сообщения,
+// закрывается соединение с RabbitMQ, накопленные снапшоты дописываются в БД,
+// WS-клиенты получают close frame,
+// и только после этого закрывается пул соединений с БД.
+func (a *App) shutdown() error {
+	shCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var errs []error
+
+	if err := a.server.Shutdown(shCtx); err != nil {
+		errs = append(errs, fmt.Errorf("http server: %w", err))
+	}
+
+	// фоновые консьюмеры останавливаем до менеджеров: общая topic-очередь должна перестать читать
+	// раньше, чем менеджеры снимут маршруты лекций, иначе её сообщения останутся без получателя,
+	// а планировщик не должен запускать занятия во время остановки
+	a.bgCancel()
+	bgDone := make(chan struct{})
+	go func() {
+		a.bg.Wait()
+		close(bgDone)
+	}()
+	select {
+	case <-bgDone:
+	case <-shCtx.Done():
+		errs = append(errs, fmt.Errorf("wait background consumers: %w", shCtx.Err()))
+	}
+
+	if err := a.managers.Shutdown(shCtx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := a.broker.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("rabbit broker: %w", err))
+	}
+
+	for _, writer := range a.writers {
+		if err := writer.Close(shCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := a.wsHub.Shutdown(shCtx); err != nil {
+		errs = append(errs, err)
+	}
+
+	a.db.Close()
+
+	return errors.Join(errs...)
+}
+
+func (a *App) goBackground(run func()) {
+	a.bg.Add(1)
+	go func() {
+		defer a.bg.Done()
+		run()
+	}()
+}
@@ -9,7 +9,16 @@ type Config struct {
 	Postgres PostgresConfig `toml:"postgres"`
 	Logger   LoggerConfig   `toml:"logger"`
 	Rabbit   RabbitConfig   `toml:"rabbit"`
+	Ingest   IngestConfig   `toml:"ingest"`
+	WS       WSConfig       `toml:"ws"`
 	JWT      JWTConfig      `toml:"jwt"`
+	// Attendance пороги статуса посещаемости по умолчанию; для предметов и департаментов
+	// их переопределяют политики в БД.
+	Attendance AttendanceConfig `toml:"attendance"`
+	Scheduler  SchedulerConfig  `toml:"scheduler"`
+	Cameras    CamerasConfig    `toml:"cameras"`
+	// Recognition пороги similarity по умолчанию; для департаментов их переопределяют пороги в БД.
+	Recognition RecognitionConfig `toml:"recognition"`
 }
 
 type JWTConfig struct {
@@ -19,8 +28,123 @@ type JWTConfig struct {
 
 type RabbitConfig struct {
 	AMPQURL string `toml:"ampq_url"`
+	// Mode способ чтения снапшотов:
+	// "queue" (по умолчанию) — своя очередь на каждую лекцию, имя передаётся при старте лекции;
+	// "topic" — одна очередь Queue на topic exchange Exchange, сообщения лекции приходят
+	// с routing key, указанным при старте (по умолчанию "lecture.<id>").
+	Mode     string `toml:"mode"`
+	Exchange string `toml:"exchange"` // "recognition"
+	Queue    string `toml:"queue"`    // "recognition.backend"
+	// ClassQueues очереди остальных видов занятий в режиме topic (routing key "<kind>.<id>"),
+	// ключ — вид занятия. Лекции читаются из Queue, для видов без записи — Queue + ".<kind>s".
+	ClassQueues map[string]string `toml:"class_queues"`
+	// DeadLetterExchange fanout exchange для сообщений, не прошедших валидацию или запись.
+	// Сообщения публикуются в него с routing key = исходная очередь.
+	DeadLetterExchange string `toml:"dead_letter_exchange"`
+	// DeadLetterQueue очередь, привязанная к DeadLetterExchange; её читает архиватор.
+	// Если оба значения пустые — такие сообщения nack-аются без requeue.
+	DeadLetterQueue string `toml:"dead_letter_queue"`
+	// MaxRetries сколько раз повторять сообщение при временной ошибке (БД недоступна и т.п.),
+	// прежде чем отправить его в dead-letter. 0 — по умолчанию 5.
+	MaxRetries int `toml:"max_retries"`
+	// Reconnect политика переподключения консьюмеров после обрыва соединения.
+	Reconnect ReconnectConfig `toml:"reconnect"`
 }
 
+type ReconnectConfig struct {
+	InitialBackoff time.Duration `toml:"initial_backoff"` // "1s"
+	MaxBackoff     time.Duration `toml:"max_backoff"`     // "20s"
+	Jitter         float64       `toml:"jitter"`          // 0.2 — пауза случайно меняется в пределах ±20%
+	// MaxAttempts неудачных попыток подряд, после которых консьюмер лекции сдаётся
+	// и сессия переходит в failed. 0 — переподключаться бесконечно.
+	// Общая topic-очередь и архиватор dead-letter переподключаются всегда.
+	MaxAttempts int `toml:"max_attempts"`
+}
+
+// ClassQueue общая очередь занятий вида kind в режиме topic. У каждого вида своя очередь,
+// иначе консьюмеры разных видов делили бы сообщения одной очереди.
+func (r RabbitConfig) ClassQueue(kind string) string {
+	if kind == "lecture" {
+		return r.Queue
+	}
+	if q := r.ClassQueues[kind]; q != "" {
+		return q
+	}
+	return r.Queue + "." + kind + "s"
+}
+
+const (
+	RabbitModeQueue = "queue"
+	RabbitModeTopic = "topic"
+)
+
+// IngestConfig параметры записи снапшотов распознавания в БД.
+type IngestConfig struct {
+	BatchSize     int           `toml:"batch_size"`     // 100
+	FlushInterval time.Duration `toml:"flush_interval"` // "500ms"
+	MaxClockSkew  time.Duration `toml:"max_clock_skew"` // "30s" — насколько время кадра может опережать сервер
+	MaxEventAge   time.Duration `toml:"max_event_age"`  // "24h" — кадры старше отбрасываются
+}
+
+// AttendanceConfig пороги, по которым присутствие на занятии превращается в статус.
+// Нулевые значения — значения по умолчанию (domain.DefaultAttendancePolicy).
+type AttendanceConfig struct {
+	LateAfter          time.Duration `toml:"late_after"`           // "10m" — опоздание меньше порога не считается
+	LeftEarlyAfter     time.Duration `toml:"left_early_after"`     // "10m" — ранний уход меньше порога не считается
+	MinCoveragePercent int           `toml:"min_coverage_percent"` // 50 — меньшая доля занятия считается отсутствием
+}
+
+// SchedulerConfig автоматический запуск мониторинга занятий по расписанию.
+// Очередь занятия берётся из его аудитории (universities_data.rooms).
+type SchedulerConfig struct {
+	Enabled     bool          `toml:"enabled"`
+	Interval    time.Duration `toml:"interval"`     // "30s" — как часто проверять расписание
+	StartBefore time.Duration `toml:"start_before"` // "5m" — за сколько до начала занятия запускать консьюмер
+	StopAfter   time.Duration `toml:"stop_after"`   // "5m" — сколько ждать после конца занятия перед остановкой
+}
+
+// RecognitionConfig пороги similarity (confidence) снапшота распознавания.
+// Нулевые значения принимают все снапшоты, как до появления порогов.
+type RecognitionConfig struct {
+	AcceptThreshold float64 `toml:"accept_threshold"` // 0.8 — не ниже снапшот засчитывается сразу
+	ReviewThreshold float64 `toml:"review_threshold"` // 0.6 — не ниже уходит на проверку преподавателю, ниже отбрасывается
+	// AcceptWithoutConfidence снапшоты без confidence засчитываются сразу; по умолчанию при заданных
+	// порогах они уходят на проверку преподавателю
+	AcceptWithoutConfidence bool `toml:"accept_without_confidence"`
+}
+
+// CamerasConfig контроль камер по heartbeat из очереди распознавания.
+type CamerasConfig struct {
+	HeartbeatTimeout time.Duration `toml:"heartbeat_timeout"` // "1m" — без heartbeat дольше камера считается отказавшей
+	CheckInterval    time.Duration `toml:"check_interval"`    // "15s" — как часто искать отказавшие камеры
+}
+
+// WSConfig параметры WebSocket-эндпоинта.
+type WSConfig struct {
+	// AllowedOrigins разрешённые Origin для handshake. Пусто — только тот же host, "*" — любой.
+	AllowedOrigins []string `toml:"allowed_origins"`
+
+	PingInterval time.Duration `toml:"ping_interval"` // "25s"
+	PongTimeout  time.Duration `toml:"pong_timeout"`  // "60s" — без pong/сообщений столько времени соединение считается мёртвым
+	WriteTimeout time.Duration `toml:"write_timeout"` // "10s"
+	SendBuffer   int           `toml:"send_buffer"`   // 256 сообщений в очереди на отправку клиенту
+	// SlowClientPolicy что делать, если очередь клиента переполнена:
+	// "disconnect" (по умолчанию) — закрыть соединение с кодом 4008, "drop" — выбросить сообщение.
+	SlowClientPolicy string `toml:"slow_client_policy"`
+	MaxMessageSize   int64  `toml:"max_message_size"` // 4096 байт на входящее сообщение
+
+	// Broadcaster как события доходят до клиентов:
+	// "memory" (по умолчанию) — только клиентам этой реплики;
+	// "postgres" — через LISTEN/NOTIFY, клиентам всех реплик.
+	Broadcaster   string `toml:"broadcaster"`
+	NotifyChannel string `toml:"notify_channel"` // "ws_events"
+}
+
+const (
+	WSBroadcasterMemory   = "memory"
+	WSBroadcasterPostgres = "postgres"
+)
+
 // AppConfig общие сведения о приложении (имя, окружение).
 type AppConfig struct {
 	Name        string `toml:"name"`
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultClassDuration длительность занятия, у которого не задан конец.
+const DefaultClassDuration = 90 * time.Minute
+
+// AttendanceStatus итог посещения студентом одного занятия.
+type AttendanceStatus string
+
+const (
+	AttendancePresent   AttendanceStatus = "present"
+	AttendanceLate      AttendanceStatus = "late"
+	AttendanceLeftEarly AttendanceStatus = "left_early"
+	AttendanceAbsent    AttendanceStatus = "absent"
+	// AttendanceExcused отсутствие по уважительной причине; ставится только вручную (см. AttendanceOverride).
+	AttendanceExcused AttendanceStatus = "excused"
+)
+
+// AttendancePolicy пороги, по которым присутствие превращается в статус.
+type AttendancePolicy struct {
+	// LateAfter опоздание до этого порога не считается.
+	LateAfter time.Duration
+	// LeftEarlyAfter ранний уход до этого порога не считается.
+	LeftEarlyAfter time.Duration
+	// MinCoveragePercent минимальная доля занятия (в процентах), при которой студент не absent.
+	MinCoveragePercent int
+}
+
+// DefaultAttendancePolicy действует, если порогов нет ни в БД, ни в конфиге.
+var DefaultAttendancePolicy = AttendancePolicy{
+	LateAfter:          10 * time.Minute,
+	LeftEarlyAfter:     10 * time.Minute,
+	MinCoveragePercent: 50,
+}
+
+// AttendancePolicyRule политика, заданная для предмета или департамента (ровно одно из двух).
+type AttendancePolicyRule struct {
+	ID           int64
+	SubjectID    *int64
+	DepartmentID *int64
+	Policy       AttendancePolicy
+	UpdatedAt    time.Time
+	UpdatedBy    *string
+}
+
+var ErrAttendancePolicyNotFound = errors.New("attendance policy not found")
+
+// Attendance посещение занятия, посчитанное по снапшотам и политике.
+type Attendance struct {
+	FirstSeen   *time.Time
+	LastSeen    *time.Time
+	LateBy      time.Duration
+	LeftEarlyBy time.Duration
+	// Coverage доля занятия, которую студент присутствовал, 0–100.
+	Coverage float64
+	Status   AttendanceStatus
+	// Computed статус по снапшотам, если его заменила ручная отметка Override; иначе пустой.
+	Computed AttendanceStatus
+	Override *AttendanceOverride
+}
+
+// WithOverride заменяет статус ручной отметкой o; присутствие по снапшотам не меняется.
+func (a Attendance) WithOverride(o *AttendanceOverride) Attendance {
+	if o == nil {
+		return a
+	}
+	a.Computed = a.Status
+	a.Status = o.Status
+	a.Override = o
+	return a
+}
+
+// Classify считает статус посещения занятия [start, end).
+// Студент absent, если его не видели или покрытие ниже MinCoveragePercent;
+// иначе late важнее left_early: опоздавший и рано ушедший студент — late.
+func (p AttendancePolicy) Classify(start, end time.Time, firstSeen, lastSeen *time.Time, presentSeconds int64) Attendance {
+	a := Attendance{
+		FirstSeen: firstSeen,
+		LastSeen:  lastSeen,
+		Status:    AttendanceAbsent,
+	}
+	if firstSeen == nil || lastSeen == nil {
+		return a
+	}
+
+	if duration := end.Sub(start); duration > 0 {
+		a.Coverage = min(100, float64(presentSeconds)/duration.Seconds()*100)
+	}
+	if firstSeen.After(start) {
+		a.LateBy = firstSeen.Sub(start)
+	}
+	if lastSeen.Before(end) {
+		a.LeftEarlyBy = end.Sub(*lastSeen)
+	}
+
+	switch {
+	case a.Coverage < float64(p.MinCoveragePercent):
+		a.Status = AttendanceAbsent
+	case a.LateBy > p.LateAfter:
+		a.Status = AttendanceLate
+	case a.LeftEarlyBy > p.LeftEarlyAfter:
+		a.Status = AttendanceLeftEarly
+	default:
+		a.Status = AttendancePresent
+	}
+	return a
+}
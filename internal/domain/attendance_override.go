@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrAttendanceOverrideNotFound = errors.New("attendance override not found")
+var ErrStudentNotFound = errors.New("student not found")
+
+// Overridable статусы, которые преподаватель может поставить вручную.
+func (s AttendanceStatus) Overridable() bool {
+	return s == AttendancePresent || s == AttendanceAbsent || s == AttendanceExcused
+}
+
+// AttendanceOverride ручная отметка преподавателя о посещении студентом занятия
+// (visits.attendance_overrides). Во всех выдачах посещаемости важнее статуса по снапшотам.
+type AttendanceOverride struct {
+	Kind      ClassKind
+	ClassID   int64
+	UserID    string
+	Status    AttendanceStatus
+	Reason    string
+	UpdatedAt time.Time
+	UpdatedBy *string
+	// User заполняется только в списке отметок занятия.
+	User User
+}
+
+type AttendanceOverrideAction string
+
+const (
+	AttendanceOverrideSet   AttendanceOverrideAction = "set"
+	AttendanceOverrideClear AttendanceOverrideAction = "clear"
+)
+
+// AttendanceAuditEntry запись журнала ручных отметок. Status nil у снятой отметки,
+// PreviousStatus nil, если до этого отметки не было.
+type AttendanceAuditEntry struct {
+	ID             int64
+	Kind           ClassKind
+	ClassID        int64
+	UserID         string
+	Action         AttendanceOverrideAction
+	Status         *AttendanceStatus
+	PreviousStatus *AttendanceStatus
+	Reason         *string
+	Actor          *string
+	CreatedAt      time.Time
+}
+
+// AttendanceAuditFilter UserID nil — журнал по всем студентам занятия.
+type AttendanceAuditFilter struct {
+	Kind     ClassKind
+	ClassID  int64
+	UserID   *string
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAttendancePolicyClassify(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	at := func(d time.Duration) *time.Time {
+		v := start.Add(d)
+		return &v
+	}
+	policy := AttendancePolicy{LateAfter: 10 * time.Minute, LeftEarlyAfter: 10 * time.Minute, MinCoveragePercent: 50}
+
+	tests := []struct {
+		name            string
+		firstSeen       *time.Time
+		lastSeen        *time.Time
+		presentSeconds  int64
+		wantStatus      AttendanceStatus
+		wantLateBy      time.Duration
+		wantLeftEarlyBy time.Duration
+		wantCoverage    float64
+	}{
+		{
+			name:       "never seen",
+			wantStatus: AttendanceAbsent,
+		},
+		{
+			name:           "whole class",
+			firstSeen:      at(0),
+			lastSeen:       at(90 * time.Minute),
+			presentSeconds: 90 * 60,
+			wantStatus:     AttendancePresent,
+			wantCoverage:   100,
+		},
+		{
+			name:           "late within threshold",
+			firstSeen:      at(10 * time.Minute),
+			lastSeen:       at(90 * time.Minute),
+			presentSeconds: 80 * 60,
+			wantStatus:     AttendancePresent,
+			wantLateBy:     10 * time.Minute,
+			wantCoverage:   80 * 100 / 90.0,
+		},
+		{
+			name:           "late",
+			firstSeen:      at(11 * time.Minute),
+			lastSeen:       at(90 * time.Minute),
+			presentSeconds: 79 * 60,
+			wantStatus:     AttendanceLate,
+			wantLateBy:     11 * time.Minute,
+			wantCoverage:   79 * 100 / 90.0,
+		},
+		{
+			name:            "left early",
+			firstSeen:       at(0),
+			lastSeen:        at(70 * time.Minute),
+			presentSeconds:  70 * 60,
+			wantStatus:      AttendanceLeftEarly,
+			wantLeftEarlyBy: 20 * time.Minute,
+			wantCoverage:    70 * 100 / 90.0,
+		},
+		{
+			name:            "late wins over left early",
+			firstSeen:       at(15 * time.Minute),
+			lastSeen:        at(75 * time.Minute),
+			presentSeconds:  60 * 60,
+			wantStatus:      AttendanceLate,
+			wantLateBy:      15 * time.Minute,
+			wantLeftEarlyBy: 15 * time.Minute,
+			wantCoverage:    60 * 100 / 90.0,
+		},
+		{
+			name:           "coverage below minimum",
+			firstSeen:      at(0),
+			lastSeen:       at(90 * time.Minute),
+			presentSeconds: 30 * 60,
+			wantStatus:     AttendanceAbsent,
+			wantCoverage:   30 * 100 / 90.0,
+		},
+		{
+			name:           "coverage is capped",
+			firstSeen:      at(-5 * time.Minute),
+			lastSeen:       at(95 * time.Minute),
+			presentSeconds: 100 * 60,
+			wantStatus:     AttendancePresent,
+			wantCoverage:   100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Classify(start, end, tt.firstSeen, tt.lastSeen, tt.presentSeconds)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if got.LateBy != tt.wantLateBy {
+				t.Errorf("late by = %s, want %s", got.LateBy, tt.wantLateBy)
+			}
+			if got.LeftEarlyBy != tt.wantLeftEarlyBy {
+				t.Errorf("left early by = %s, want %s", got.LeftEarlyBy, tt.wantLeftEarlyBy)
+			}
+			if math.Abs(got.Coverage-tt.wantCoverage) > 1e-9 {
+				t.Errorf("coverage = %v, want %v", got.Coverage, tt.wantCoverage)
+			}
+			if got.FirstSeen != tt.firstSeen || got.LastSeen != tt.lastSeen {
+				t.Errorf("seen = %v..%v, want %v..%v", got.FirstSeen, got.LastSeen, tt.firstSeen, tt.lastSeen)
+			}
+		})
+	}
+}
+
+func TestAttendanceWithOverride(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Minute)
+	firstSeen := start.Add(20 * time.Minute)
+	late := DefaultAttendancePolicy.Classify(start, end, &firstSeen, &end, 70*60)
+	if late.Status != AttendanceLate {
+		t.Fatalf("computed status = %s, want %s", late.Status, AttendanceLate)
+	}
+
+	tests := []struct {
+		name         string
+		computed     Attendance
+		override     *AttendanceOverride
+		wantStatus   AttendanceStatus
+		wantComputed AttendanceStatus
+	}{
+		{
+			name:       "no override",
+			computed:   late,
+			wantStatus: late.Status,
+		},
+		{
+			name:         "teacher marks present",
+			computed:     late,
+			override:     &AttendanceOverride{Status: AttendancePresent, Reason: "camera missed"},
+			wantStatus:   AttendancePresent,
+			wantComputed: late.Status,
+		},
+		{
+			name:         "excused instead of absent",
+			computed:     Attendance{Status: AttendanceAbsent},
+			override:     &AttendanceOverride{Status: AttendanceExcused, Reason: "sick leave"},
+			wantStatus:   AttendanceExcused,
+			wantComputed: AttendanceAbsent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.computed.WithOverride(tt.override)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if got.Computed != tt.wantComputed {
+				t.Errorf("computed = %q, want %q", got.Computed, tt.wantComputed)
+			}
+			if got.Override != tt.override {
+				t.Errorf("override = %v, want %v", got.Override, tt.override)
+			}
+			// присутствие по снапшотам отметка не меняет
+			if got.FirstSeen != tt.computed.FirstSeen || got.LateBy != tt.computed.LateBy || got.Coverage != tt.computed.Coverage {
+				t.Errorf("presence changed: %+v, want %+v", got, tt.computed)
+			}
+		})
+	}
+}
+
+func TestAttendanceStatusOverridable(t *testing.T) {
+	tests := []struct {
+		status AttendanceStatus
+		want   bool
+	}{
+		{AttendancePresent, true},
+		{AttendanceAbsent, true},
+		{AttendanceExcused, true},
+		{AttendanceLate, false},
+		{AttendanceLeftEarly, false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.Overridable(); got != tt.want {
+			t.Errorf("%q.Overridable() = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
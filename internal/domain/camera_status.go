@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// DefaultCameraHeartbeatTimeout без heartbeat дольше камера считается отказавшей, если таймаут не задан.
+const DefaultCameraHeartbeatTimeout = time.Minute
+
+// CameraHealth состояние камеры: ok, degraded и down камера присылает в camera_status сама,
+// down также ставится, если heartbeat не приходил дольше таймаута.
+type CameraHealth string
+
+const (
+	CameraOK       CameraHealth = "ok"
+	CameraDegraded CameraHealth = "degraded"
+	CameraDown     CameraHealth = "down"
+	// CameraUnknown камера есть в справочнике, но ещё ни разу не присылала heartbeat
+	CameraUnknown CameraHealth = "unknown"
+)
+
+// CameraHeartbeat сигнал жизни камеры. Errors — ошибки распознавания с прошлого heartbeat.
+type CameraHeartbeat struct {
+	CameraID string
+	SeenAt   time.Time
+	FPS      *float64
+	Errors   int64
+}
+
+// CameraReport статус, который камера прислала сама; degraded и down считаются ошибкой.
+type CameraReport struct {
+	CameraID string
+	Status   CameraHealth
+	Message  string
+	SeenAt   time.Time
+}
+
+// CameraStatus последнее известное состояние камеры. RoomCode пустой у камер не из справочника,
+// LastSeenAt nil — у камер из справочника, от которых не было ни одного сообщения.
+type CameraStatus struct {
+	CameraID    string
+	RoomCode    *string
+	Registered  bool
+	LastSeenAt  *time.Time
+	FPS         *float64
+	Reported    CameraHealth
+	ErrorCount  int64
+	LastError   *string
+	LastErrorAt *time.Time
+}
+
+// Health состояние камеры на момент now с учётом таймаута heartbeat.
+func (s CameraStatus) Health(now time.Time, timeout time.Duration) CameraHealth {
+	switch {
+	case s.LastSeenAt == nil:
+		return CameraUnknown
+	case now.Sub(*s.LastSeenAt) > timeout:
+		return CameraDown
+	case s.Reported == "":
+		return CameraOK
+	default:
+		return s.Reported
+	}
+}
+
+// CameraOutage камера, про отказ которой ещё не сообщали, и запущенные лекции в её аудитории.
+type CameraOutage struct {
+	Status     CameraStatus
+	LectureIDs []int64
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrClassNotFound = errors.New("class not found")
+var ErrClassAccessDenied = errors.New("class access denied")
+
+// Class занятие любого вида. id уникален только в пределах вида: лекции и практики
+// перенесены в общую таблицу с прежними id.
+type Class struct {
+	ID        int64
+	Kind      ClassKind
+	Date      time.Time
+	SubjectID int64
+	TeacherID string
+	// EndsAt конец занятия; nil — занятие длится DefaultClassDuration.
+	EndsAt *time.Time
+	// Room аудитория; пустая, если не назначена.
+	Room   string
+	Online bool
+}
+
+// End фактический конец занятия.
+func (c Class) End() time.Time {
+	if c.EndsAt != nil {
+		return *c.EndsAt
+	}
+	return c.Date.Add(DefaultClassDuration)
+}
+
+// Duration длительность занятия.
+func (c Class) Duration() time.Duration {
+	return c.End().Sub(c.Date)
+}
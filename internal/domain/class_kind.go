@@ -0,0 +1,26 @@
+package domain
+
+// ClassKind вид занятия, на котором отслеживается посещаемость.
+// Все виды хранятся в universities_data.classes и обрабатываются одним и тем же конвейером;
+// занятие идентифицируется парой (kind, id).
+type ClassKind string
+
+const (
+	ClassLecture      ClassKind = "lecture"
+	ClassPractice     ClassKind = "practice"
+	ClassLab          ClassKind = "lab"
+	ClassExam         ClassKind = "exam"
+	ClassConsultation ClassKind = "consultation"
+)
+
+// ClassKinds все поддерживаемые виды занятий.
+var ClassKinds = []ClassKind{ClassLecture, ClassPractice, ClassLab, ClassExam, ClassConsultation}
+
+func (k ClassKind) Valid() bool {
+	for _, kind := range ClassKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrClassSessionNotFound = errors.New("class session not found")
+var ErrClassSessionAlreadyRunning = errors.New("class session already running")
+var ErrClassSessionShuttingDown = errors.New("lecture manager is shutting down")
+var ErrClassSessionRouteRequired = errors.New("route is required")
+var ErrClassSessionBusy = errors.New("class session is being started or stopped")
+
+type ClassSessionState string
+
+const (
+	// ClassSessionRunning консьюмер занятия запущен (или должен быть поднят после рестарта).
+	ClassSessionRunning ClassSessionState = "running"
+	// ClassSessionStopped обработка остановлена вручную через API.
+	ClassSessionStopped ClassSessionState = "stopped"
+	// ClassSessionFinished консьюмер завершился сам (пришло сообщение о конце занятия)
+	// или его остановил планировщик после конца занятия.
+	ClassSessionFinished ClassSessionState = "finished"
+	// ClassSessionFailed консьюмер не смог переподключиться к RabbitMQ за отведённые попытки.
+	ClassSessionFailed ClassSessionState = "failed"
+)
+
+// ConsumerState состояние подключения консьюмера лекции к RabbitMQ.
+type ConsumerState string
+
+const (
+	ConsumerConnecting   ConsumerState = "connecting"
+	ConsumerConnected    ConsumerState = "connected"
+	ConsumerReconnecting ConsumerState = "reconnecting"
+	ConsumerFailed       ConsumerState = "failed"
+)
+
+type ConsumerHealth struct {
+	State ConsumerState
+	// Attempt номер неудачной попытки подряд, 0 после успешного подключения
+	Attempt   int
+	LastError string
+	Since     time.Time
+}
+
+// ClassSession сессия мониторинга занятия (visits.class_sessions): LectureID — id занятия вида Kind.
+type ClassSession struct {
+	ID        int64
+	Kind      ClassKind
+	LectureID int64
+	Queue     string
+	State     ClassSessionState
+	StartedAt time.Time
+	StoppedAt *time.Time
+	StartedBy *string
+	StoppedBy *string
+}
+
+type ClassSessionFilter struct {
+	LectureID *int64
+	State     *ClassSessionState
+	Page      int
+	PageSize  int
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// ClassVisit снапшот распознавания на занятии (visits.classes_visiting).
+// LectureID — id занятия; вид занятия задаёт репозиторий, которым снапшот записан.
+type ClassVisit struct {
+	ID        int64
+	LectureID int64
+	UserID    string
+	// CapturedAt время кадра на камере — по нему считается присутствие.
+	CapturedAt time.Time
+	// ReceivedAt время, когда бэкенд получил сообщение из очереди.
+	ReceivedAt time.Time
+	CameraID   *string
+	Confidence *float64
+}
+
+// ClassVisitDetails снапшот вместе с данными студента.
+type ClassVisitDetails struct {
+	Visit ClassVisit
+	User  User
+}
+
+// StudentPresence агрегированное присутствие студента на лекции.
+type StudentPresence struct {
+	User           User
+	PresentSeconds int64
+	Snapshots      int
+	FirstSeen      *time.Time
+	LastSeen       *time.Time
+	// Override ручная отметка преподавателя, если есть.
+	Override *AttendanceOverride
+}
+
+// ClassVisitFilter выборка сырых снапшотов занятия; границы по captured_at включительно.
+type ClassVisitFilter struct {
+	From     *time.Time
+	To       *time.Time
+	UserID   *string
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+type DeadLetterStatus string
+
+const (
+	DeadLetterPending   DeadLetterStatus = "pending"
+	DeadLetterReplayed  DeadLetterStatus = "replayed"
+	DeadLetterDiscarded DeadLetterStatus = "discarded"
+)
+
+// DeadLetter сообщение очереди распознавания, которое не удалось обработать.
+type DeadLetter struct {
+	ID          int64
+	SourceQueue string
+	Kind        ClassKind
+	// LectureID id занятия вида Kind
+	LectureID      *int64
+	Reason         string
+	Error          string
+	Headers        map[string]any
+	ContentType    *string
+	Body           []byte
+	FailedAt       time.Time
+	ArchivedAt     time.Time
+	Status         DeadLetterStatus
+	ReplayAttempts int
+	ResolvedAt     *time.Time
+	ResolvedBy     *string
+}
+
+type DeadLetterFilter struct {
+	Status    *DeadLetterStatus
+	Reason    *string
+	Kind      *ClassKind
+	LectureID *int64
+	Page      int
+	PageSize  int
+}
@@ -1,10 +1,4 @@
 package domain
 
-import "time"
-
-type Lecture struct {
-	ID        int64
-	Date      time.Time
-	SubjectID int64
-	TeacherID string
-}
+// Lecture занятие вида ClassLecture.
+type Lecture = Class
@@ -1,10 +1,9 @@
 package domain
 
-import "time"
+import "errors"
 
-type Practice struct {
-	ID        int64
-	Date      time.Time
-	SubjectID int64
-	TeacherID string
-}
+// Practice занятие вида ClassPractice.
+type Practice = Class
+
+var ErrPracticeNotFound = errors.New("practice not found")
+var ErrPracticeAccessDenied = errors.New("practice access denied")
@@ -1,10 +0,0 @@
-package domain
-
-import "time"
-
-type PracticeVisit struct {
-	ID         int64
-	PracticeID int64
-	UserID     string
-	Date       time.Time
-}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRecognitionThresholdNotFound = errors.New("recognition threshold not found")
+
+// RecognitionVerdict что делать со снапшотом по его similarity (confidence).
+type RecognitionVerdict string
+
+const (
+	RecognitionAccepted  RecognitionVerdict = "accepted"
+	RecognitionReview    RecognitionVerdict = "review"
+	RecognitionDiscarded RecognitionVerdict = "discarded"
+)
+
+// RecognitionThresholds пороги similarity: не ниже Accept — посещение, не ниже Review — на проверку,
+// ниже — снапшот отбрасывается. Нулевые пороги принимают всё.
+type RecognitionThresholds struct {
+	Accept float64
+	Review float64
+	// AcceptMissing снапшоты без similarity принимаются и при заданных порогах
+	// (камеры, которые её ещё не присылают); иначе они уходят на проверку.
+	AcceptMissing bool
+}
+
+// Valid 0 <= Review <= Accept <= 1.
+func (t RecognitionThresholds) Valid() bool {
+	return t.Review >= 0 && t.Review <= t.Accept && t.Accept <= 1
+}
+
+// Classify снапшот без similarity принимается, только если пороги не заданы или AcceptMissing.
+func (t RecognitionThresholds) Classify(confidence *float64) RecognitionVerdict {
+	switch {
+	case confidence == nil:
+		if t.Accept == 0 || t.AcceptMissing {
+			return RecognitionAccepted
+		}
+		return RecognitionReview
+	case *confidence >= t.Accept:
+		return RecognitionAccepted
+	case *confidence >= t.Review:
+		return RecognitionReview
+	default:
+		return RecognitionDiscarded
+	}
+}
+
+// RecognitionThresholdRule пороги, заданные для департамента.
+type RecognitionThresholdRule struct {
+	DepartmentID int64
+	Thresholds   RecognitionThresholds
+	UpdatedAt    time.Time
+	UpdatedBy    *string
+}
+
+// SightingStatus состояние снапшота на проверке.
+type SightingStatus string
+
+const (
+	SightingPending   SightingStatus = "pending"
+	SightingConfirmed SightingStatus = "confirmed"
+	SightingRejected  SightingStatus = "rejected"
+)
+
+func (s SightingStatus) Valid() bool {
+	switch s {
+	case SightingPending, SightingConfirmed, SightingRejected:
+		return true
+	}
+	return false
+}
+
+// PendingSighting снапшот с similarity между порогами (visits.pending_sightings).
+// Visit.ID — id записи на проверке, а не снапшота в visits.classes_visiting.
+type PendingSighting struct {
+	Kind       ClassKind
+	Visit      ClassVisit
+	User       User
+	Status     SightingStatus
+	ReviewedBy *string
+	ReviewedAt *time.Time
+}
+
+// PendingSightingFilter Status nil — снапшоты во всех состояниях.
+type PendingSightingFilter struct {
+	Kind     ClassKind
+	ClassID  int64
+	Status   *SightingStatus
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "testing"
+
+func TestRecognitionThresholdsClassify(t *testing.T) {
+	similarity := func(v float64) *float64 { return &v }
+	thresholds := RecognitionThresholds{Accept: 0.8, Review: 0.5}
+
+	tests := []struct {
+		name       string
+		thresholds RecognitionThresholds
+		confidence *float64
+		want       RecognitionVerdict
+	}{
+		{name: "no thresholds accept everything", confidence: similarity(0.1), want: RecognitionAccepted},
+		{name: "no thresholds accept scoreless", want: RecognitionAccepted},
+		{name: "above accept", thresholds: thresholds, confidence: similarity(0.95), want: RecognitionAccepted},
+		{name: "exactly accept", thresholds: thresholds, confidence: similarity(0.8), want: RecognitionAccepted},
+		{name: "between review and accept", thresholds: thresholds, confidence: similarity(0.6), want: RecognitionReview},
+		{name: "exactly review", thresholds: thresholds, confidence: similarity(0.5), want: RecognitionReview},
+		{name: "below review", thresholds: thresholds, confidence: similarity(0.49), want: RecognitionDiscarded},
+		{name: "scoreless goes to review", thresholds: thresholds, want: RecognitionReview},
+		{
+			name:       "scoreless accepted when allowed",
+			thresholds: RecognitionThresholds{Accept: 0.8, Review: 0.5, AcceptMissing: true},
+			want:       RecognitionAccepted,
+		},
+		{
+			name:       "accept missing does not affect scored",
+			thresholds: RecognitionThresholds{Accept: 0.8, Review: 0.5, AcceptMissing: true},
+			confidence: similarity(0.3),
+			want:       RecognitionDiscarded,
+		},
+		{
+			name:       "review disabled",
+			thresholds: RecognitionThresholds{Accept: 0.8, Review: 0.8},
+			confidence: similarity(0.7),
+			want:       RecognitionDiscarded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.thresholds.Classify(tt.confidence); got != tt.want {
+				t.Errorf("Classify = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecognitionThresholdsValid(t *testing.T) {
+	tests := []struct {
+		thresholds RecognitionThresholds
+		want       bool
+	}{
+		{RecognitionThresholds{}, true},
+		{RecognitionThresholds{Accept: 0.8, Review: 0.5}, true},
+		{RecognitionThresholds{Accept: 1, Review: 1}, true},
+		{RecognitionThresholds{Accept: 0.5, Review: 0.8}, false},
+		{RecognitionThresholds{Accept: 1.2, Review: 0.5}, false},
+		{RecognitionThresholds{Accept: 0.8, Review: -0.1}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.thresholds.Valid(); got != tt.want {
+			t.Errorf("%+v.Valid() = %v, want %v", tt.thresholds, got, tt.want)
+		}
+	}
+}
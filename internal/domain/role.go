@@ -0,0 +1,8 @@
+package domain
+
+// Роли пользователя из JWT (claims.Role).
+const (
+	RoleAdmin   = "admin"
+	RoleTeacher = "teacher"
+	RoleStudent = "student"
+)
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRoomNotFound = errors.New("room not found")
+var ErrRoomInUse = errors.New("room is used by classes")
+var ErrCameraNotFound = errors.New("camera not found")
+
+// Room аудитория. Queue — очередь камер аудитории или routing key в режиме topic;
+// пустая — мониторинг занятий в аудитории запускается с route по умолчанию.
+type Room struct {
+	Code     string
+	Building *string
+	Capacity *int
+	Queue    string
+	Cameras  []Camera
+}
+
+// Camera камера аудитории; ID совпадает с camera_id в сообщениях распознавания.
+type Camera struct {
+	ID          string
+	RoomCode    string
+	Description *string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// ScheduleMode кто управляет мониторингом занятия.
+type ScheduleMode string
+
+const (
+	// ScheduleAuto планировщик запускает консьюмер перед началом занятия и останавливает после конца.
+	ScheduleAuto ScheduleMode = "auto"
+	// ScheduleManual планировщик занятие не трогает: старт и стоп только через API.
+	ScheduleManual ScheduleMode = "manual"
+)
+
+func (m ScheduleMode) Valid() bool {
+	return m == ScheduleAuto || m == ScheduleManual
+}
+
+type ScheduleAction string
+
+const (
+	ScheduleActionSetMode     ScheduleAction = "set_mode"
+	ScheduleActionAutoStart   ScheduleAction = "auto_start"
+	ScheduleActionAutoStop    ScheduleAction = "auto_stop"
+	ScheduleActionManualStart ScheduleAction = "manual_start"
+	ScheduleActionManualStop  ScheduleAction = "manual_stop"
+)
+
+// ScheduleOverride ручной режим планировщика для занятия (visits.class_schedule_overrides).
+type ScheduleOverride struct {
+	Kind      ClassKind
+	ClassID   int64
+	Mode      ScheduleMode
+	Reason    *string
+	UpdatedAt time.Time
+	UpdatedBy *string
+}
+
+// ScheduleAuditEntry запись журнала расписания. Actor nil — действие планировщика.
+type ScheduleAuditEntry struct {
+	ID        int64
+	Kind      ClassKind
+	ClassID   int64
+	Action    ScheduleAction
+	Mode      *ScheduleMode
+	Reason    *string
+	Actor     *string
+	CreatedAt time.Time
+}
+
+type ScheduleAuditFilter struct {
+	Kind     ClassKind
+	ClassID  int64
+	Page     int
+	PageSize int
+}
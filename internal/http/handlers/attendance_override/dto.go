@@ -0,0 +1,62 @@
+package attendance_override
+
+import "time"
+
+// SetOverrideRequest status: present, absent или excused; причина обязательна.
+type SetOverrideRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// OverrideResponse отметка студента на занятии.
+type OverrideResponse struct {
+	Kind      string    `json:"kind"`
+	ClassID   int64     `json:"class_id"`
+	ISU       string    `json:"isu"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy *string   `json:"updated_by,omitempty"`
+}
+
+type OverrideItem struct {
+	ISU        string    `json:"isu"`
+	FirstName  string    `json:"first_name"`
+	LastName   string    `json:"last_name"`
+	Patronymic *string   `json:"patronymic,omitempty"`
+	Group      *string   `json:"group,omitempty"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	UpdatedBy  *string   `json:"updated_by,omitempty"`
+}
+
+type ListOverridesResponse struct {
+	Kind    string         `json:"kind"`
+	ClassID int64          `json:"class_id"`
+	Items   []OverrideItem `json:"items"`
+}
+
+// AuditItem action: set — отметка поставлена или изменена, clear — снята (status пустой).
+// previous_status пустой, если до этого отметки не было.
+type AuditItem struct {
+	ID             int64     `json:"id"`
+	ISU            string    `json:"isu"`
+	Action         string    `json:"action"`
+	Status         *string   `json:"status,omitempty"`
+	PreviousStatus *string   `json:"previous_status,omitempty"`
+	Reason         *string   `json:"reason,omitempty"`
+	Actor          *string   `json:"actor,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type PageMeta struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Total    int `json:"total"`
+}
+
+type ListAuditResponse struct {
+	Items []AuditItem `json:"items"`
+	Meta  PageMeta    `json:"meta"`
+}
@@ -0,0 +1,263 @@
+package attendance_override
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitoring_backend/internal/domain"
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+type AttendanceOverrideService interface {
+	List(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) (ListOverridesResponse, error)
+	Set(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, isu string, req SetOverrideRequest) (OverrideResponse, error)
+	Clear(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, isu string, reason *string) (OverrideResponse, error)
+	ListAudit(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, isu *string, page, pageSize int) (ListAuditResponse, error)
+}
+
+type AttendanceOverrideHandler struct {
+	service AttendanceOverrideService
+}
+
+func NewAttendanceOverrideHandler(service AttendanceOverrideService) *AttendanceOverrideHandler {
+	return &AttendanceOverrideHandler{service: service}
+}
+
+// List godoc
+// @Summary      List attendance overrides
+// @Description  Ручные отметки посещаемости занятия. Во всех выдачах посещаемости они важнее статуса по снапшотам.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind  path  string  true  "Вид занятия"
+// @Param        id    path  int     true  "Class ID"
+// @Success      200  {object}  attendance_override.ListOverridesResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/attendance [get]
+func (h *AttendanceOverrideHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, role, kind, classID, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.List(r.Context(), userID, role, kind, classID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Set godoc
+// @Summary      Override student attendance
+// @Description  Отмечает студента на занятии как present, absent или excused, например если камера его не распознала.
+// @Description  Отметка заменяет статус по снапшотам; повторный запрос меняет её. Каждая смена пишется в журнал.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind     path  string                                  true  "Вид занятия"
+// @Param        id       path  int                                     true  "Class ID"
+// @Param        isu      path  string                                  true  "ISU студента"
+// @Param        request  body  attendance_override.SetOverrideRequest  true  "Статус и причина"
+// @Success      200  {object}  attendance_override.OverrideResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/attendance/{isu} [put]
+func (h *AttendanceOverrideHandler) Set(w http.ResponseWriter, r *http.Request) {
+	userID, role, kind, classID, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+	isu, ok := parseISU(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if !domain.AttendanceStatus(req.Status).Overridable() {
+		response.WriteError(w, http.StatusBadRequest, "status must be present, absent or excused")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		response.WriteError(w, http.StatusBadRequest, "reason is required")
+		return
+	}
+
+	resp, err := h.service.Set(r.Context(), userID, role, kind, classID, isu, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Clear godoc
+// @Summary      Clear attendance override
+// @Description  Снимает ручную отметку: статус студента снова считается по снапшотам. Снятие пишется в журнал.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind    path   string  true   "Вид занятия"
+// @Param        id      path   int     true   "Class ID"
+// @Param        isu     path   string  true   "ISU студента"
+// @Param        reason  query  string  false  "Причина"
+// @Success      200  {object}  attendance_override.OverrideResponse  "Снятая отметка"
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/attendance/{isu} [delete]
+func (h *AttendanceOverrideHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	userID, role, kind, classID, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+	isu, ok := parseISU(w, r)
+	if !ok {
+		return
+	}
+
+	var reason *string
+	if s := strings.TrimSpace(r.URL.Query().Get("reason")); s != "" {
+		reason = &s
+	}
+
+	resp, err := h.service.Clear(r.Context(), userID, role, kind, classID, isu, reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ListAudit godoc
+// @Summary      Attendance override audit
+// @Description  Журнал ручных отметок занятия (новые сверху): кто, когда и почему ставил, менял или снимал отметку.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind       path   string  true   "Вид занятия"
+// @Param        id         path   int     true   "Class ID"
+// @Param        isu        query  string  false  "Только по этому студенту"
+// @Param        page       query  int     false  "Страница (по умолчанию 1)"
+// @Param        page_size  query  int     false  "Размер страницы (по умолчанию 50)"
+// @Success      200  {object}  attendance_override.ListAuditResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/attendance/audit [get]
+func (h *AttendanceOverrideHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	userID, role, kind, classID, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var isu *string
+	if s := strings.TrimSpace(r.URL.Query().Get("isu")); s != "" {
+		isu = &s
+	}
+
+	page, err := httputil.QueryInt(r, "page", 1)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if page < 1 {
+		page = 1
+	}
+	pageSize, err := httputil.QueryInt(r, "page_size", 50)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+
+	resp, err := h.service.ListAudit(r.Context(), userID, role, kind, classID, isu, page, pageSize)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+func parseRequest(w http.ResponseWriter, r *http.Request) (string, string, domain.ClassKind, int64, bool) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok || userID == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return "", "", "", 0, false
+	}
+	role, _ := middleware.Role(r.Context())
+
+	vars := mux.Vars(r)
+	kind := domain.ClassKind(strings.ToLower(strings.TrimSpace(vars["kind"])))
+	if !kind.Valid() {
+		response.WriteError(w, http.StatusNotFound, "unknown class kind")
+		return "", "", "", 0, false
+	}
+
+	classID, err := httputil.PathInt64(r, "id", vars)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return "", "", "", 0, false
+	}
+
+	return userID, role, kind, classID, true
+}
+
+func parseISU(w http.ResponseWriter, r *http.Request) (string, bool) {
+	isu := strings.TrimSpace(mux.Vars(r)["isu"])
+	if isu == "" {
+		response.WriteError(w, http.StatusBadRequest, "isu is required")
+		return "", false
+	}
+	return isu, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrClassAccessDenied) {
+		response.WriteError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	httputil.WriteServiceError(w, err)
+}
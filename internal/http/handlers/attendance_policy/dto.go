@@ -0,0 +1,28 @@
+package attendance_policy
+
+import "time"
+
+// PolicyRequest пороги статуса посещаемости.
+type PolicyRequest struct {
+	LateAfterSeconds      int `json:"late_after_seconds"`
+	LeftEarlyAfterSeconds int `json:"left_early_after_seconds"`
+	MinCoveragePercent    int `json:"min_coverage_percent"`
+}
+
+// PolicyResponse заполнен либо subject_id, либо department_id.
+type PolicyResponse struct {
+	ID                    int64     `json:"id"`
+	SubjectID             *int64    `json:"subject_id,omitempty"`
+	DepartmentID          *int64    `json:"department_id,omitempty"`
+	LateAfterSeconds      int       `json:"late_after_seconds"`
+	LeftEarlyAfterSeconds int       `json:"left_early_after_seconds"`
+	MinCoveragePercent    int       `json:"min_coverage_percent"`
+	UpdatedAt             time.Time `json:"updated_at"`
+	UpdatedBy             *string   `json:"updated_by,omitempty"`
+}
+
+// ListPoliciesResponse default — пороги из конфига для предметов и департаментов без своей политики.
+type ListPoliciesResponse struct {
+	Default PolicyRequest    `json:"default"`
+	Items   []PolicyResponse `json:"items"`
+}
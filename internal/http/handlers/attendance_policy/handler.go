@@ -0,0 +1,193 @@
+package attendance_policy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+type AttendancePolicyService interface {
+	List(ctx context.Context) (ListPoliciesResponse, error)
+	SetForSubject(ctx context.Context, subjectID int64, req PolicyRequest, by *string) (PolicyResponse, error)
+	SetForDepartment(ctx context.Context, departmentID int64, req PolicyRequest, by *string) (PolicyResponse, error)
+	DeleteForSubject(ctx context.Context, subjectID int64) error
+	DeleteForDepartment(ctx context.Context, departmentID int64) error
+}
+
+type AttendancePolicyHandler struct {
+	service AttendancePolicyService
+}
+
+func NewAttendancePolicyHandler(service AttendancePolicyService) *AttendancePolicyHandler {
+	return &AttendancePolicyHandler{service: service}
+}
+
+// List godoc
+// @Summary      List attendance policies
+// @Description  Пороги статуса посещаемости (late, left_early, absent): значения по умолчанию и политики предметов и департаментов.
+// @Description  Для студента действует политика предмета, без неё — департамента его группы, без обеих — default.
+// @Tags         attendance-policies
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Success      200  {object}  attendance_policy.ListPoliciesResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/attendance-policies [get]
+func (h *AttendancePolicyHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	resp, err := h.service.List(r.Context())
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// SetForSubject godoc
+// @Summary      Set subject attendance policy
+// @Description  Создаёт или заменяет пороги статуса посещаемости для предмета.
+// @Tags         attendance-policies
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        id       path  int                              true  "Subject ID"
+// @Param        request  body  attendance_policy.PolicyRequest  true  "Пороги"
+// @Success      200  {object}  attendance_policy.PolicyResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/attendance-policies/subjects/{id} [put]
+func (h *AttendancePolicyHandler) SetForSubject(w http.ResponseWriter, r *http.Request) {
+	h.set(w, r, h.service.SetForSubject)
+}
+
+// SetForDepartment godoc
+// @Summary      Set department attendance policy
+// @Description  Создаёт или заменяет пороги статуса посещаемости для департамента (по группе студента).
+// @Tags         attendance-policies
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        id       path  int                              true  "Department ID"
+// @Param        request  body  attendance_policy.PolicyRequest  true  "Пороги"
+// @Success      200  {object}  attendance_policy.PolicyResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/attendance-policies/departments/{id} [put]
+func (h *AttendancePolicyHandler) SetForDepartment(w http.ResponseWriter, r *http.Request) {
+	h.set(w, r, h.service.SetForDepartment)
+}
+
+// DeleteForSubject godoc
+// @Summary      Delete subject attendance policy
+// @Description  Удаляет политику предмета: начинает действовать политика департамента или default.
+// @Tags         attendance-policies
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        id  path  int  true  "Subject ID"
+// @Success      200  {string}  string  "ok"
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/attendance-policies/subjects/{id} [delete]
+func (h *AttendancePolicyHandler) DeleteForSubject(w http.ResponseWriter, r *http.Request) {
+	h.delete(w, r, h.service.DeleteForSubject)
+}
+
+// DeleteForDepartment godoc
+// @Summary      Delete department attendance policy
+// @Description  Удаляет политику департамента: начинает действовать default.
+// @Tags         attendance-policies
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        id  path  int  true  "Department ID"
+// @Success      200  {string}  string  "ok"
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/attendance-policies/departments/{id} [delete]
+func (h *AttendancePolicyHandler) DeleteForDepartment(w http.ResponseWriter, r *http.Request) {
+	h.delete(w, r, h.service.DeleteForDepartment)
+}
+
+func (h *AttendancePolicyHandler) set(w http.ResponseWriter, r *http.Request, set func(context.Context, int64, PolicyRequest, *string) (PolicyResponse, error)) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	id, err := httputil.PathInt64(r, "id", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req PolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.LateAfterSeconds < 0 || req.LeftEarlyAfterSeconds < 0 {
+		response.WriteError(w, http.StatusBadRequest, "thresholds must be >= 0")
+		return
+	}
+	if req.MinCoveragePercent < 0 || req.MinCoveragePercent > 100 {
+		response.WriteError(w, http.StatusBadRequest, "min_coverage_percent must be between 0 and 100")
+		return
+	}
+
+	resp, err := set(r.Context(), id, req, currentUser(r))
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *AttendancePolicyHandler) delete(w http.ResponseWriter, r *http.Request, del func(context.Context, int64) error) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	id, err := httputil.PathInt64(r, "id", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := del(r.Context(), id); err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, "ok")
+}
+
+func currentUser(r *http.Request) *string {
+	id, ok := middleware.UserID(r.Context())
+	if !ok || id == "" {
+		return nil
+	}
+	return &id
+}
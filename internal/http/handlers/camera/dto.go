@@ -0,0 +1,17 @@
+package camera
+
+import "time"
+
+// CameraStatusResponse status: ok, degraded, down или unknown (камера ещё ни разу не присылала heartbeat).
+// registered = false — камера присылает сообщения, но не добавлена в справочник аудиторий.
+type CameraStatusResponse struct {
+	CameraID    string     `json:"camera_id"`
+	Room        *string    `json:"room,omitempty"`
+	Registered  bool       `json:"registered"`
+	Status      string     `json:"status"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+	FPS         *float64   `json:"fps,omitempty"`
+	ErrorCount  int64      `json:"error_count"`
+	LastError   *string    `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
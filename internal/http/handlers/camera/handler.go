@@ -0,0 +1,50 @@
+package camera
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/response"
+)
+
+type CameraService interface {
+	Status(ctx context.Context, roomCode *string) ([]CameraStatusResponse, error)
+}
+
+type CameraHandler struct {
+	service CameraService
+}
+
+func NewCameraHandler(service CameraService) *CameraHandler {
+	return &CameraHandler{service: service}
+}
+
+// Status godoc
+// @Summary      Camera health
+// @Description  Состояние камер по heartbeat из очереди распознавания: время последнего сигнала, fps и число ошибок.
+// @Description  Камера без heartbeat дольше cameras.heartbeat_timeout считается down. Heartbeat читается
+// @Description  вместе с очередью аудитории, поэтому между занятиями камеры в режиме queue тоже выглядят down.
+// @Tags         rooms
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        room  query     string  false  "Room code"
+// @Success      200  {array}   camera.CameraStatusResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/cameras/status [get]
+func (h *CameraHandler) Status(w http.ResponseWriter, r *http.Request) {
+	var roomCode *string
+	if room := strings.TrimSpace(r.URL.Query().Get("room")); room != "" {
+		roomCode = &room
+	}
+
+	resp, err := h.service.Status(r.Context(), roomCode)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
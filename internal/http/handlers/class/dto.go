@@ -0,0 +1,65 @@
+package class
+
+import "time"
+
+type CreateClassRequest struct {
+	Kind      string    `json:"kind" validate:"required"`
+	ID        int64     `json:"id,omitempty" validate:"omitempty,gt=0"`
+	Date      time.Time `json:"date" validate:"required"`
+	SubjectID int64     `json:"subject_id" validate:"required,gt=0"`
+	TeacherID string    `json:"teacher_id" validate:"required"`
+	GroupIDs  []string  `json:"group_ids" validate:"required,min=1"`
+	// EndsAt или DurationMinutes задают конец занятия; без них занятие длится 90 минут.
+	EndsAt          *time.Time `json:"ends_at,omitempty"`
+	DurationMinutes int        `json:"duration_minutes,omitempty" validate:"omitempty,gt=0"`
+	// Room код аудитории из справочника /api/rooms; по её очереди запускается мониторинг.
+	Room   string `json:"room,omitempty"`
+	Online bool   `json:"online"`
+}
+
+type GetClassByIDRequest struct {
+	Kind string `validate:"required"`
+	ID   int64  `validate:"required,gt=0"`
+}
+
+// SetClassRoomRequest room — код аудитории из справочника, "" — занятие без аудитории.
+type SetClassRoomRequest struct {
+	Kind string `json:"-"`
+	ID   int64  `json:"-"`
+	Room string `json:"room"`
+}
+
+// ListClassesRequest ровно один из TeacherID, SubjectID, GroupCode.
+type ListClassesRequest struct {
+	Kind      string    `validate:"required"`
+	TeacherID string    `validate:"omitempty"`
+	SubjectID int64     `validate:"omitempty,gt=0"`
+	GroupCode string    `validate:"omitempty"`
+	From      time.Time `validate:"required"`
+	To        time.Time `validate:"required"`
+}
+
+type ClassResponse struct {
+	Kind            string    `json:"kind"`
+	ID              int64     `json:"id"`
+	Date            time.Time `json:"date"`
+	SubjectID       int64     `json:"subject_id"`
+	TeacherID       string    `json:"teacher_id"`
+	GroupIDs        []string  `json:"group_ids"`
+	EndsAt          time.Time `json:"ends_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Room            string    `json:"room,omitempty"`
+	Online          bool      `json:"online"`
+}
+
+type ClassListItemResponse struct {
+	Kind            string    `json:"kind"`
+	ID              int64     `json:"id"`
+	Date            time.Time `json:"date"`
+	SubjectID       int64     `json:"subject_id"`
+	TeacherID       string    `json:"teacher_id"`
+	EndsAt          time.Time `json:"ends_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Room            string    `json:"room,omitempty"`
+	Online          bool      `json:"online"`
+}
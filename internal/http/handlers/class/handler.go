@@ -0,0 +1,372 @@
+package class
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitoring_backend/internal/domain"
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+type ClassService interface {
+	Create(ctx context.Context, req CreateClassRequest) (ClassResponse, error)
+	GetByID(ctx context.Context, req GetClassByIDRequest) (ClassResponse, error)
+	List(ctx context.Context, req ListClassesRequest) ([]ClassListItemResponse, error)
+	SetRoom(ctx context.Context, userID, role string, req SetClassRoomRequest) (ClassResponse, error)
+}
+
+type ClassHandler struct {
+	service ClassService
+}
+
+func NewClassHandler(service ClassService) *ClassHandler {
+	return &ClassHandler{service: service}
+}
+
+// CreateClass godoc
+// @Summary      Create class
+// @Description  Создаёт занятие любого вида: lecture, practice, lab, exam, consultation.
+// @Tags         classes
+// @Accept       json
+// @Produce      json
+// @Param        request  body      class.CreateClassRequest  true  "Class payload"
+// @Success      201  {object}  class.ClassResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /api/classes [post]
+func (h *ClassHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Kind = strings.ToLower(strings.TrimSpace(req.Kind))
+	if !domain.ClassKind(req.Kind).Valid() {
+		response.WriteError(w, http.StatusBadRequest, "invalid kind")
+		return
+	}
+
+	h.create(w, r, req)
+}
+
+func (h *ClassHandler) create(w http.ResponseWriter, r *http.Request, req CreateClassRequest) {
+	if req.TeacherID == "" || req.SubjectID <= 0 || req.Date.IsZero() || len(req.GroupIDs) == 0 {
+		response.WriteError(w, http.StatusBadRequest, "teacher_id, subject_id, date, group_ids are required")
+		return
+	}
+	endsAt, err := httputil.ClassEnd(req.Date, req.EndsAt, req.DurationMinutes)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.EndsAt, req.DurationMinutes = endsAt, 0
+
+	resp, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// GetClassByID godoc
+// @Summary      Get class by kind and ID
+// @Tags         classes
+// @Produce      json
+// @Param        kind  path      string  true  "Вид занятия"
+// @Param        id    path      int     true  "Class ID"
+// @Success      200  {object}  class.ClassResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /api/classes/{kind}/{id} [get]
+func (h *ClassHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	kind, err := kindFromPath(vars)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id, err := httputil.PathInt64(r, "id", vars)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.getByID(w, r, kind, id)
+}
+
+func (h *ClassHandler) getByID(w http.ResponseWriter, r *http.Request, kind string, id int64) {
+	resp, err := h.service.GetByID(r.Context(), GetClassByIDRequest{Kind: kind, ID: id})
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ListClasses godoc
+// @Summary      List classes
+// @Description  Занятия указанного вида за период. Нужен ровно один из фильтров teacher_id, subject_id, group_code.
+// @Tags         classes
+// @Produce      json
+// @Param        kind        path   string  true   "Вид занятия"
+// @Param        teacher_id  query  string  false  "Teacher ISU"
+// @Param        subject_id  query  int     false  "Subject ID"
+// @Param        group_code  query  string  false  "Group code"
+// @Param        from        query  string  true   "RFC3339 start time"
+// @Param        to          query  string  true   "RFC3339 end time"
+// @Success      200  {array}   class.ClassListItemResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Router       /api/classes/{kind} [get]
+func (h *ClassHandler) List(w http.ResponseWriter, r *http.Request) {
+	kind, err := kindFromPath(mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := r.URL.Query()
+	req := ListClassesRequest{
+		Kind:      kind,
+		TeacherID: strings.TrimSpace(q.Get("teacher_id")),
+		GroupCode: strings.TrimSpace(q.Get("group_code")),
+	}
+	if q.Get("subject_id") != "" {
+		subjectID, err := httputil.QueryInt(r, "subject_id", 0)
+		if err != nil || subjectID <= 0 {
+			response.WriteError(w, http.StatusBadRequest, "invalid query param subject_id")
+			return
+		}
+		req.SubjectID = int64(subjectID)
+	}
+
+	filters := 0
+	for _, set := range []bool{req.TeacherID != "", req.SubjectID > 0, req.GroupCode != ""} {
+		if set {
+			filters++
+		}
+	}
+	if filters != 1 {
+		response.WriteError(w, http.StatusBadRequest, "exactly one of teacher_id, subject_id, group_code is required")
+		return
+	}
+
+	h.list(w, r, req)
+}
+
+// list дочитывает период from–to и отдаёт занятия по уже выбранному фильтру req.
+func (h *ClassHandler) list(w http.ResponseWriter, r *http.Request, req ListClassesRequest) {
+	var err error
+	if req.From, err = httputil.QueryTimeRFC3339(r, "from"); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.To, err = httputil.QueryTimeRFC3339(r, "to"); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.service.List(r.Context(), req)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// SetClassRoom godoc
+// @Summary      Attach class to room
+// @Description  Переносит занятие в аудиторию из справочника (/api/rooms) или убирает аудиторию (room = "").
+// @Description  Мониторинг занятия запускается по очереди его аудитории.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind     path      string                     true  "Вид занятия"
+// @Param        id       path      int                        true  "Class ID"
+// @Param        request  body      class.SetClassRoomRequest  true  "Room"
+// @Success      200  {object}  class.ClassResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/room [put]
+func (h *ClassHandler) SetRoom(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok || userID == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	role, _ := middleware.Role(r.Context())
+
+	vars := mux.Vars(r)
+	kind, err := kindFromPath(vars)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id, err := httputil.PathInt64(r, "id", vars)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req SetClassRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Kind, req.ID = kind, id
+	req.Room = strings.TrimSpace(req.Room)
+
+	resp, err := h.service.SetRoom(r.Context(), userID, role, req)
+	if errors.Is(err, domain.ErrClassAccessDenied) {
+		response.WriteError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// CreatePractice godoc
+// @Summary      Create practice
+// @Description  Устарел: используйте POST /api/classes с kind = "practice".
+// @Tags         practices
+// @Accept       json
+// @Produce      json
+// @Param        request  body      class.CreateClassRequest  true  "Practice payload (kind не нужен)"
+// @Success      201  {object}  class.ClassResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Deprecated
+// @Router       /api/practices [post]
+func (h *ClassHandler) CreatePractice(w http.ResponseWriter, r *http.Request) {
+	var req CreateClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Kind = string(domain.ClassPractice)
+
+	h.create(w, r, req)
+}
+
+// GetPracticeByID godoc
+// @Summary      Get practice by ID
+// @Description  Устарел: используйте GET /api/classes/practice/{id}.
+// @Tags         practices
+// @Produce      json
+// @Param        id  path      int  true  "Practice ID"
+// @Success      200  {object}  class.ClassResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Deprecated
+// @Router       /api/practices/{id} [get]
+func (h *ClassHandler) GetPracticeByID(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathInt64(r, "id", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.getByID(w, r, string(domain.ClassPractice), id)
+}
+
+// ListPracticesByTeacher godoc
+// @Summary      List practices by teacher
+// @Description  Устарел: используйте GET /api/classes/practice?teacher_id=.
+// @Tags         practices
+// @Produce      json
+// @Param        isu   path   string  true  "Teacher ISU"
+// @Param        from  query  string  true  "RFC3339 start time"
+// @Param        to    query  string  true  "RFC3339 end time"
+// @Success      200  {array}   class.ClassListItemResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Deprecated
+// @Router       /api/teachers/{isu}/practices [get]
+func (h *ClassHandler) ListPracticesByTeacher(w http.ResponseWriter, r *http.Request) {
+	teacherID, err := httputil.PathString("isu", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.list(w, r, ListClassesRequest{Kind: string(domain.ClassPractice), TeacherID: teacherID})
+}
+
+// ListPracticesBySubject godoc
+// @Summary      List practices by subject
+// @Description  Устарел: используйте GET /api/classes/practice?subject_id=.
+// @Tags         practices
+// @Produce      json
+// @Param        id    path   int     true  "Subject ID"
+// @Param        from  query  string  true  "RFC3339 start time"
+// @Param        to    query  string  true  "RFC3339 end time"
+// @Success      200  {array}   class.ClassListItemResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Deprecated
+// @Router       /api/subjects/{id}/practices [get]
+func (h *ClassHandler) ListPracticesBySubject(w http.ResponseWriter, r *http.Request) {
+	subjectID, err := httputil.PathInt64(r, "id", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.list(w, r, ListClassesRequest{Kind: string(domain.ClassPractice), SubjectID: subjectID})
+}
+
+// ListPracticesByGroup godoc
+// @Summary      List practices by group
+// @Description  Устарел: используйте GET /api/classes/practice?group_code=.
+// @Tags         practices
+// @Produce      json
+// @Param        code  path   string  true  "Group code"
+// @Param        from  query  string  true  "RFC3339 start time"
+// @Param        to    query  string  true  "RFC3339 end time"
+// @Success      200  {array}   class.ClassListItemResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Deprecated
+// @Router       /api/groups/{code}/practices [get]
+func (h *ClassHandler) ListPracticesByGroup(w http.ResponseWriter, r *http.Request) {
+	code, err := httputil.PathString("code", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.list(w, r, ListClassesRequest{Kind: string(domain.ClassPractice), GroupCode: code})
+}
+
+func kindFromPath(vars map[string]string) (string, error) {
+	kind := strings.ToLower(strings.TrimSpace(vars["kind"]))
+	if !domain.ClassKind(kind).Valid() {
+		return "", errors.New("invalid kind")
+	}
+	return kind, nil
+}
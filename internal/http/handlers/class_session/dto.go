@@ -0,0 +1,61 @@
+package class_session
+
+import "time"
+
+type StartClassRequest struct {
+	ClassID int64 `json:"class_id"`
+	// Queue явная очередь занятия (rabbit.mode = "queue") или routing key на topic exchange
+	// (rabbit.mode = "topic"). Обычно не передаётся: берётся очередь аудитории занятия,
+	// а без неё — route по умолчанию ("<kind>.<class_id>" в режиме topic).
+	Queue string `json:"queue,omitempty"`
+}
+
+type StopClassRequest struct {
+	ClassID int64 `json:"class_id"`
+}
+
+// StartLectureRequest тело устаревшего /api/lecture/start, то же, что StartClassRequest для лекций.
+type StartLectureRequest struct {
+	LectureID int64  `json:"lecture_id"`
+	Queue     string `json:"queue,omitempty"`
+}
+
+// StopLectureRequest тело устаревшего /api/lecture/stop.
+type StopLectureRequest struct {
+	LectureID int64 `json:"lecture_id"`
+}
+
+// SessionResponse kind и class_id заполнены всегда; lecture_id — у лекций,
+// для совместимости с /api/lecture/sessions.
+type SessionResponse struct {
+	ID        int64      `json:"id"`
+	Kind      string     `json:"kind"`
+	ClassID   int64      `json:"class_id"`
+	LectureID int64      `json:"lecture_id,omitempty"`
+	Queue     string     `json:"queue,omitempty"`
+	State     string     `json:"state"`
+	StartedAt time.Time  `json:"started_at"`
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+	StartedBy *string    `json:"started_by,omitempty"`
+	StoppedBy *string    `json:"stopped_by,omitempty"`
+	// Consumer состояние подключения консьюмера, только у запущенных сессий
+	Consumer *ConsumerStatus `json:"consumer,omitempty"`
+}
+
+type ConsumerStatus struct {
+	State     string    `json:"state"`
+	Attempt   int       `json:"attempt,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Since     time.Time `json:"since"`
+}
+
+type PageMeta struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Total    int `json:"total"`
+}
+
+type ListSessionsResponse struct {
+	Items []SessionResponse `json:"items"`
+	Meta  PageMeta          `json:"meta"`
+}
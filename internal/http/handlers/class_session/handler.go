@@ -0,0 +1,337 @@
+package class_session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitoring_backend/internal/domain"
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+type ClassSessionService interface {
+	Start(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, queue string) error
+	Stop(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) error
+	List(ctx context.Context, kind domain.ClassKind, filter domain.ClassSessionFilter) (ListSessionsResponse, error)
+}
+
+// ClassSessionHandler запуск и остановка мониторинга занятий (консьюмеров RabbitMQ) и их сессии.
+type ClassSessionHandler struct {
+	service ClassSessionService
+}
+
+func NewClassSessionHandler(service ClassSessionService) *ClassSessionHandler {
+	return &ClassSessionHandler{service: service}
+}
+
+// Start godoc
+// @Summary Start class processing
+// @Description Запускает обработку очереди RabbitMQ для занятия любого вида. Сессия сохраняется в БД и поднимается заново после рестарта.
+// @Description Доступ: администратор или преподаватель занятия.
+// @Description Очередь обычно не передаётся: она берётся из аудитории занятия (см. /api/rooms).
+// @Description В режиме topic вместо очереди используется routing key (по умолчанию <kind>.<class_id>).
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param kind path string true "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Param request body class_session.StartClassRequest true "Class and RabbitMQ queue"
+// @Success 200 {string} string "Consumer started"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "Not an admin or the class teacher"
+// @Failure 404 {object} response.ErrorResponse "Unknown class kind"
+// @Failure 409 {object} response.ErrorResponse "Session is being started or stopped"
+// @Failure 503 {object} response.ErrorResponse "Server is shutting down"
+// @Security BearerAuth
+// @Router /api/classes/{kind}/start [post]
+func (h *ClassSessionHandler) Start(w http.ResponseWriter, r *http.Request) {
+	kind, ok := kindFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req StartClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	h.start(w, r, kind, req.ClassID, req.Queue)
+}
+
+// Stop godoc
+// @Summary Stop class processing
+// @Description Останавливает консьюмер RabbitMQ занятия. Доступ: администратор или преподаватель занятия.
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param kind path string true "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Param request body class_session.StopClassRequest true "Class identifier"
+// @Success 200 {string} string "ok"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "Not an admin or the class teacher"
+// @Failure 404 {object} response.ErrorResponse "Class not found"
+// @Failure 409 {object} response.ErrorResponse "Session is being started or stopped"
+// @Security BearerAuth
+// @Router /api/classes/{kind}/stop [post]
+func (h *ClassSessionHandler) Stop(w http.ResponseWriter, r *http.Request) {
+	kind, ok := kindFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req StopClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	h.stop(w, r, kind, req.ClassID)
+}
+
+// List godoc
+// @Summary List class sessions
+// @Description Возвращает активные и завершённые сессии мониторинга занятий указанного вида (новые сверху).
+// @Description Для запущенных сессий consumer содержит состояние подключения к RabbitMQ: connecting, connected, reconnecting.
+// @Tags classes
+// @Produce json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param kind path string true "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Param class_id query int false "Фильтр по занятию"
+// @Param state query string false "Фильтр по состоянию: running, stopped, finished, failed"
+// @Param page query int false "Страница (по умолчанию 1)"
+// @Param page_size query int false "Размер страницы (по умолчанию 50)"
+// @Success 200 {object} class_session.ListSessionsResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse "Unknown class kind"
+// @Failure 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/classes/{kind}/sessions [get]
+func (h *ClassSessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	kind, ok := kindFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	h.list(w, r, kind, "class_id")
+}
+
+// StartLecture godoc
+// @Summary Start lecture processing
+// @Description Устарел: используйте POST /api/classes/lecture/start.
+// @Tags lecture
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param request body class_session.StartLectureRequest true "Lecture and RabbitMQ queue"
+// @Success 200 {string} string "Consumer started"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "Not an admin or the lecture teacher"
+// @Failure 409 {object} response.ErrorResponse "Session is being started or stopped"
+// @Failure 503 {object} response.ErrorResponse "Server is shutting down"
+// @Security BearerAuth
+// @Deprecated
+// @Router /api/lecture/start [post]
+func (h *ClassSessionHandler) StartLecture(w http.ResponseWriter, r *http.Request) {
+	var req StartLectureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	h.start(w, r, domain.ClassLecture, req.LectureID, req.Queue)
+}
+
+// StopLecture godoc
+// @Summary Stop lecture processing
+// @Description Устарел: используйте POST /api/classes/lecture/stop.
+// @Tags lecture
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param request body class_session.StopLectureRequest true "Lecture identifier"
+// @Success 200 {string} string "ok"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse "Not an admin or the lecture teacher"
+// @Failure 404 {object} response.ErrorResponse "Lecture not found"
+// @Failure 409 {object} response.ErrorResponse "Session is being started or stopped"
+// @Security BearerAuth
+// @Deprecated
+// @Router /api/lecture/stop [post]
+func (h *ClassSessionHandler) StopLecture(w http.ResponseWriter, r *http.Request) {
+	var req StopLectureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	h.stop(w, r, domain.ClassLecture, req.LectureID)
+}
+
+// ListLectureSessions godoc
+// @Summary List lecture sessions
+// @Description Устарел: используйте GET /api/classes/lecture/sessions (фильтр class_id вместо lecture_id).
+// @Tags lecture
+// @Produce json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param lecture_id query int false "Фильтр по лекции"
+// @Param state query string false "Фильтр по состоянию: running, stopped, finished, failed"
+// @Param page query int false "Страница (по умолчанию 1)"
+// @Param page_size query int false "Размер страницы (по умолчанию 50)"
+// @Success 200 {object} class_session.ListSessionsResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Deprecated
+// @Router /api/lecture/sessions [get]
+func (h *ClassSessionHandler) ListLectureSessions(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, domain.ClassLecture, "lecture_id")
+}
+
+func (h *ClassSessionHandler) start(w http.ResponseWriter, r *http.Request, kind domain.ClassKind, classID int64, queue string) {
+	userID, role, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if classID <= 0 {
+		response.WriteError(w, http.StatusBadRequest, "invalid class_id")
+		return
+	}
+
+	err := h.service.Start(r.Context(), userID, role, kind, classID, strings.TrimSpace(queue))
+	switch {
+	case errors.Is(err, domain.ErrClassSessionRouteRequired):
+		response.WriteError(w, http.StatusBadRequest, "Invalid queue name")
+	case errors.Is(err, domain.ErrClassSessionShuttingDown):
+		response.WriteError(w, http.StatusServiceUnavailable, "Server is shutting down")
+	case errors.Is(err, domain.ErrClassSessionAlreadyRunning):
+		response.WriteJSON(w, http.StatusOK, "Consumer already running")
+	case errors.Is(err, domain.ErrClassSessionBusy):
+		response.WriteError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrClassAccessDenied):
+		response.WriteError(w, http.StatusForbidden, err.Error())
+	case err != nil:
+		httputil.WriteServiceError(w, err)
+	default:
+		response.WriteJSON(w, http.StatusOK, "Consumer started")
+	}
+}
+
+func (h *ClassSessionHandler) stop(w http.ResponseWriter, r *http.Request, kind domain.ClassKind, classID int64) {
+	userID, role, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.Stop(r.Context(), userID, role, kind, classID)
+	if errors.Is(err, domain.ErrClassSessionNotFound) {
+		if kind == domain.ClassLecture {
+			response.WriteError(w, http.StatusNotFound, "Lecture not found")
+		} else {
+			response.WriteError(w, http.StatusNotFound, "Class not found")
+		}
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrClassSessionBusy):
+		response.WriteError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, domain.ErrClassAccessDenied):
+		response.WriteError(w, http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, "ok")
+}
+
+// list idParam — имя query-параметра с id занятия: class_id или lecture_id у устаревшего эндпоинта.
+func (h *ClassSessionHandler) list(w http.ResponseWriter, r *http.Request, kind domain.ClassKind, idParam string) {
+	filter, err := parseSessionsFilter(r, idParam)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.service.List(r.Context(), kind, filter)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+func kindFromPath(w http.ResponseWriter, r *http.Request) (domain.ClassKind, bool) {
+	kind := domain.ClassKind(strings.ToLower(strings.TrimSpace(mux.Vars(r)["kind"])))
+	if !kind.Valid() {
+		response.WriteError(w, http.StatusNotFound, "unknown class kind")
+		return "", false
+	}
+	return kind, true
+}
+
+func parseSessionsFilter(r *http.Request, idParam string) (domain.ClassSessionFilter, error) {
+	page, err := httputil.QueryInt(r, "page", 1)
+	if err != nil {
+		return domain.ClassSessionFilter{}, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	pageSize, err := httputil.QueryInt(r, "page_size", 50)
+	if err != nil {
+		return domain.ClassSessionFilter{}, err
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+
+	filter := domain.ClassSessionFilter{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	if r.URL.Query().Get(idParam) != "" {
+		classID, err := httputil.QueryInt(r, idParam, 0)
+		if err != nil {
+			return domain.ClassSessionFilter{}, err
+		}
+		id := int64(classID)
+		filter.LectureID = &id
+	}
+
+	if raw := strings.TrimSpace(r.URL.Query().Get("state")); raw != "" {
+		state := domain.ClassSessionState(strings.ToLower(raw))
+		switch state {
+		case domain.ClassSessionRunning, domain.ClassSessionStopped, domain.ClassSessionFinished, domain.ClassSessionFailed:
+		default:
+			return domain.ClassSessionFilter{}, errors.New("invalid query param state")
+		}
+		filter.State = &state
+	}
+
+	return filter, nil
+}
+
+func currentUser(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok || userID == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return "", "", false
+	}
+	role, _ := middleware.Role(r.Context())
+	return userID, role, true
+}
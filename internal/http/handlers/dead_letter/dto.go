@@ -0,0 +1,79 @@
+package dead_letter
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ListDeadLettersRequest struct {
+	Status    *string
+	Reason    *string
+	LectureID *int64
+	Kind      *string
+	Page      int
+	PageSize  int
+}
+
+type DeadLetterItem struct {
+	ID             int64      `json:"id"`
+	SourceQueue    string     `json:"source_queue"`
+	Kind           string     `json:"kind"`
+	LectureID      *int64     `json:"lecture_id,omitempty"`
+	Reason         string     `json:"reason"`
+	Error          string     `json:"error"`
+	Status         string     `json:"status"`
+	ReplayAttempts int        `json:"replay_attempts"`
+	FailedAt       time.Time  `json:"failed_at"`
+	ArchivedAt     time.Time  `json:"archived_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy     *string    `json:"resolved_by,omitempty"`
+}
+
+type PageMeta struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Total    int `json:"total"`
+}
+
+type ListDeadLettersResponse struct {
+	Items []DeadLetterItem `json:"items"`
+	Meta  PageMeta         `json:"meta"`
+}
+
+// DeadLetterResponse сообщение целиком. Body отдаётся как есть, если это JSON,
+// иначе — в BodyBase64.
+type DeadLetterResponse struct {
+	DeadLetterItem
+	Headers     map[string]any  `json:"headers"`
+	ContentType *string         `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+	BodyBase64  string          `json:"body_base64,omitempty"`
+}
+
+// BulkRequest выбирает pending-сообщения: либо явно по ids, либо по фильтру reason/lecture_id/kind
+// (не больше limit штук, самые старые первыми).
+type BulkRequest struct {
+	IDs       []int64 `json:"ids,omitempty"`
+	Reason    *string `json:"reason,omitempty"`
+	LectureID *int64  `json:"lecture_id,omitempty"`
+	Kind      *string `json:"kind,omitempty"`
+	Limit     int     `json:"limit,omitempty"`
+}
+
+type ReplayFailure struct {
+	ID     int64  `json:"id"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+}
+
+type ReplayResponse struct {
+	Replayed []int64         `json:"replayed"`
+	Failed   []ReplayFailure `json:"failed"`
+	// Skipped — не найдены или уже не pending
+	Skipped []int64 `json:"skipped"`
+}
+
+type DiscardResponse struct {
+	Discarded []int64 `json:"discarded"`
+	Skipped   []int64 `json:"skipped"`
+}
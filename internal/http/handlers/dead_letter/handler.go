@@ -0,0 +1,269 @@
+package dead_letter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitoring_backend/internal/domain"
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+// maxBulkIDs столько сообщений можно обработать одним запросом.
+const maxBulkIDs = 500
+
+type DeadLetterService interface {
+	List(ctx context.Context, req ListDeadLettersRequest) (ListDeadLettersResponse, error)
+	Get(ctx context.Context, id int64) (DeadLetterResponse, error)
+	Replay(ctx context.Context, req BulkRequest, by *string) (ReplayResponse, error)
+	Discard(ctx context.Context, req BulkRequest, by *string) (DiscardResponse, error)
+}
+
+type DeadLetterHandler struct {
+	service DeadLetterService
+}
+
+func NewDeadLetterHandler(service DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{service: service}
+}
+
+// List godoc
+// @Summary      List dead letters
+// @Description  Сообщения распознавания, которые не удалось обработать (старые первыми).
+// @Tags         dead-letters
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        status      query  string  false  "pending (по умолчанию), replayed, discarded, all"
+// @Param        reason      query  string  false  "Причина отказа, например unknown_student"
+// @Param        lecture_id  query  int     false  "Фильтр по занятию (id занятия вида kind)"
+// @Param        kind        query  string  false  "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Param        page        query  int     false  "Страница (по умолчанию 1)"
+// @Param        page_size   query  int     false  "Размер страницы (по умолчанию 50)"
+// @Success      200  {object}  dead_letter.ListDeadLettersResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/dead-letters [get]
+func (h *DeadLetterHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	req, err := parseListRequest(r)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.service.List(r.Context(), req)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Get godoc
+// @Summary      Inspect dead letter
+// @Description  Возвращает сообщение целиком: заголовки, тело и причину отказа.
+// @Tags         dead-letters
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        id  path  int  true  "Dead letter ID"
+// @Success      200  {object}  dead_letter.DeadLetterResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/dead-letters/{id} [get]
+func (h *DeadLetterHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	id, err := httputil.PathInt64(r, "id", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Replay godoc
+// @Summary      Replay dead letters
+// @Description  Повторно прогоняет выбранные pending-сообщения через обработку снапшотов.
+// @Description  Успешные помечаются replayed, неуспешные остаются pending с обновлённой причиной.
+// @Tags         dead-letters
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        request  body  dead_letter.BulkRequest  true  "ids или фильтр reason/lecture_id"
+// @Success      200  {object}  dead_letter.ReplayResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/dead-letters/replay [post]
+func (h *DeadLetterHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	req, err := decodeBulkRequest(r)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.service.Replay(r.Context(), req, currentUser(r))
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Discard godoc
+// @Summary      Discard dead letters
+// @Description  Помечает выбранные pending-сообщения как discarded, больше они не переигрываются.
+// @Tags         dead-letters
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        request  body  dead_letter.BulkRequest  true  "ids или фильтр reason/lecture_id"
+// @Success      200  {object}  dead_letter.DiscardResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/dead-letters/discard [post]
+func (h *DeadLetterHandler) Discard(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	req, err := decodeBulkRequest(r)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.service.Discard(r.Context(), req, currentUser(r))
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+func parseListRequest(r *http.Request) (ListDeadLettersRequest, error) {
+	page, err := httputil.QueryInt(r, "page", 1)
+	if err != nil {
+		return ListDeadLettersRequest{}, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	pageSize, err := httputil.QueryInt(r, "page_size", 50)
+	if err != nil {
+		return ListDeadLettersRequest{}, err
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+
+	req := ListDeadLettersRequest{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	q := r.URL.Query()
+
+	switch status := strings.ToLower(strings.TrimSpace(q.Get("status"))); status {
+	case "":
+		pending := "pending"
+		req.Status = &pending
+	case "all":
+	case "pending", "replayed", "discarded":
+		req.Status = &status
+	default:
+		return ListDeadLettersRequest{}, errors.New("invalid query param status")
+	}
+
+	if reason := strings.TrimSpace(q.Get("reason")); reason != "" {
+		req.Reason = &reason
+	}
+
+	if q.Get("lecture_id") != "" {
+		lectureID, err := httputil.QueryInt(r, "lecture_id", 0)
+		if err != nil {
+			return ListDeadLettersRequest{}, err
+		}
+		id := int64(lectureID)
+		req.LectureID = &id
+	}
+
+	if kind := strings.ToLower(strings.TrimSpace(q.Get("kind"))); kind != "" {
+		if !validKind(kind) {
+			return ListDeadLettersRequest{}, errors.New("invalid query param kind")
+		}
+		req.Kind = &kind
+	}
+
+	return req, nil
+}
+
+func validKind(kind string) bool {
+	return domain.ClassKind(kind).Valid()
+}
+
+func decodeBulkRequest(r *http.Request) (BulkRequest, error) {
+	var req BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return BulkRequest{}, errors.New("invalid request body")
+	}
+	// без фильтра операция затронула бы все pending-сообщения сразу
+	if len(req.IDs) == 0 && req.Reason == nil && req.LectureID == nil {
+		return BulkRequest{}, errors.New("ids, reason or lecture_id is required")
+	}
+	if req.Kind != nil && !validKind(*req.Kind) {
+		return BulkRequest{}, errors.New("invalid kind")
+	}
+	if len(req.IDs) > maxBulkIDs {
+		return BulkRequest{}, errors.New("too many ids")
+	}
+	return req, nil
+}
+
+func currentUser(r *http.Request) *string {
+	id, ok := middleware.UserID(r.Context())
+	if !ok || id == "" {
+		return nil
+	}
+	return &id
+}
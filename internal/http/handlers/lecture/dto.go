@@ -7,6 +7,12 @@ type CreateLectureRequest struct {
 	SubjectID int64     `json:"subject_id" validate:"required,gt=0"`
 	TeacherID string    `json:"teacher_id" validate:"required"`
 	GroupIDs  []string  `json:"group_ids" validate:"required,min=1"`
+	// EndsAt или DurationMinutes задают конец занятия; без них занятие длится 90 минут.
+	EndsAt          *time.Time `json:"ends_at,omitempty"`
+	DurationMinutes int        `json:"duration_minutes,omitempty" validate:"omitempty,gt=0"`
+	// Room код аудитории из справочника /api/rooms; по её очереди запускается мониторинг.
+	Room   string `json:"room,omitempty"`
+	Online bool   `json:"online"`
 }
 
 type GetLectureByIDRequest struct {
@@ -32,16 +38,24 @@ type ListLecturesByGroupRequest struct {
 }
 
 type LectureResponse struct {
-	ID        int64     `json:"id"`
-	Date      time.Time `json:"date"`
-	SubjectID int64     `json:"subject_id"`
-	TeacherID string    `json:"teacher_id"`
-	GroupIDs  []string  `json:"group_ids"`
+	ID              int64     `json:"id"`
+	Date            time.Time `json:"date"`
+	SubjectID       int64     `json:"subject_id"`
+	TeacherID       string    `json:"teacher_id"`
+	GroupIDs        []string  `json:"group_ids"`
+	EndsAt          time.Time `json:"ends_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Room            string    `json:"room,omitempty"`
+	Online          bool      `json:"online"`
 }
 
 type LectureListItemResponse struct {
-	ID        int64     `json:"id"`
-	Date      time.Time `json:"date"`
-	SubjectID int64     `json:"subject_id"`
-	TeacherID string    `json:"teacher_id"`
+	ID              int64     `json:"id"`
+	Date            time.Time `json:"date"`
+	SubjectID       int64     `json:"subject_id"`
+	TeacherID       string    `json:"teacher_id"`
+	EndsAt          time.Time `json:"ends_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Room            string    `json:"room,omitempty"`
+	Online          bool      `json:"online"`
 }
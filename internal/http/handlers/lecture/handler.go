@@ -49,6 +49,12 @@ func (h *LectureHandler) Create(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, "teacher_id, subject_id, date, group_ids are required")
 		return
 	}
+	endsAt, err := httputil.ClassEnd(req.Date, req.EndsAt, req.DurationMinutes)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.EndsAt, req.DurationMinutes = endsAt, 0
 
 	resp, err := h.service.Create(r.Context(), req)
 	if err != nil {
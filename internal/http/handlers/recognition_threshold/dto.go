@@ -0,0 +1,24 @@
+package recognition_threshold
+
+import "time"
+
+// ThresholdRequest пороги similarity: не ниже accept_threshold — посещение, не ниже review_threshold —
+// на проверку преподавателю, ниже — снапшот отбрасывается. 0 <= review_threshold <= accept_threshold <= 1.
+type ThresholdRequest struct {
+	AcceptThreshold float64 `json:"accept_threshold"`
+	ReviewThreshold float64 `json:"review_threshold"`
+}
+
+type ThresholdResponse struct {
+	DepartmentID    int64     `json:"department_id"`
+	AcceptThreshold float64   `json:"accept_threshold"`
+	ReviewThreshold float64   `json:"review_threshold"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	UpdatedBy       *string   `json:"updated_by,omitempty"`
+}
+
+// ListThresholdsResponse default — пороги из конфига для департаментов без своих порогов.
+type ListThresholdsResponse struct {
+	Default ThresholdRequest    `json:"default"`
+	Items   []ThresholdResponse `json:"items"`
+}
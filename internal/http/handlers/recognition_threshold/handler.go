@@ -0,0 +1,144 @@
+package recognition_threshold
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+type RecognitionThresholdService interface {
+	List(ctx context.Context) (ListThresholdsResponse, error)
+	SetForDepartment(ctx context.Context, departmentID int64, req ThresholdRequest, by *string) (ThresholdResponse, error)
+	DeleteForDepartment(ctx context.Context, departmentID int64) error
+}
+
+type RecognitionThresholdHandler struct {
+	service RecognitionThresholdService
+}
+
+func NewRecognitionThresholdHandler(service RecognitionThresholdService) *RecognitionThresholdHandler {
+	return &RecognitionThresholdHandler{service: service}
+}
+
+// List godoc
+// @Summary      List recognition thresholds
+// @Description  Пороги similarity распознавания: значения по умолчанию и пороги департаментов.
+// @Description  Для снапшота действуют пороги департамента группы студента, без них — default.
+// @Tags         recognition-thresholds
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Success      200  {object}  recognition_threshold.ListThresholdsResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/recognition-thresholds [get]
+func (h *RecognitionThresholdHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	resp, err := h.service.List(r.Context())
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// SetForDepartment godoc
+// @Summary      Set department recognition thresholds
+// @Description  Создаёт или заменяет пороги similarity для департамента (по группе студента).
+// @Description  Уже записанные снапшоты не пересматриваются.
+// @Tags         recognition-thresholds
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        id       path  int                                     true  "Department ID"
+// @Param        request  body  recognition_threshold.ThresholdRequest  true  "Пороги"
+// @Success      200  {object}  recognition_threshold.ThresholdResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/recognition-thresholds/departments/{id} [put]
+func (h *RecognitionThresholdHandler) SetForDepartment(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	id, err := httputil.PathInt64(r, "id", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req ThresholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.ReviewThreshold < 0 || req.ReviewThreshold > req.AcceptThreshold || req.AcceptThreshold > 1 {
+		response.WriteError(w, http.StatusBadRequest, "thresholds must satisfy 0 <= review_threshold <= accept_threshold <= 1")
+		return
+	}
+
+	resp, err := h.service.SetForDepartment(r.Context(), id, req, currentUser(r))
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// DeleteForDepartment godoc
+// @Summary      Delete department recognition thresholds
+// @Description  Удаляет пороги департамента: начинают действовать default.
+// @Tags         recognition-thresholds
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        id  path  int  true  "Department ID"
+// @Success      200  {string}  string  "ok"
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/admin/recognition-thresholds/departments/{id} [delete]
+func (h *RecognitionThresholdHandler) DeleteForDepartment(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	id, err := httputil.PathInt64(r, "id", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.DeleteForDepartment(r.Context(), id); err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, "ok")
+}
+
+func currentUser(r *http.Request) *string {
+	id, ok := middleware.UserID(r.Context())
+	if !ok || id == "" {
+		return nil
+	}
+	return &id
+}
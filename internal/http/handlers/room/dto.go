@@ -0,0 +1,40 @@
+package room
+
+import "time"
+
+// CreateRoomRequest queue — очередь камер аудитории (rabbit.mode = "queue")
+// или routing key на topic exchange (rabbit.mode = "topic").
+type CreateRoomRequest struct {
+	Code     string  `json:"code" validate:"required"`
+	Building *string `json:"building,omitempty"`
+	Capacity *int    `json:"capacity,omitempty" validate:"omitempty,gt=0"`
+	Queue    string  `json:"queue,omitempty"`
+}
+
+// UpdateRoomRequest заменяет все поля аудитории, кроме кода.
+type UpdateRoomRequest struct {
+	Building *string `json:"building,omitempty"`
+	Capacity *int    `json:"capacity,omitempty" validate:"omitempty,gt=0"`
+	Queue    string  `json:"queue,omitempty"`
+}
+
+// AddCameraRequest id — camera_id, с которым камера присылает сообщения распознавания.
+type AddCameraRequest struct {
+	ID          string  `json:"id" validate:"required"`
+	Description *string `json:"description,omitempty"`
+}
+
+type CameraResponse struct {
+	ID          string    `json:"id"`
+	RoomCode    string    `json:"room_code"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RoomResponse struct {
+	Code     string           `json:"code"`
+	Building *string          `json:"building,omitempty"`
+	Capacity *int             `json:"capacity,omitempty"`
+	Queue    string           `json:"queue,omitempty"`
+	Cameras  []CameraResponse `json:"cameras"`
+}
@@ -0,0 +1,291 @@
+package room
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+type RoomService interface {
+	Create(ctx context.Context, req CreateRoomRequest) (RoomResponse, error)
+	GetByCode(ctx context.Context, code string) (RoomResponse, error)
+	List(ctx context.Context) ([]RoomResponse, error)
+	Update(ctx context.Context, code string, req UpdateRoomRequest) (RoomResponse, error)
+	Delete(ctx context.Context, code string) error
+	AddCamera(ctx context.Context, roomCode string, req AddCameraRequest) (CameraResponse, error)
+	DeleteCamera(ctx context.Context, roomCode, cameraID string) error
+}
+
+type RoomHandler struct {
+	service RoomService
+}
+
+func NewRoomHandler(service RoomService) *RoomHandler {
+	return &RoomHandler{service: service}
+}
+
+// CreateRoom godoc
+// @Summary      Create room
+// @Description  Добавляет аудиторию в справочник. Мониторинг занятий в аудитории запускается по её очереди.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        request  body      room.CreateRoomRequest  true  "Room payload"
+// @Success      201  {object}  room.RoomResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/rooms [post]
+func (h *RoomHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	var req CreateRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	if req.Code == "" {
+		response.WriteError(w, http.StatusBadRequest, "code is required")
+		return
+	}
+	if req.Capacity != nil && *req.Capacity <= 0 {
+		response.WriteError(w, http.StatusBadRequest, "capacity must be positive")
+		return
+	}
+
+	resp, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// ListRooms godoc
+// @Summary      List rooms
+// @Tags         rooms
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Success      200  {array}   room.RoomResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/rooms [get]
+func (h *RoomHandler) List(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.List(r.Context())
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// GetRoom godoc
+// @Summary      Get room by code
+// @Tags         rooms
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        code  path      string  true  "Room code"
+// @Success      200  {object}  room.RoomResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/rooms/{code} [get]
+func (h *RoomHandler) GetByCode(w http.ResponseWriter, r *http.Request) {
+	code, err := httputil.PathString("code", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.service.GetByCode(r.Context(), code)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// UpdateRoom godoc
+// @Summary      Update room
+// @Description  Заменяет корпус, вместимость и очередь аудитории. Уже запущенные консьюмеры продолжают читать старую очередь.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        code     path      string                  true  "Room code"
+// @Param        request  body      room.UpdateRoomRequest  true  "Room payload"
+// @Success      200  {object}  room.RoomResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/rooms/{code} [put]
+func (h *RoomHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	code, err := httputil.PathString("code", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req UpdateRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Capacity != nil && *req.Capacity <= 0 {
+		response.WriteError(w, http.StatusBadRequest, "capacity must be positive")
+		return
+	}
+
+	resp, err := h.service.Update(r.Context(), code, req)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// DeleteRoom godoc
+// @Summary      Delete room
+// @Description  Удаляет аудиторию вместе с камерами. Аудиторию, в которой есть занятия, удалить нельзя.
+// @Tags         rooms
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        code  path      string  true  "Room code"
+// @Success      200  {string}  string  "ok"
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/rooms/{code} [delete]
+func (h *RoomHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	code, err := httputil.PathString("code", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), code); err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, "ok")
+}
+
+// AddCamera godoc
+// @Summary      Add camera to room
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        code     path      string                 true  "Room code"
+// @Param        request  body      room.AddCameraRequest  true  "Camera payload"
+// @Success      201  {object}  room.CameraResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      409  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/rooms/{code}/cameras [post]
+func (h *RoomHandler) AddCamera(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	code, err := httputil.PathString("code", mux.Vars(r))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req AddCameraRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.ID = strings.TrimSpace(req.ID)
+	if req.ID == "" {
+		response.WriteError(w, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	resp, err := h.service.AddCamera(r.Context(), code, req)
+	if err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusCreated, resp)
+}
+
+// DeleteCamera godoc
+// @Summary      Remove camera from room
+// @Tags         rooms
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        code       path      string  true  "Room code"
+// @Param        camera_id  path      string  true  "Camera ID"
+// @Success      200  {string}  string  "ok"
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/rooms/{code}/cameras/{camera_id} [delete]
+func (h *RoomHandler) DeleteCamera(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.WriteError(w, http.StatusForbidden, "Invalid role")
+		return
+	}
+
+	vars := mux.Vars(r)
+	code, err := httputil.PathString("code", vars)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	cameraID, err := httputil.PathString("camera_id", vars)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.DeleteCamera(r.Context(), code, cameraID); err != nil {
+		httputil.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, "ok")
+}
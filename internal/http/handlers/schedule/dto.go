@@ -0,0 +1,44 @@
+package schedule
+
+import "time"
+
+// SetModeRequest mode: auto — мониторингом управляет планировщик, manual — только вручную.
+type SetModeRequest struct {
+	Mode   string `json:"mode"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ScheduleResponse start_at и stop_at — когда планировщик запускает и останавливает мониторинг
+// в режиме auto. updated_* заполнены, если режим менялся вручную.
+type ScheduleResponse struct {
+	Kind      string     `json:"kind"`
+	ClassID   int64      `json:"class_id"`
+	Mode      string     `json:"mode"`
+	StartAt   time.Time  `json:"start_at"`
+	StopAt    time.Time  `json:"stop_at"`
+	Reason    *string    `json:"reason,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy *string    `json:"updated_by,omitempty"`
+}
+
+// AuditItem actor пустой у действий планировщика (auto_start, auto_stop);
+// у set_mode, manual_start и manual_stop это пользователь.
+type AuditItem struct {
+	ID        int64     `json:"id"`
+	Action    string    `json:"action"`
+	Mode      *string   `json:"mode,omitempty"`
+	Reason    *string   `json:"reason,omitempty"`
+	Actor     *string   `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PageMeta struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Total    int `json:"total"`
+}
+
+type ListAuditResponse struct {
+	Items []AuditItem `json:"items"`
+	Meta  PageMeta    `json:"meta"`
+}
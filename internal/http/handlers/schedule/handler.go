@@ -0,0 +1,194 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitoring_backend/internal/domain"
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+type ScheduleService interface {
+	Get(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) (ScheduleResponse, error)
+	SetMode(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, req SetModeRequest) (ScheduleResponse, error)
+	ListAudit(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, page, pageSize int) (ListAuditResponse, error)
+}
+
+type ScheduleHandler struct {
+	service ScheduleService
+}
+
+func NewScheduleHandler(service ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{service: service}
+}
+
+// Get godoc
+// @Summary      Get class monitoring schedule
+// @Description  Режим планировщика для занятия и время, когда мониторинг будет запущен и остановлен автоматически.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind  path  string  true  "Вид занятия"
+// @Param        id    path  int     true  "Class ID"
+// @Success      200  {object}  schedule.ScheduleResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/schedule [get]
+func (h *ScheduleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, role, kind, classID, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.Get(r.Context(), userID, role, kind, classID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// SetMode godoc
+// @Summary      Override class monitoring schedule
+// @Description  Переключает занятие между режимами auto (мониторинг запускает и останавливает планировщик)
+// @Description  и manual (только вручную через /api/classes/{kind}/start и stop). Смена режима пишется в журнал.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind     path  string                   true  "Вид занятия"
+// @Param        id       path  int                      true  "Class ID"
+// @Param        request  body  schedule.SetModeRequest  true  "Режим"
+// @Success      200  {object}  schedule.ScheduleResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/schedule [put]
+func (h *ScheduleHandler) SetMode(w http.ResponseWriter, r *http.Request) {
+	userID, role, kind, classID, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Mode = strings.ToLower(strings.TrimSpace(req.Mode))
+	if !domain.ScheduleMode(req.Mode).Valid() {
+		response.WriteError(w, http.StatusBadRequest, "mode must be auto or manual")
+		return
+	}
+
+	resp, err := h.service.SetMode(r.Context(), userID, role, kind, classID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ListAudit godoc
+// @Summary      Class schedule audit
+// @Description  Журнал расписания занятия (новые сверху): кто и когда менял режим, запускал и останавливал мониторинг вручную
+// @Description  (manual_start, manual_stop) и что планировщик запускал и останавливал сам.
+// @Tags         classes
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind       path   string  true   "Вид занятия"
+// @Param        id         path   int     true   "Class ID"
+// @Param        page       query  int     false  "Страница (по умолчанию 1)"
+// @Param        page_size  query  int     false  "Размер страницы (по умолчанию 50)"
+// @Success      200  {object}  schedule.ListAuditResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/schedule/audit [get]
+func (h *ScheduleHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	userID, role, kind, classID, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := httputil.QueryInt(r, "page", 1)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if page < 1 {
+		page = 1
+	}
+	pageSize, err := httputil.QueryInt(r, "page_size", 50)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+
+	resp, err := h.service.ListAudit(r.Context(), userID, role, kind, classID, page, pageSize)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+func parseRequest(w http.ResponseWriter, r *http.Request) (string, string, domain.ClassKind, int64, bool) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok || userID == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return "", "", "", 0, false
+	}
+	role, _ := middleware.Role(r.Context())
+
+	vars := mux.Vars(r)
+	kind := domain.ClassKind(strings.ToLower(strings.TrimSpace(vars["kind"])))
+	if !kind.Valid() {
+		response.WriteError(w, http.StatusNotFound, "unknown class kind")
+		return "", "", "", 0, false
+	}
+
+	classID, err := httputil.PathInt64(r, "id", vars)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return "", "", "", 0, false
+	}
+
+	return userID, role, kind, classID, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrClassAccessDenied) {
+		response.WriteError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	httputil.WriteServiceError(w, err)
+}
@@ -0,0 +1,43 @@
+package sighting_review
+
+import "time"
+
+// SightingItem снапшот с similarity между порогами; id — id записи на проверке.
+type SightingItem struct {
+	ID         int64      `json:"id"`
+	ISU        string     `json:"isu"`
+	FirstName  string     `json:"first_name"`
+	LastName   string     `json:"last_name"`
+	Patronymic *string    `json:"patronymic,omitempty"`
+	Group      *string    `json:"group,omitempty"`
+	CapturedAt time.Time  `json:"captured_at"`
+	CameraID   *string    `json:"camera_id,omitempty"`
+	Confidence *float64   `json:"confidence,omitempty"`
+	Status     string     `json:"status"`
+	ReviewedBy *string    `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+type PageMeta struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Total    int `json:"total"`
+}
+
+type ListSightingsResponse struct {
+	Kind    string         `json:"kind"`
+	ClassID int64          `json:"class_id"`
+	Items   []SightingItem `json:"items"`
+	Meta    PageMeta       `json:"meta"`
+}
+
+// ReviewRequest id снапшотов занятия на проверке.
+type ReviewRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// ReviewResponse снапшоты, которые действительно были на проверке и сменили статус;
+// уже проверенные и чужие id пропускаются.
+type ReviewResponse struct {
+	Items []SightingItem `json:"items"`
+}
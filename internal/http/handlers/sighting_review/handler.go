@@ -0,0 +1,210 @@
+package sighting_review
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitoring_backend/internal/domain"
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+type SightingReviewService interface {
+	List(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, status *domain.SightingStatus, page, pageSize int) (ListSightingsResponse, error)
+	Review(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, ids []int64, status domain.SightingStatus) (ReviewResponse, error)
+}
+
+type SightingReviewHandler struct {
+	service SightingReviewService
+}
+
+func NewSightingReviewHandler(service SightingReviewService) *SightingReviewHandler {
+	return &SightingReviewHandler{service: service}
+}
+
+// maxReviewIDs ограничение на число снапшотов в одном запросе подтверждения или отклонения.
+const maxReviewIDs = 500
+
+// List godoc
+// @Summary      List sightings awaiting review
+// @Description  Снапшоты занятия с similarity между порогами департамента: в присутствии они не учитываются,
+// @Description  пока преподаватель их не подтвердит. По умолчанию — только ожидающие проверки.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind       path   string  true   "Вид занятия"
+// @Param        id         path   int     true   "Class ID"
+// @Param        status     query  string  false  "pending (по умолчанию), confirmed, rejected или all"
+// @Param        page       query  int     false  "Страница (по умолчанию 1)"
+// @Param        page_size  query  int     false  "Размер страницы (по умолчанию 50)"
+// @Success      200  {object}  sighting_review.ListSightingsResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/reviews [get]
+func (h *SightingReviewHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, role, kind, classID, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var status *domain.SightingStatus
+	switch raw := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))); raw {
+	case "all":
+	case "":
+		pending := domain.SightingPending
+		status = &pending
+	default:
+		s := domain.SightingStatus(raw)
+		if !s.Valid() {
+			response.WriteError(w, http.StatusBadRequest, "status must be pending, confirmed, rejected or all")
+			return
+		}
+		status = &s
+	}
+
+	page, err := httputil.QueryInt(r, "page", 1)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if page < 1 {
+		page = 1
+	}
+	pageSize, err := httputil.QueryInt(r, "page_size", 50)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+
+	resp, err := h.service.List(r.Context(), userID, role, kind, classID, status, page, pageSize)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// Confirm godoc
+// @Summary      Confirm sightings
+// @Description  Подтверждает снапшоты на проверке: они становятся обычными снапшотами занятия и учитываются в присутствии.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind     path  string                         true  "Вид занятия"
+// @Param        id       path  int                            true  "Class ID"
+// @Param        request  body  sighting_review.ReviewRequest  true  "id снапшотов"
+// @Success      200  {object}  sighting_review.ReviewResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/reviews/confirm [post]
+func (h *SightingReviewHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, domain.SightingConfirmed)
+}
+
+// Reject godoc
+// @Summary      Reject sightings
+// @Description  Отклоняет снапшоты на проверке: в присутствии они не учитываются, запись остаётся для истории.
+// @Description  Доступ: администратор или преподаватель занятия.
+// @Tags         classes
+// @Accept       json
+// @Produce      json
+// @Param Authorization header string true "Bearer <JWT>"
+// @Param        kind     path  string                         true  "Вид занятия"
+// @Param        id       path  int                            true  "Class ID"
+// @Param        request  body  sighting_review.ReviewRequest  true  "id снапшотов"
+// @Success      200  {object}  sighting_review.ReviewResponse
+// @Failure      400  {object}  response.ErrorResponse
+// @Failure      401  {object}  response.ErrorResponse
+// @Failure      403  {object}  response.ErrorResponse
+// @Failure      404  {object}  response.ErrorResponse
+// @Failure      500  {object}  response.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/classes/{kind}/{id}/reviews/reject [post]
+func (h *SightingReviewHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, domain.SightingRejected)
+}
+
+func (h *SightingReviewHandler) review(w http.ResponseWriter, r *http.Request, status domain.SightingStatus) {
+	userID, role, kind, classID, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.IDs) == 0 {
+		response.WriteError(w, http.StatusBadRequest, "ids are required")
+		return
+	}
+	if len(req.IDs) > maxReviewIDs {
+		response.WriteError(w, http.StatusBadRequest, "too many ids")
+		return
+	}
+
+	resp, err := h.service.Review(r.Context(), userID, role, kind, classID, req.IDs, status)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+func parseRequest(w http.ResponseWriter, r *http.Request) (string, string, domain.ClassKind, int64, bool) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok || userID == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return "", "", "", 0, false
+	}
+	role, _ := middleware.Role(r.Context())
+
+	vars := mux.Vars(r)
+	kind := domain.ClassKind(strings.ToLower(strings.TrimSpace(vars["kind"])))
+	if !kind.Valid() {
+		response.WriteError(w, http.StatusNotFound, "unknown class kind")
+		return "", "", "", 0, false
+	}
+
+	classID, err := httputil.PathInt64(r, "id", vars)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return "", "", "", 0, false
+	}
+
+	return userID, role, kind, classID, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrClassAccessDenied) {
+		response.WriteError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	httputil.WriteServiceError(w, err)
+}
@@ -57,17 +57,50 @@ func QueryTimeRFC3339(r *http.Request, key string) (time.Time, error) {
 	return t, nil
 }
 
+// ClassEnd конец занятия из ends_at или duration_minutes (задаётся не больше одного).
+// nil — занятие длится стандартное время.
+func ClassEnd(date time.Time, endsAt *time.Time, durationMinutes int) (*time.Time, error) {
+	if endsAt != nil && durationMinutes != 0 {
+		return nil, fmt.Errorf("ends_at and duration_minutes are mutually exclusive")
+	}
+	if durationMinutes < 0 {
+		return nil, fmt.Errorf("duration_minutes must be positive")
+	}
+	if durationMinutes > 0 {
+		end := date.Add(time.Duration(durationMinutes) * time.Minute)
+		return &end, nil
+	}
+	if endsAt != nil && !endsAt.After(date) {
+		return nil, fmt.Errorf("ends_at must be after date")
+	}
+	return endsAt, nil
+}
+
 func WriteServiceError(w http.ResponseWriter, err error) {
 	// 404
 	if errors.Is(err, pgx.ErrNoRows) ||
 		errors.Is(err, domain.ErrorDepartmentNotFound) ||
 		errors.Is(err, domain.ErrorDepartmentsNotFound) ||
 		errors.Is(err, domain.ErrGroupNotFound) ||
-		errors.Is(err, domain.ErrGroupsNotFound) {
+		errors.Is(err, domain.ErrGroupsNotFound) ||
+		errors.Is(err, domain.ErrDeadLetterNotFound) ||
+		errors.Is(err, domain.ErrAttendancePolicyNotFound) ||
+		errors.Is(err, domain.ErrRecognitionThresholdNotFound) ||
+		errors.Is(err, domain.ErrClassNotFound) ||
+		errors.Is(err, domain.ErrRoomNotFound) ||
+		errors.Is(err, domain.ErrCameraNotFound) ||
+		errors.Is(err, domain.ErrAttendanceOverrideNotFound) ||
+		errors.Is(err, domain.ErrStudentNotFound) {
 		response.WriteError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
+	// 409
+	if errors.Is(err, domain.ErrRoomInUse) {
+		response.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+
 	// 409 (unique_violation)
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -0,0 +1,404 @@
+package visits
+
+import (
+	"monitoring_backend/internal/domain"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ClassVisitsHandler посещаемость занятий любого вида; вид берётся из пути /api/visits/classes/{kind}/...
+type ClassVisitsHandler struct {
+	services map[domain.ClassKind]visitsService
+}
+
+func NewClassVisitsHandler() *ClassVisitsHandler {
+	return &ClassVisitsHandler{services: make(map[domain.ClassKind]visitsService)}
+}
+
+// Register подключает сервис посещаемости занятий вида kind. Вызывается при сборке приложения,
+// до того как обработчик начнёт принимать запросы.
+func (h *ClassVisitsHandler) Register(kind domain.ClassKind, service visitsService) {
+	h.services[kind] = service
+}
+
+// GetVisitedSubjects godoc
+// @Summary      Предметы, по которым студент посещал занятия вида kind
+// @Description  Возвращает уникальный список предметов, по которым у текущего пользователя (ISU из JWT) есть снапшоты на занятиях указанного вида.
+// @Tags         visits
+// @Produce      json
+// @Param        kind path string true "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Success      200 {object} visits.GetVisitedSubjectsResponse
+// @Failure      400 {object} response.ErrorResponse "Bad request"
+// @Failure      404 {object} response.ErrorResponse "Unknown class kind"
+// @Failure      500 {object} response.ErrorResponse "Internal error"
+// @Security     BearerAuth
+// @Router       /api/visits/classes/{kind}/subjects [get]
+func (h *ClassVisitsHandler) GetVisitedSubjects(w http.ResponseWriter, r *http.Request) {
+	_, service, ok := h.fromPath(w, r)
+	if !ok {
+		return
+	}
+
+	isu, ok := middleware.UserID(r.Context())
+	if isu == "" || !ok {
+		response.WriteError(w, http.StatusBadRequest, "isu is required")
+		return
+	}
+
+	subjects, err := service.GetVisitedSubjectsByISU(r.Context(), isu)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := make([]SubjectDTO, 0, len(subjects))
+	for _, s := range subjects {
+		out = append(out, SubjectDTO{ID: s.ID, Name: s.Name})
+	}
+
+	response.WriteJSON(w, http.StatusOK, GetVisitedSubjectsResponse{
+		ISU:      isu,
+		Subjects: out,
+	})
+}
+
+// GetStudentClassesBySubject godoc
+// @Summary      Занятия студента по предмету
+// @Description  Возвращает занятия указанного вида по предмету и время присутствия студента на каждом (секунды). ISU берётся из JWT.
+// @Tags         visits
+// @Produce      json
+// @Param        kind       path string true "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Param        subject_id path int true "ID предмета"
+// @Param        date_from  query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param        date_to    query string false "Конец периода (RFC3339 или YYYY-MM-DD)"
+// @Param        order      query string false "Сортировка по дате: asc или desc (по умолчанию desc)"
+// @Param        page       query int false "Страница (по умолчанию 1)"
+// @Param        page_size  query int false "Размер страницы (по умолчанию 20)"
+// @Param        gap_seconds query int false "Максимальный разрыв между снапшотами для склейки (сек), по умолчанию 120"
+// @Success      200 {object} visits.GetStudentClassesBySubjectResponse
+// @Failure      401 {object} response.ErrorResponse "Unauthorized"
+// @Failure      400 {object} response.ErrorResponse "Bad request"
+// @Failure      404 {object} response.ErrorResponse "Unknown class kind"
+// @Failure      500 {object} response.ErrorResponse "Internal error"
+// @Security     BearerAuth
+// @Router       /api/visits/classes/{kind}/{subject_id} [get]
+func (h *ClassVisitsHandler) GetStudentClassesBySubject(w http.ResponseWriter, r *http.Request) {
+	kind, service, ok := h.fromPath(w, r)
+	if !ok {
+		return
+	}
+
+	isu, ok := middleware.UserID(r.Context())
+	if !ok || strings.TrimSpace(isu) == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	role, ok := middleware.Role(r.Context())
+	if !ok || role != domain.RoleStudent {
+		response.WriteError(w, http.StatusUnauthorized, "Access denied")
+		return
+	}
+
+	subjectID, err := parseIDPath(mux.Vars(r), "subject_id")
+	if err != nil || subjectID <= 0 {
+		response.WriteError(w, http.StatusBadRequest, "invalid subject_id")
+		return
+	}
+
+	filter, err := parseLecturesFilter(r)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, total, err := service.GetStudentClassesBySubject(r.Context(), isu, subjectID, filter)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := make([]ClassAttendanceItem, 0, len(items))
+	for _, it := range items {
+		out = append(out, ClassAttendanceItem{
+			ClassID:        it.ClassID,
+			Date:           it.Date.UTC().Format(time.RFC3339),
+			TeacherISU:     it.TeacherISU,
+			PresentSeconds: it.PresentSeconds,
+			AttendanceDTO:  toAttendanceDTO(it.Attendance),
+		})
+	}
+
+	response.WriteJSON(w, http.StatusOK, GetStudentClassesBySubjectResponse{
+		Kind:      string(kind),
+		SubjectID: subjectID,
+		ISU:       isu,
+		Items:     out,
+		Meta: PageMeta{
+			Page:     filter.Page,
+			PageSize: filter.PageSize,
+			Total:    total,
+		},
+	})
+}
+
+// GetTeacherClassesBySubject godoc
+// @Summary      Занятия преподавателя по предмету
+// @Description  Возвращает занятия указанного вида по предмету для текущего преподавателя (ISU из JWT). Период опционально. Есть пагинация.
+// @Tags         visits
+// @Produce      json
+// @Param        kind       path string true "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Param        subject_id path int true "ID предмета"
+// @Param        date_from  query string false "Начало периода (RFC3339 или YYYY-MM-DD)"
+// @Param        date_to    query string false "Конец периода (RFC3339 или YYYY-MM-DD)"
+// @Param        order      query string false "Сортировка по дате: asc или desc (по умолчанию desc)"
+// @Param        page       query int false "Страница (по умолчанию 1)"
+// @Param        page_size  query int false "Размер страницы (по умолчанию 20)"
+// @Success      200 {object} visits.GetTeacherClassesResponse
+// @Failure      401 {object} response.ErrorResponse "Unauthorized"
+// @Failure      400 {object} response.ErrorResponse "Bad request"
+// @Failure      404 {object} response.ErrorResponse "Unknown class kind"
+// @Failure      500 {object} response.ErrorResponse "Internal error"
+// @Security     BearerAuth
+// @Router       /api/visits/classes/{kind}/teacher/{subject_id} [get]
+func (h *ClassVisitsHandler) GetTeacherClassesBySubject(w http.ResponseWriter, r *http.Request) {
+	kind, service, ok := h.fromPath(w, r)
+	if !ok {
+		return
+	}
+
+	teacherISU, ok := middleware.UserID(r.Context())
+	if !ok || strings.TrimSpace(teacherISU) == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	subjectID, err := parseIDPath(mux.Vars(r), "subject_id")
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid subject_id")
+		return
+	}
+
+	filter, err := parseTeacherLecturesFilter(r)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, total, err := service.GetTeacherClassesBySubject(r.Context(), teacherISU, subjectID, filter)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := make([]TeacherClassItem, 0, len(items))
+	for _, it := range items {
+		out = append(out, TeacherClassItem{
+			ClassID: it.ClassID,
+			Date:    it.Date.UTC().Format(time.RFC3339),
+		})
+	}
+
+	response.WriteJSON(w, http.StatusOK, GetTeacherClassesResponse{
+		Kind:       string(kind),
+		SubjectID:  subjectID,
+		TeacherISU: teacherISU,
+		Items:      out,
+		Meta: PageMeta{
+			Page:     filter.Page,
+			PageSize: filter.PageSize,
+			Total:    total,
+		},
+	})
+}
+
+// GetClassGroups godoc
+// @Summary      Группы на занятии
+// @Description  Возвращает список групп, привязанных к занятию. Доступ только преподавателю занятия (ISU из JWT).
+// @Tags         visits
+// @Produce      json
+// @Param        kind     path string true "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Param        class_id path int true "ID занятия"
+// @Success      200 {object} visits.GetClassGroupsResponse
+// @Failure      401 {object} response.ErrorResponse "Unauthorized"
+// @Failure      400 {object} response.ErrorResponse "Bad request"
+// @Failure      404 {object} response.ErrorResponse "Unknown class kind"
+// @Failure      500 {object} response.ErrorResponse "Internal error"
+// @Security     BearerAuth
+// @Router       /api/visits/classes/{kind}/teacher/class/{class_id}/groups [get]
+func (h *ClassVisitsHandler) GetClassGroups(w http.ResponseWriter, r *http.Request) {
+	kind, service, ok := h.fromPath(w, r)
+	if !ok {
+		return
+	}
+
+	teacherISU, ok := middleware.UserID(r.Context())
+	if !ok || strings.TrimSpace(teacherISU) == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	classID, err := parseIDPath(mux.Vars(r), "class_id")
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid class_id")
+		return
+	}
+
+	groups, err := service.GetClassGroups(r.Context(), teacherISU, classID)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := make([]GroupItem, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, GroupItem{GroupCode: g})
+	}
+
+	response.WriteJSON(w, http.StatusOK, GetClassGroupsResponse{
+		Kind:    string(kind),
+		ClassID: classID,
+		Groups:  out,
+	})
+}
+
+// GetClassGroupStudents godoc
+// @Summary      Студенты группы на занятии и время присутствия
+// @Description  Возвращает студентов выбранной группы на занятии и сколько секунд каждый присутствовал, а также статус посещения по политике предмета или департамента. Есть пагинация.
+// @Tags         visits
+// @Produce      json
+// @Param        kind       path string true "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Param        class_id   path int true "ID занятия"
+// @Param        group_code path string true "Код группы"
+// @Param        page       query int false "Страница (по умолчанию 1)"
+// @Param        page_size  query int false "Размер страницы (по умолчанию 50)"
+// @Param        gap_seconds query int false "Максимальный разрыв между снапшотами для склейки (сек), по умолчанию 120"
+// @Success      200 {object} visits.GetClassGroupStudentsResponse
+// @Failure      401 {object} response.ErrorResponse "Unauthorized"
+// @Failure      400 {object} response.ErrorResponse "Bad request"
+// @Failure      404 {object} response.ErrorResponse "Unknown class kind"
+// @Failure      500 {object} response.ErrorResponse "Internal error"
+// @Security     BearerAuth
+// @Router       /api/visits/classes/{kind}/teacher/class/{class_id}/{group_code}/students [get]
+func (h *ClassVisitsHandler) GetClassGroupStudents(w http.ResponseWriter, r *http.Request) {
+	kind, service, ok := h.fromPath(w, r)
+	if !ok {
+		return
+	}
+
+	teacherISU, ok := middleware.UserID(r.Context())
+	if !ok || strings.TrimSpace(teacherISU) == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	classID, err := parseIDPath(vars, "class_id")
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid class_id")
+		return
+	}
+
+	groupCode := strings.TrimSpace(vars["group_code"])
+	if groupCode == "" {
+		response.WriteError(w, http.StatusBadRequest, "invalid group_code")
+		return
+	}
+
+	page := intFromQuery(r.URL.Query().Get("page"), 1)
+	if page < 1 {
+		page = 1
+	}
+	pageSize := intFromQuery(r.URL.Query().Get("page_size"), 50)
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+	gapSeconds := intFromQuery(r.URL.Query().Get("gap_seconds"), 120)
+	if gapSeconds < 1 {
+		gapSeconds = 120
+	}
+
+	items, total, err := service.GetClassGroupStudents(r.Context(), teacherISU, classID, groupCode, page, pageSize, gapSeconds)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := make([]StudentOnLectureItem, 0, len(items))
+	for _, it := range items {
+		out = append(out, StudentOnLectureItem{
+			ISU:            it.ISU,
+			FirstName:      it.FirstName,
+			LastName:       it.LastName,
+			Patronymic:     it.Patronymic,
+			PresentSeconds: it.PresentSeconds,
+			AttendanceDTO:  toAttendanceDTO(it.Attendance),
+		})
+	}
+
+	response.WriteJSON(w, http.StatusOK, GetClassGroupStudentsResponse{
+		Kind:      string(kind),
+		ClassID:   classID,
+		GroupCode: groupCode,
+		Items:     out,
+		Meta: PageMeta{
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+		},
+	})
+}
+
+// GetTeacherSubjects godoc
+// @Summary      Предметы преподавателя с занятиями вида kind
+// @Description  Возвращает список предметов, по которым у текущего преподавателя есть занятия указанного вида. ISU берётся из JWT.
+// @Tags         visits
+// @Produce      json
+// @Param        kind path string true "Вид занятия: lecture, practice, lab, exam, consultation"
+// @Success      200 {object} visits.GetTeacherSubjectsResponse
+// @Failure      401 {object} response.ErrorResponse "Unauthorized"
+// @Failure      404 {object} response.ErrorResponse "Unknown class kind"
+// @Failure      500 {object} response.ErrorResponse "Internal error"
+// @Security     BearerAuth
+// @Router       /api/visits/classes/{kind}/teacher/subjects [get]
+func (h *ClassVisitsHandler) GetTeacherSubjects(w http.ResponseWriter, r *http.Request) {
+	_, service, ok := h.fromPath(w, r)
+	if !ok {
+		return
+	}
+
+	teacherISU, ok := middleware.UserID(r.Context())
+	if !ok || strings.TrimSpace(teacherISU) == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	subjects, err := service.GetTeacherSubjects(r.Context(), teacherISU)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, GetTeacherSubjectsResponse{
+		TeacherISU: teacherISU,
+		Subjects:   subjects,
+	})
+}
+
+func (h *ClassVisitsHandler) fromPath(w http.ResponseWriter, r *http.Request) (domain.ClassKind, visitsService, bool) {
+	kind := domain.ClassKind(strings.ToLower(strings.TrimSpace(mux.Vars(r)["kind"])))
+	service, ok := h.services[kind]
+	if !ok {
+		response.WriteError(w, http.StatusNotFound, "unknown class kind")
+		return "", nil, false
+	}
+	return kind, service, true
+}
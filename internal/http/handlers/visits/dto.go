@@ -16,11 +16,34 @@ type PageMeta struct {
 	Total    int `json:"total"`
 }
 
+// AttendanceDTO статус посещения занятия студентом. first_seen/last_seen нет, если студента не видели.
+// Статус: present, late, left_early или absent — по порогам предмета или департамента.
+// Если преподаватель отметил студента вручную, status — его отметка (present, absent или excused),
+// а посчитанный по снапшотам статус — в computed_status.
+type AttendanceDTO struct {
+	FirstSeen          string                 `json:"first_seen,omitempty"` // RFC3339
+	LastSeen           string                 `json:"last_seen,omitempty"`  // RFC3339
+	LateBySeconds      int64                  `json:"late_by_seconds"`
+	LeftEarlyBySeconds int64                  `json:"left_early_by_seconds"`
+	CoveragePercent    float64                `json:"coverage_percent"`
+	Status             string                 `json:"status"`
+	ComputedStatus     string                 `json:"computed_status,omitempty"`
+	Override           *AttendanceOverrideDTO `json:"override,omitempty"`
+}
+
+// AttendanceOverrideDTO ручная отметка преподавателя.
+type AttendanceOverrideDTO struct {
+	Reason    string  `json:"reason"`
+	UpdatedAt string  `json:"updated_at"` // RFC3339
+	UpdatedBy *string `json:"updated_by,omitempty"`
+}
+
 type LectureAttendanceItem struct {
 	LectureID      int64  `json:"lecture_id"`
 	Date           string `json:"date"` // RFC3339
 	TeacherISU     string `json:"teacher_isu"`
 	PresentSeconds int64  `json:"present_seconds"`
+	AttendanceDTO
 }
 
 type GetStudentLecturesBySubjectResponse struct {
@@ -57,6 +80,7 @@ type StudentOnLectureItem struct {
 	LastName       string  `json:"last_name"`
 	Patronymic     *string `json:"patronymic,omitempty"`
 	PresentSeconds int64   `json:"present_seconds"`
+	AttendanceDTO
 }
 
 type GetLectureGroupStudentsResponse struct {
@@ -70,3 +94,70 @@ type GetTeacherSubjectsResponse struct {
 	TeacherISU string       `json:"teacher_isu"`
 	Subjects   []SubjectDTO `json:"subjects"`
 }
+
+type ClassAttendanceItem struct {
+	ClassID        int64  `json:"class_id"`
+	Date           string `json:"date"` // RFC3339
+	TeacherISU     string `json:"teacher_isu"`
+	PresentSeconds int64  `json:"present_seconds"`
+	AttendanceDTO
+}
+
+type GetStudentClassesBySubjectResponse struct {
+	Kind      string                `json:"kind"`
+	SubjectID int64                 `json:"subject_id"`
+	ISU       string                `json:"isu"`
+	Items     []ClassAttendanceItem `json:"items"`
+	Meta      PageMeta              `json:"meta"`
+}
+
+type TeacherClassItem struct {
+	ClassID int64  `json:"class_id"`
+	Date    string `json:"date"` // RFC3339
+}
+
+type GetTeacherClassesResponse struct {
+	Kind       string             `json:"kind"`
+	SubjectID  int64              `json:"subject_id"`
+	TeacherISU string             `json:"teacher_isu"`
+	Items      []TeacherClassItem `json:"items"`
+	Meta       PageMeta           `json:"meta"`
+}
+
+type GetClassGroupsResponse struct {
+	Kind    string      `json:"kind"`
+	ClassID int64       `json:"class_id"`
+	Groups  []GroupItem `json:"groups"`
+}
+
+type GetClassGroupStudentsResponse struct {
+	Kind      string                 `json:"kind"`
+	ClassID   int64                  `json:"class_id"`
+	GroupCode string                 `json:"group_code"`
+	Items     []StudentOnLectureItem `json:"items"`
+	Meta      PageMeta               `json:"meta"`
+}
+
+// VisitSnapshotItem сырой снапшот распознавания; время с наносекундами, как записано в БД.
+type VisitSnapshotItem struct {
+	ID         int64    `json:"id"`
+	LectureID  int64    `json:"lecture_id"`
+	UserID     string   `json:"user_id"`
+	CapturedAt string   `json:"captured_at"` // RFC3339Nano
+	ReceivedAt string   `json:"received_at"` // RFC3339Nano
+	CameraID   *string  `json:"camera_id,omitempty"`
+	Confidence *float64 `json:"confidence,omitempty"`
+}
+
+type GetLectureVisitsResponse struct {
+	LectureID int64               `json:"lecture_id"`
+	Items     []VisitSnapshotItem `json:"items"`
+	Meta      PageMeta            `json:"meta"`
+}
+
+type GetStudentVisitsResponse struct {
+	ISU   string              `json:"isu"`
+	From  string              `json:"from"` // RFC3339
+	To    string              `json:"to"`   // RFC3339
+	Items []VisitSnapshotItem `json:"items"`
+}
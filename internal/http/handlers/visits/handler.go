@@ -2,6 +2,7 @@ package visits
 
 import (
 	"context"
+	"math"
 	"monitoring_backend/internal/domain"
 	"monitoring_backend/internal/http/middleware"
 	"monitoring_backend/internal/http/response"
@@ -22,31 +23,39 @@ type GetLecturesFilter struct {
 	GapSeconds int // для склейки снапшотов
 }
 
-type LectureAttendance struct {
-	LectureID      int64
+// ClassAttendance присутствие студента на одном занятии.
+type ClassAttendance struct {
+	ClassID        int64
 	Date           time.Time
+	EndsAt         time.Time
 	TeacherISU     string
 	PresentSeconds int64
+	FirstSeen      *time.Time
+	LastSeen       *time.Time
+	// Attendance статус по политике предмета или департамента, считает сервис.
+	Attendance domain.Attendance
 }
 
+// visitsService посещаемость занятий одного вида: у VisitsHandler — лекций,
+// у ClassVisitsHandler — по сервису на каждый вид.
 type visitsService interface {
 	GetVisitedSubjectsByISU(ctx context.Context, isu string) ([]domain.Subject, error)
-	GetStudentLecturesBySubject(ctx context.Context, isu string, subjectID int64, filter GetLecturesFilter) (items []LectureAttendance, total int, err error)
+	GetStudentClassesBySubject(ctx context.Context, isu string, subjectID int64, filter GetLecturesFilter) (items []ClassAttendance, total int, err error)
 
-	GetTeacherLecturesBySubject(
+	GetTeacherClassesBySubject(
 		ctx context.Context,
 		teacherISU string,
 		subjectID int64,
 		filter TeacherLecturesFilter,
-	) (items []TeacherLecture, total int, err error)
+	) (items []TeacherClass, total int, err error)
 
-	GetLectureGroups(
+	GetClassGroups(
 		ctx context.Context,
 		teacherISU string,
 		lectureID int64,
 	) ([]string, error)
 
-	GetLectureGroupStudents(
+	GetClassGroupStudents(
 		ctx context.Context,
 		teacherISU string,
 		lectureID int64,
@@ -57,6 +66,10 @@ type visitsService interface {
 	) (items []StudentOnLecture, total int, err error)
 
 	GetTeacherSubjects(ctx context.Context, teacherISU string) ([]SubjectDTO, error)
+
+	// GetClassVisits сырые снапшоты занятия, GetUserVisits — снапшоты студента за период.
+	GetClassVisits(ctx context.Context, classID int64, filter domain.ClassVisitFilter) (items []domain.ClassVisit, total int, err error)
+	GetUserVisits(ctx context.Context, isu string, from, to time.Time) ([]domain.ClassVisit, error)
 }
 
 type VisitsHandler struct {
@@ -69,7 +82,7 @@ func NewVisitsHandler(visitsService visitsService) *VisitsHandler {
 
 // GetVisitedSubjects godoc
 // @Summary      Получить предметы, по которым студент посещал лекции
-// @Description  Возвращает уникальный список предметов (subjects), по которым есть записи в visits.lectures_visiting для указанного isu.
+// @Description  Возвращает уникальный список предметов (subjects), по которым есть записи в visits.classes_visiting по лекциям для указанного isu.
 // @Tags         visits
 // @Accept       json
 // @Produce      json
@@ -110,7 +123,7 @@ func (h *VisitsHandler) GetVisitedSubjects(w http.ResponseWriter, r *http.Reques
 
 // GetStudentLecturesBySubject godoc
 // @Summary      Лекции студента по предмету
-// @Description  Возвращает лекции по предмету (сортировка по дате) и время присутствия студента на каждой лекции (секунды). ISU берётся из JWT.
+// @Description  Возвращает лекции по предмету (сортировка по дате) и время присутствия студента на каждой лекции (секунды) со статусом посещения. ISU берётся из JWT.
 // @Tags         visits
 // @Accept       json
 // @Produce      json
@@ -153,7 +166,7 @@ func (h *VisitsHandler) GetStudentLecturesBySubject(w http.ResponseWriter, r *ht
 		return
 	}
 
-	items, total, err := h.visitsService.GetStudentLecturesBySubject(r.Context(), isu, subjectID, filter)
+	items, total, err := h.visitsService.GetStudentClassesBySubject(r.Context(), isu, subjectID, filter)
 	if err != nil {
 		response.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -162,10 +175,11 @@ func (h *VisitsHandler) GetStudentLecturesBySubject(w http.ResponseWriter, r *ht
 	out := make([]LectureAttendanceItem, 0, len(items))
 	for _, it := range items {
 		out = append(out, LectureAttendanceItem{
-			LectureID:      it.LectureID,
+			LectureID:      it.ClassID,
 			Date:           it.Date.UTC().Format(time.RFC3339),
 			TeacherISU:     it.TeacherISU,
 			PresentSeconds: it.PresentSeconds,
+			AttendanceDTO:  toAttendanceDTO(it.Attendance),
 		})
 	}
 
@@ -272,9 +286,9 @@ type TeacherLecturesFilter struct {
 	PageSize int
 }
 
-type TeacherLecture struct {
-	LectureID int64
-	Date      time.Time
+type TeacherClass struct {
+	ClassID int64
+	Date    time.Time
 }
 
 type StudentOnLecture struct {
@@ -283,6 +297,10 @@ type StudentOnLecture struct {
 	LastName       string
 	Patronymic     *string
 	PresentSeconds int64
+	FirstSeen      *time.Time
+	LastSeen       *time.Time
+	// Attendance статус по политике предмета или департамента, считает сервис.
+	Attendance domain.Attendance
 }
 
 // GetTeacherLecturesBySubject godoc
@@ -322,7 +340,7 @@ func (h *VisitsHandler) GetTeacherLecturesBySubject(w http.ResponseWriter, r *ht
 		return
 	}
 
-	items, total, err := h.visitsService.GetTeacherLecturesBySubject(r.Context(), teacherISU, subjectID, filter)
+	items, total, err := h.visitsService.GetTeacherClassesBySubject(r.Context(), teacherISU, subjectID, filter)
 	if err != nil {
 		response.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -331,7 +349,7 @@ func (h *VisitsHandler) GetTeacherLecturesBySubject(w http.ResponseWriter, r *ht
 	out := make([]TeacherLectureItem, 0, len(items))
 	for _, it := range items {
 		out = append(out, TeacherLectureItem{
-			LectureID: it.LectureID,
+			LectureID: it.ClassID,
 			Date:      it.Date.UTC().Format(time.RFC3339),
 		})
 	}
@@ -376,7 +394,7 @@ func (h *VisitsHandler) GetLectureGroups(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	groups, err := h.visitsService.GetLectureGroups(r.Context(), teacherISU, lectureID)
+	groups, err := h.visitsService.GetClassGroups(r.Context(), teacherISU, lectureID)
 	if err != nil {
 		response.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -396,7 +414,7 @@ func (h *VisitsHandler) GetLectureGroups(w http.ResponseWriter, r *http.Request)
 
 // GetLectureGroupStudents godoc
 // @Summary      Студенты группы на лекции и время присутствия
-// @Description  Возвращает студентов выбранной группы на выбранной лекции и сколько секунд каждый присутствовал. Пагинация есть, фильтров/сортировок нет.
+// @Description  Возвращает студентов выбранной группы на выбранной лекции и сколько секунд каждый присутствовал, а также статус посещения по политике предмета или департамента. Пагинация есть, фильтров/сортировок нет.
 // @Tags         visits
 // @Accept       json
 // @Produce      json
@@ -447,7 +465,7 @@ func (h *VisitsHandler) GetLectureGroupStudents(w http.ResponseWriter, r *http.R
 		gapSeconds = 120
 	}
 
-	items, total, err := h.visitsService.GetLectureGroupStudents(r.Context(), teacherISU, lectureID, groupCode, page, pageSize, gapSeconds)
+	items, total, err := h.visitsService.GetClassGroupStudents(r.Context(), teacherISU, lectureID, groupCode, page, pageSize, gapSeconds)
 	if err != nil {
 		response.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -461,6 +479,7 @@ func (h *VisitsHandler) GetLectureGroupStudents(w http.ResponseWriter, r *http.R
 			LastName:       it.LastName,
 			Patronymic:     it.Patronymic,
 			PresentSeconds: it.PresentSeconds,
+			AttendanceDTO:  toAttendanceDTO(it.Attendance),
 		})
 	}
 
@@ -563,6 +582,30 @@ func (h *VisitsHandler) GetTeacherSubjects(w http.ResponseWriter, r *http.Reques
 	response.WriteJSON(w, http.StatusOK, resp)
 }
 
+func toAttendanceDTO(a domain.Attendance) AttendanceDTO {
+	out := AttendanceDTO{
+		LateBySeconds:      int64(a.LateBy / time.Second),
+		LeftEarlyBySeconds: int64(a.LeftEarlyBy / time.Second),
+		CoveragePercent:    math.Round(a.Coverage*10) / 10,
+		Status:             string(a.Status),
+		ComputedStatus:     string(a.Computed),
+	}
+	if a.Override != nil {
+		out.Override = &AttendanceOverrideDTO{
+			Reason:    a.Override.Reason,
+			UpdatedAt: a.Override.UpdatedAt.UTC().Format(time.RFC3339),
+			UpdatedBy: a.Override.UpdatedBy,
+		}
+	}
+	if a.FirstSeen != nil {
+		out.FirstSeen = a.FirstSeen.UTC().Format(time.RFC3339)
+	}
+	if a.LastSeen != nil {
+		out.LastSeen = a.LastSeen.UTC().Format(time.RFC3339)
+	}
+	return out
+}
+
 func parseIDPath(vars map[string]string, key string) (int64, error) {
 	s := strings.TrimSpace(vars[key])
 	return strconv.ParseInt(s, 10, 64)
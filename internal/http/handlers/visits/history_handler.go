@@ -0,0 +1,230 @@
+package visits
+
+import (
+	"context"
+	"errors"
+	"monitoring_backend/internal/domain"
+	httputil "monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// maxStudentVisitsRange максимальный период выборки снапшотов студента за один запрос.
+const maxStudentVisitsRange = 31 * 24 * time.Hour
+
+// classAuthorizer проверяет доступ к данным занятия (см. service.ClassAccessService).
+type classAuthorizer interface {
+	AuthorizeClass(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) error
+}
+
+// VisitHistoryHandler сырые снапшоты лекций — для разбора качества распознавания и аудита.
+type VisitHistoryHandler struct {
+	visitsService visitsService
+	access        classAuthorizer
+}
+
+func NewVisitHistoryHandler(visitsService visitsService, access classAuthorizer) *VisitHistoryHandler {
+	return &VisitHistoryHandler{
+		visitsService: visitsService,
+		access:        access,
+	}
+}
+
+// GetLectureVisits godoc
+// @Summary      Сырые снапшоты лекции
+// @Description  Возвращает снапшоты распознавания лекции по captured_at с пагинацией. Доступ: администратор или преподаватель лекции.
+// @Tags         visits
+// @Produce      json
+// @Param        id        path  int    true  "ID лекции"
+// @Param        from      query string false "Начало периода по captured_at (RFC3339)"
+// @Param        to        query string false "Конец периода по captured_at (RFC3339)"
+// @Param        isu       query string false "Фильтр по студенту"
+// @Param        page      query int    false "Страница (по умолчанию 1)"
+// @Param        page_size query int    false "Размер страницы (по умолчанию 100, максимум 500)"
+// @Success      200 {object} visits.GetLectureVisitsResponse
+// @Failure      400 {object} response.ErrorResponse "Bad request"
+// @Failure      401 {object} response.ErrorResponse "Unauthorized"
+// @Failure      403 {object} response.ErrorResponse "Access denied"
+// @Failure      404 {object} response.ErrorResponse "Lecture not found"
+// @Failure      500 {object} response.ErrorResponse "Internal error"
+// @Security     BearerAuth
+// @Router       /api/lectures/{id}/visits [get]
+func (h *VisitHistoryHandler) GetLectureVisits(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok || strings.TrimSpace(userID) == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	role, _ := middleware.Role(r.Context())
+
+	lectureID, err := parseIDPath(mux.Vars(r), "id")
+	if err != nil || lectureID <= 0 {
+		response.WriteError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	filter, err := parseClassVisitFilter(r)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.access.AuthorizeClass(r.Context(), userID, role, domain.ClassLecture, lectureID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrClassNotFound):
+			response.WriteError(w, http.StatusNotFound, "lecture not found")
+		case errors.Is(err, domain.ErrClassAccessDenied):
+			response.WriteError(w, http.StatusForbidden, "Access denied")
+		default:
+			response.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	items, total, err := h.visitsService.GetClassVisits(r.Context(), lectureID, filter)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, GetLectureVisitsResponse{
+		LectureID: lectureID,
+		Items:     toVisitSnapshotItems(items),
+		Meta: PageMeta{
+			Page:     filter.Page,
+			PageSize: filter.PageSize,
+			Total:    total,
+		},
+	})
+}
+
+// GetStudentVisits godoc
+// @Summary      Сырые снапшоты студента за период
+// @Description  Возвращает снапшоты распознавания студента на лекциях за период (не больше 31 дня). Доступ: администратор или сам студент.
+// @Tags         visits
+// @Produce      json
+// @Param        isu  path  string true "ISU студента"
+// @Param        from query string true "Начало периода по captured_at (RFC3339)"
+// @Param        to   query string true "Конец периода по captured_at (RFC3339)"
+// @Success      200 {object} visits.GetStudentVisitsResponse
+// @Failure      400 {object} response.ErrorResponse "Bad request"
+// @Failure      401 {object} response.ErrorResponse "Unauthorized"
+// @Failure      403 {object} response.ErrorResponse "Access denied"
+// @Failure      500 {object} response.ErrorResponse "Internal error"
+// @Security     BearerAuth
+// @Router       /api/students/{isu}/visits [get]
+func (h *VisitHistoryHandler) GetStudentVisits(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r.Context())
+	if !ok || strings.TrimSpace(userID) == "" {
+		response.WriteError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	isu := strings.TrimSpace(mux.Vars(r)["isu"])
+	if isu == "" {
+		response.WriteError(w, http.StatusBadRequest, "invalid isu")
+		return
+	}
+	if !middleware.IsAdmin(r.Context()) && userID != isu {
+		response.WriteError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	from, err := httputil.QueryTimeRFC3339(r, "from")
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := httputil.QueryTimeRFC3339(r, "to")
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if to.Before(from) {
+		response.WriteError(w, http.StatusBadRequest, "to must be >= from")
+		return
+	}
+	if to.Sub(from) > maxStudentVisitsRange {
+		response.WriteError(w, http.StatusBadRequest, "period must not exceed 31 days")
+		return
+	}
+
+	items, err := h.visitsService.GetUserVisits(r.Context(), isu, from, to)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, GetStudentVisitsResponse{
+		ISU:   isu,
+		From:  from.UTC().Format(time.RFC3339),
+		To:    to.UTC().Format(time.RFC3339),
+		Items: toVisitSnapshotItems(items),
+	})
+}
+
+func parseClassVisitFilter(r *http.Request) (domain.ClassVisitFilter, error) {
+	q := r.URL.Query()
+
+	page := intFromQuery(q.Get("page"), 1)
+	if page < 1 {
+		page = 1
+	}
+	pageSize := intFromQuery(q.Get("page_size"), 100)
+	if pageSize < 1 {
+		pageSize = 100
+	}
+	if pageSize > 500 {
+		pageSize = 500
+	}
+
+	filter := domain.ClassVisitFilter{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	if q.Get("from") != "" {
+		from, err := httputil.QueryTimeRFC3339(r, "from")
+		if err != nil {
+			return domain.ClassVisitFilter{}, err
+		}
+		filter.From = &from
+	}
+	if q.Get("to") != "" {
+		to, err := httputil.QueryTimeRFC3339(r, "to")
+		if err != nil {
+			return domain.ClassVisitFilter{}, err
+		}
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return domain.ClassVisitFilter{}, httpError("to must be >= from")
+	}
+
+	if isu := strings.TrimSpace(q.Get("isu")); isu != "" {
+		filter.UserID = &isu
+	}
+
+	return filter, nil
+}
+
+func toVisitSnapshotItems(visits []domain.ClassVisit) []VisitSnapshotItem {
+	out := make([]VisitSnapshotItem, 0, len(visits))
+	for _, v := range visits {
+		out = append(out, VisitSnapshotItem{
+			ID:         v.ID,
+			LectureID:  v.LectureID,
+			UserID:     v.UserID,
+			CapturedAt: v.CapturedAt.UTC().Format(time.RFC3339Nano),
+			ReceivedAt: v.ReceivedAt.UTC().Format(time.RFC3339Nano),
+			CameraID:   v.CameraID,
+			Confidence: v.Confidence,
+		})
+	}
+	return out
+}
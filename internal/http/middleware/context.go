@@ -1,6 +1,10 @@
 package middleware
 
-import "context"
+import (
+	"context"
+
+	"monitoring_backend/internal/domain"
+)
 
 func UserID(ctx context.Context) (string, bool) {
 	id, ok := ctx.Value(ctxUserID).(string)
@@ -11,3 +15,9 @@ func Role(ctx context.Context) (string, bool) {
 	role, ok := ctx.Value(ctxRole).(string)
 	return role, ok
 }
+
+// IsAdmin проверяет, что запрос пришёл от администратора.
+func IsAdmin(ctx context.Context) bool {
+	role, ok := Role(ctx)
+	return ok && role == domain.RoleAdmin
+}
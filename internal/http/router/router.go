@@ -3,20 +3,28 @@ package router
 import (
 	auth2 "monitoring_backend/internal/auth"
 	"monitoring_backend/internal/http/handlers"
+	"monitoring_backend/internal/http/handlers/attendance_override"
+	"monitoring_backend/internal/http/handlers/attendance_policy"
 	"monitoring_backend/internal/http/handlers/auth"
+	"monitoring_backend/internal/http/handlers/camera"
+	"monitoring_backend/internal/http/handlers/class"
+	"monitoring_backend/internal/http/handlers/class_session"
+	"monitoring_backend/internal/http/handlers/dead_letter"
 	"monitoring_backend/internal/http/handlers/department"
 	"monitoring_backend/internal/http/handlers/group"
 	lecture2 "monitoring_backend/internal/http/handlers/lecture"
-	"monitoring_backend/internal/http/handlers/practice"
+	"monitoring_backend/internal/http/handlers/recognition_threshold"
+	"monitoring_backend/internal/http/handlers/room"
+	"monitoring_backend/internal/http/handlers/schedule"
 	"monitoring_backend/internal/http/handlers/service/dataset"
+	"monitoring_backend/internal/http/handlers/sighting_review"
 	"monitoring_backend/internal/http/handlers/student_group"
 	"monitoring_backend/internal/http/handlers/subject"
 	"monitoring_backend/internal/http/handlers/user"
 	"monitoring_backend/internal/http/handlers/visits"
 	"monitoring_backend/internal/http/middleware"
 	"monitoring_backend/internal/http/response"
-	"monitoring_backend/internal/lecture"
-	"monitoring_backend/internal/ws"
+	"monitoring_backend/internal/ingest"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -33,15 +41,33 @@ type Dependencies struct {
 	StudentGroup  *student_group.StudentGroupHandler
 	Subject       *subject.SubjectHandler
 	Lecture       *lecture2.LectureHandler
-	Practice      *practice.PracticeHandler
+	Class         *class.ClassHandler
+	Room          *room.RoomHandler
+	Camera        *camera.CameraHandler
 	User          *user.UserHandler
 	VisitsHandler *visits.VisitsHandler
 
-	DataSet *dataset.DatasetHandler
-
-	WsHub          *ws.Hub
-	LectureManager *lecture.Manager
-	JWTManager     *auth2.JWTManager
+	ClassVisitsHandler *visits.ClassVisitsHandler
+	VisitHistory       *visits.VisitHistoryHandler
+
+	DataSet     *dataset.DatasetHandler
+	DeadLetters *dead_letter.DeadLetterHandler
+
+	AttendancePolicies    *attendance_policy.AttendancePolicyHandler
+	RecognitionThresholds *recognition_threshold.RecognitionThresholdHandler
+
+	WS     http.HandlerFunc
+	Events http.HandlerFunc
+	Ingest *ingest.Pipeline
+	// ClassSessions запуск и остановка мониторинга занятий любого вида
+	ClassSessions *class_session.ClassSessionHandler
+	// Schedule ручное управление планировщиком мониторинга занятий
+	Schedule *schedule.ScheduleHandler
+	// SightingReviews проверка преподавателем снапшотов с similarity между порогами
+	SightingReviews *sighting_review.SightingReviewHandler
+	// AttendanceOverrides ручные отметки посещаемости преподавателем
+	AttendanceOverrides *attendance_override.AttendanceOverrideHandler
+	JWTManager          *auth2.JWTManager
 }
 
 func New(d Dependencies) *mux.Router {
@@ -56,16 +82,53 @@ func New(d Dependencies) *mux.Router {
 	api := r.PathPrefix("/api").Subrouter()
 
 	api.HandleFunc("/health", d.Health.Health).Methods(http.MethodGet)
-	api.HandleFunc("/ws", ws.Handler(d.WsHub))
+	api.HandleFunc("/ws", d.WS)
+	api.HandleFunc("/lectures/{id:[0-9]+}/events", d.Events).Methods(http.MethodGet)
 
 	// auth
 	authGroup := api.PathPrefix("/auth").Subrouter()
 	authGroup.HandleFunc("/login", d.AuthHandler.Login).Methods(http.MethodPost)
 
-	// lectures
+	// lectures: устаревшие обёртки над /classes/lecture/...
 	lectureGroup := api.PathPrefix("/lecture").Subrouter()
-	lectureGroup.HandleFunc("/start", d.LectureManager.StartLecture).Methods(http.MethodPost)
-	lectureGroup.HandleFunc("/stop", d.LectureManager.StopLecture).Methods(http.MethodPost)
+	lectureGroup.Use(jwtMW)
+	lectureGroup.HandleFunc("/start", d.ClassSessions.StartLecture).Methods(http.MethodPost)
+	lectureGroup.HandleFunc("/stop", d.ClassSessions.StopLecture).Methods(http.MethodPost)
+	lectureGroup.HandleFunc("/sessions", d.ClassSessions.ListLectureSessions).Methods(http.MethodGet)
+
+	// занятия любого вида; CRUD регистрируется до подроутера мониторинга, чтобы не попасть под JWT
+	api.HandleFunc("/classes", d.Class.Create).Methods(http.MethodPost)
+	api.HandleFunc("/classes/{kind}", d.Class.List).Methods(http.MethodGet)
+	api.HandleFunc("/classes/{kind}/{id:[0-9]+}", d.Class.GetByID).Methods(http.MethodGet)
+
+	classGroup := api.PathPrefix("/classes/{kind}").Subrouter()
+	classGroup.Use(jwtMW)
+	classGroup.HandleFunc("/start", d.ClassSessions.Start).Methods(http.MethodPost)
+	classGroup.HandleFunc("/stop", d.ClassSessions.Stop).Methods(http.MethodPost)
+	classGroup.HandleFunc("/sessions", d.ClassSessions.List).Methods(http.MethodGet)
+	classGroup.HandleFunc("/{id:[0-9]+}/room", d.Class.SetRoom).Methods(http.MethodPut)
+	classGroup.HandleFunc("/{id:[0-9]+}/schedule", d.Schedule.Get).Methods(http.MethodGet)
+	classGroup.HandleFunc("/{id:[0-9]+}/schedule", d.Schedule.SetMode).Methods(http.MethodPut)
+	classGroup.HandleFunc("/{id:[0-9]+}/schedule/audit", d.Schedule.ListAudit).Methods(http.MethodGet)
+	classGroup.HandleFunc("/{id:[0-9]+}/reviews", d.SightingReviews.List).Methods(http.MethodGet)
+	classGroup.HandleFunc("/{id:[0-9]+}/reviews/confirm", d.SightingReviews.Confirm).Methods(http.MethodPost)
+	classGroup.HandleFunc("/{id:[0-9]+}/reviews/reject", d.SightingReviews.Reject).Methods(http.MethodPost)
+	classGroup.HandleFunc("/{id:[0-9]+}/attendance", d.AttendanceOverrides.List).Methods(http.MethodGet)
+	classGroup.HandleFunc("/{id:[0-9]+}/attendance/audit", d.AttendanceOverrides.ListAudit).Methods(http.MethodGet)
+	classGroup.HandleFunc("/{id:[0-9]+}/attendance/{isu}", d.AttendanceOverrides.Set).Methods(http.MethodPut)
+	classGroup.HandleFunc("/{id:[0-9]+}/attendance/{isu}", d.AttendanceOverrides.Clear).Methods(http.MethodDelete)
+
+	// аудитории и камеры; менять справочник может только администратор
+	roomGroup := api.PathPrefix("/rooms").Subrouter()
+	roomGroup.Use(jwtMW)
+	roomGroup.HandleFunc("", d.Room.Create).Methods(http.MethodPost)
+	roomGroup.HandleFunc("", d.Room.List).Methods(http.MethodGet)
+	roomGroup.HandleFunc("/{code}", d.Room.GetByCode).Methods(http.MethodGet)
+	roomGroup.HandleFunc("/{code}", d.Room.Update).Methods(http.MethodPut)
+	roomGroup.HandleFunc("/{code}", d.Room.Delete).Methods(http.MethodDelete)
+	roomGroup.HandleFunc("/{code}/cameras", d.Room.AddCamera).Methods(http.MethodPost)
+	roomGroup.HandleFunc("/{code}/cameras/{camera_id}", d.Room.DeleteCamera).Methods(http.MethodDelete)
+	api.Handle("/cameras/status", jwtMW(http.HandlerFunc(d.Camera.Status))).Methods(http.MethodGet)
 
 	api.HandleFunc("/departments", d.Department.List).Methods("GET")
 	api.HandleFunc("/departments/{id:[0-9]+}", d.Department.GetByID).Methods("GET")
@@ -89,15 +152,15 @@ func New(d Dependencies) *mux.Router {
 	api.HandleFunc("/teachers/{isu}/lectures", d.Lecture.ListByTeacher).Methods("GET")
 	api.HandleFunc("/subjects/{id:[0-9]+}/lectures", d.Lecture.ListBySubject).Methods("GET")
 	api.HandleFunc("/groups/{code}/lectures", d.Lecture.ListByGroup).Methods("GET")
+	api.Handle("/lectures/{id:[0-9]+}/visits", jwtMW(http.HandlerFunc(d.VisitHistory.GetLectureVisits))).Methods(http.MethodGet)
+	api.Handle("/students/{isu}/visits", jwtMW(http.HandlerFunc(d.VisitHistory.GetStudentVisits))).Methods(http.MethodGet)
 
-	api.HandleFunc("/practices", d.Practice.Create).Methods("POST")
-	api.HandleFunc("/practices/{id:[0-9]+}", d.Practice.GetByID).Methods("GET")
-	api.HandleFunc("/teachers/{isu}/practices", d.Practice.ListByTeacher).Methods("GET")
-	api.HandleFunc("/subjects/{id:[0-9]+}/practices", d.Practice.ListBySubject).Methods("GET")
-	api.HandleFunc("/groups/{code}/practices", d.Practice.ListByGroup).Methods("GET")
-
-	api.HandleFunc("/lecture/start", d.LectureManager.StartLecture).Methods(http.MethodPost)
-	api.HandleFunc("/lecture/stop", d.LectureManager.StopLecture).Methods(http.MethodPost)
+	// practices: устаревшие обёртки над /classes/practice...
+	api.HandleFunc("/practices", d.Class.CreatePractice).Methods("POST")
+	api.HandleFunc("/practices/{id:[0-9]+}", d.Class.GetPracticeByID).Methods("GET")
+	api.HandleFunc("/teachers/{isu}/practices", d.Class.ListPracticesByTeacher).Methods("GET")
+	api.HandleFunc("/subjects/{id:[0-9]+}/practices", d.Class.ListPracticesBySubject).Methods("GET")
+	api.HandleFunc("/groups/{code}/practices", d.Class.ListPracticesByGroup).Methods("GET")
 
 	// visits
 	visitsGroup := api.PathPrefix("/visits").Subrouter()
@@ -108,6 +171,12 @@ func New(d Dependencies) *mux.Router {
 	visitsGroup.HandleFunc("/teacher/{lecture_id}/groups", d.VisitsHandler.GetLectureGroups).Methods(http.MethodGet)
 	visitsGroup.HandleFunc("/teacher/{lecture_id}/{group_code}/students", d.VisitsHandler.GetLectureGroupStudents).Methods(http.MethodGet)
 	visitsGroup.HandleFunc("/teacher/subjects", d.VisitsHandler.GetTeacherSubjects).Methods(http.MethodGet)
+	visitsGroup.HandleFunc("/classes/{kind}/subjects", d.ClassVisitsHandler.GetVisitedSubjects).Methods(http.MethodGet)
+	visitsGroup.HandleFunc("/classes/{kind}/teacher/subjects", d.ClassVisitsHandler.GetTeacherSubjects).Methods(http.MethodGet)
+	visitsGroup.HandleFunc("/classes/{kind}/teacher/class/{class_id}/groups", d.ClassVisitsHandler.GetClassGroups).Methods(http.MethodGet)
+	visitsGroup.HandleFunc("/classes/{kind}/teacher/class/{class_id}/{group_code}/students", d.ClassVisitsHandler.GetClassGroupStudents).Methods(http.MethodGet)
+	visitsGroup.HandleFunc("/classes/{kind}/teacher/{subject_id}", d.ClassVisitsHandler.GetTeacherClassesBySubject).Methods(http.MethodGet)
+	visitsGroup.HandleFunc("/classes/{kind}/{subject_id}", d.ClassVisitsHandler.GetStudentClassesBySubject).Methods(http.MethodGet)
 
 	// cores
 	userGroup := api.PathPrefix("/user").Subrouter()
@@ -119,9 +188,34 @@ func New(d Dependencies) *mux.Router {
 	userGroup.HandleFunc("/roles", d.User.GetRoles).Methods(http.MethodGet)
 	adminGroup.HandleFunc("/roles", d.User.AddRole).Methods(http.MethodPost)
 
+	// dead letters
+	deadLetterGroup := api.PathPrefix("/admin/dead-letters").Subrouter()
+	deadLetterGroup.Use(jwtMW)
+	deadLetterGroup.HandleFunc("", d.DeadLetters.List).Methods(http.MethodGet)
+	deadLetterGroup.HandleFunc("/{id:[0-9]+}", d.DeadLetters.Get).Methods(http.MethodGet)
+	deadLetterGroup.HandleFunc("/replay", d.DeadLetters.Replay).Methods(http.MethodPost)
+	deadLetterGroup.HandleFunc("/discard", d.DeadLetters.Discard).Methods(http.MethodPost)
+
+	// attendance policies
+	policyGroup := api.PathPrefix("/admin/attendance-policies").Subrouter()
+	policyGroup.Use(jwtMW)
+	policyGroup.HandleFunc("", d.AttendancePolicies.List).Methods(http.MethodGet)
+	policyGroup.HandleFunc("/subjects/{id:[0-9]+}", d.AttendancePolicies.SetForSubject).Methods(http.MethodPut)
+	policyGroup.HandleFunc("/subjects/{id:[0-9]+}", d.AttendancePolicies.DeleteForSubject).Methods(http.MethodDelete)
+	policyGroup.HandleFunc("/departments/{id:[0-9]+}", d.AttendancePolicies.SetForDepartment).Methods(http.MethodPut)
+	policyGroup.HandleFunc("/departments/{id:[0-9]+}", d.AttendancePolicies.DeleteForDepartment).Methods(http.MethodDelete)
+
+	// recognition thresholds
+	thresholdGroup := api.PathPrefix("/admin/recognition-thresholds").Subrouter()
+	thresholdGroup.Use(jwtMW)
+	thresholdGroup.HandleFunc("", d.RecognitionThresholds.List).Methods(http.MethodGet)
+	thresholdGroup.HandleFunc("/departments/{id:[0-9]+}", d.RecognitionThresholds.SetForDepartment).Methods(http.MethodPut)
+	thresholdGroup.HandleFunc("/departments/{id:[0-9]+}", d.RecognitionThresholds.DeleteForDepartment).Methods(http.MethodDelete)
+
 	// services
 	serviceGroup := api.PathPrefix("/service").Subrouter()
 	serviceGroup.HandleFunc("/dataset", d.DataSet.Get).Methods(http.MethodGet)
+	serviceGroup.Handle("/ingest/stats", jwtMW(ingest.StatsHandler(d.Ingest))).Methods(http.MethodGet)
 
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
@@ -0,0 +1,157 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type MessageType string
+
+const (
+	TypeRecognition  MessageType = "recognition"
+	TypeLectureEnd   MessageType = "lecture_end"
+	TypeHeartbeat    MessageType = "heartbeat"
+	TypeCameraStatus MessageType = "camera_status"
+)
+
+// legacyVersion сообщения без конверта: плоский JSON распознавания или {"end": true}.
+const legacyVersion = 0
+
+// supportedVersions версии payload, которые умеет разбирать бэкенд, по типам сообщений.
+// Новая версия контракта добавляется сюда вместе с её разбором, старые продолжают работать.
+var supportedVersions = map[MessageType][]int{
+	TypeRecognition:  {legacyVersion, 1},
+	TypeLectureEnd:   {legacyVersion, 1},
+	TypeHeartbeat:    {1},
+	TypeCameraStatus: {1},
+}
+
+// Envelope конверт сообщения из очереди распознавания.
+//
+//	{"type": "recognition", "version": 1, "payload": {...}}
+type Envelope struct {
+	Type    MessageType     `json:"type"`
+	Version int             `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type LectureEndPayload struct {
+	LectureID int64  `json:"lecture_id"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type HeartbeatPayload struct {
+	CameraID  string    `json:"camera_id"`
+	Timestamp time.Time `json:"timestamp"`
+	FPS       *float64  `json:"fps,omitempty"`
+	// Errors ошибки распознавания на камере с прошлого heartbeat
+	Errors int64 `json:"errors,omitempty"`
+}
+
+type CameraStatusPayload struct {
+	CameraID  string    `json:"camera_id"`
+	Status    string    `json:"status"` // ok / degraded / down
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func decodeEnvelope(body []byte) (Envelope, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return Envelope{}, reject(ReasonInvalidJSON, "invalid json: %v", err)
+	}
+
+	// старый формат без конверта — до перехода камер на версионированный контракт
+	if _, ok := probe["type"]; !ok {
+		return decodeLegacy(body, probe), nil
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return Envelope{}, reject(ReasonInvalidJSON, "invalid envelope: %v", err)
+	}
+
+	env.Type = MessageType(strings.TrimSpace(string(env.Type)))
+	versions, ok := supportedVersions[env.Type]
+	if !ok {
+		return env, reject(ReasonUnknownType, "unknown message type %q", env.Type)
+	}
+	if env.Version == legacyVersion || !containsVersion(versions, env.Version) {
+		return env, reject(ReasonUnsupportedVersion, "unsupported %s version %d", env.Type, env.Version)
+	}
+	if len(bytes.TrimSpace(env.Payload)) == 0 || bytes.Equal(bytes.TrimSpace(env.Payload), []byte("null")) {
+		return env, reject(ReasonInvalidPayload, "%s payload is empty", env.Type)
+	}
+
+	return env, nil
+}
+
+func decodeLegacy(body []byte, probe map[string]json.RawMessage) Envelope {
+	if raw, ok := probe["end"]; ok {
+		var end bool
+		if json.Unmarshal(raw, &end) == nil && end {
+			return Envelope{Type: TypeLectureEnd, Version: legacyVersion, Payload: body}
+		}
+	}
+	return Envelope{Type: TypeRecognition, Version: legacyVersion, Payload: body}
+}
+
+func containsVersion(versions []int, v int) bool {
+	for _, x := range versions {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeLectureEnd(env Envelope, lectureID int64) (LectureEndPayload, error) {
+	var p LectureEndPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil {
+		return p, reject(ReasonInvalidPayload, "invalid lecture_end payload: %v", err)
+	}
+	if p.LectureID == 0 {
+		p.LectureID = lectureID
+	}
+	if p.LectureID != lectureID {
+		return p, reject(ReasonLectureMismatch, "lecture_id %d does not match consumer lecture %d", p.LectureID, lectureID)
+	}
+	return p, nil
+}
+
+func decodeHeartbeat(env Envelope) (HeartbeatPayload, error) {
+	var p HeartbeatPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil {
+		return p, reject(ReasonInvalidPayload, "invalid heartbeat payload: %v", err)
+	}
+	p.CameraID = strings.TrimSpace(p.CameraID)
+	if p.CameraID == "" {
+		return p, reject(ReasonInvalidPayload, "heartbeat camera_id is empty")
+	}
+	if p.FPS != nil && *p.FPS < 0 {
+		return p, reject(ReasonInvalidPayload, "heartbeat fps %v is negative", *p.FPS)
+	}
+	if p.Errors < 0 {
+		return p, reject(ReasonInvalidPayload, "heartbeat errors %d is negative", p.Errors)
+	}
+	return p, nil
+}
+
+func decodeCameraStatus(env Envelope) (CameraStatusPayload, error) {
+	var p CameraStatusPayload
+	if err := json.Unmarshal(env.Payload, &p); err != nil {
+		return p, reject(ReasonInvalidPayload, "invalid camera_status payload: %v", err)
+	}
+	p.CameraID = strings.TrimSpace(p.CameraID)
+	if p.CameraID == "" {
+		return p, reject(ReasonInvalidPayload, "camera_status camera_id is empty")
+	}
+	switch p.Status {
+	case "ok", "degraded", "down":
+	default:
+		return p, reject(ReasonInvalidPayload, "camera_status status %q is unknown", p.Status)
+	}
+	return p, nil
+}
@@ -0,0 +1,234 @@
+package ingest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"monitoring_backend/internal/rabbit"
+)
+
+// rejectReason причина RejectError или "" для ошибок, после которых сообщение не отбрасывается.
+func rejectReason(err error) string {
+	var re *RejectError
+	if errors.As(err, &re) {
+		return re.Reason()
+	}
+	return ""
+}
+
+func TestDecodeEnvelope(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantType    MessageType
+		wantVersion int
+		wantReason  string
+	}{
+		{
+			name:     "legacy recognition",
+			body:     `{"person_id": "123", "timestamp": "2025-03-01T10:00:00Z"}`,
+			wantType: TypeRecognition,
+		},
+		{
+			name:     "legacy lecture end",
+			body:     `{"end": true}`,
+			wantType: TypeLectureEnd,
+		},
+		{
+			name:     "legacy end false is recognition",
+			body:     `{"end": false, "person_id": "123"}`,
+			wantType: TypeRecognition,
+		},
+		{
+			name:        "recognition v1",
+			body:        `{"type": "recognition", "version": 1, "payload": {"person_id": "123"}}`,
+			wantType:    TypeRecognition,
+			wantVersion: 1,
+		},
+		{
+			name:        "type is trimmed",
+			body:        `{"type": " heartbeat ", "version": 1, "payload": {"camera_id": "c1"}}`,
+			wantType:    TypeHeartbeat,
+			wantVersion: 1,
+		},
+		{
+			name:       "not json",
+			body:       `person_id=123`,
+			wantReason: ReasonInvalidJSON,
+		},
+		{
+			name:       "json array",
+			body:       `[1, 2]`,
+			wantReason: ReasonInvalidJSON,
+		},
+		{
+			name:       "unknown type",
+			body:       `{"type": "selfie", "version": 1, "payload": {}}`,
+			wantReason: ReasonUnknownType,
+		},
+		{
+			name:       "future version",
+			body:       `{"type": "recognition", "version": 2, "payload": {}}`,
+			wantReason: ReasonUnsupportedVersion,
+		},
+		{
+			name:       "envelope without version",
+			body:       `{"type": "recognition", "payload": {}}`,
+			wantReason: ReasonUnsupportedVersion,
+		},
+		{
+			name:       "heartbeat has no legacy version",
+			body:       `{"type": "heartbeat", "version": 0, "payload": {}}`,
+			wantReason: ReasonUnsupportedVersion,
+		},
+		{
+			name:       "missing payload",
+			body:       `{"type": "recognition", "version": 1}`,
+			wantReason: ReasonInvalidPayload,
+		},
+		{
+			name:       "null payload",
+			body:       `{"type": "recognition", "version": 1, "payload": null}`,
+			wantReason: ReasonInvalidPayload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := decodeEnvelope([]byte(tt.body))
+			if reason := rejectReason(err); reason != tt.wantReason {
+				t.Fatalf("reason = %q, want %q (err %v)", reason, tt.wantReason, err)
+			}
+			if tt.wantReason != "" {
+				if !errors.Is(err, rabbit.ErrReject) {
+					t.Errorf("err %v is not rabbit.ErrReject", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if env.Type != tt.wantType || env.Version != tt.wantVersion {
+				t.Errorf("envelope = %s v%d, want %s v%d", env.Type, env.Version, tt.wantType, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestDecodeRecognition(t *testing.T) {
+	const lectureID = 7
+
+	tests := []struct {
+		name       string
+		env        Envelope
+		wantPerson string
+		wantCamera *string
+		wantReason string
+	}{
+		{
+			name:       "legacy without lecture_id",
+			env:        Envelope{Version: legacyVersion, Payload: []byte(`{"person_id": " 123 ", "timestamp": "2025-03-01T10:00:00Z"}`)},
+			wantPerson: "123",
+		},
+		{
+			name:       "v1 with camera and confidence",
+			env:        Envelope{Version: 1, Payload: []byte(`{"lecture_id": 7, "person_id": "123", "timestamp": "2025-03-01T10:00:00Z", "camera_id": " cam-1 ", "confidence": 0.93}`)},
+			wantPerson: "123",
+			wantCamera: ptr("cam-1"),
+		},
+		{
+			name:       "blank camera is dropped",
+			env:        Envelope{Version: 1, Payload: []byte(`{"person_id": "123", "timestamp": "2025-03-01T10:00:00Z", "camera_id": "  "}`)},
+			wantPerson: "123",
+		},
+		{
+			name:       "missing timestamp",
+			env:        Envelope{Version: 1, Payload: []byte(`{"person_id": "123"}`)},
+			wantReason: ReasonInvalidPayload,
+		},
+		{
+			name:       "legacy without timestamp",
+			env:        Envelope{Version: legacyVersion, Payload: []byte(`{"person_id": "123"}`)},
+			wantPerson: "123",
+		},
+		{
+			name:       "empty person",
+			env:        Envelope{Version: 1, Payload: []byte(`{"person_id": " ", "timestamp": "2025-03-01T10:00:00Z"}`)},
+			wantReason: ReasonInvalidPayload,
+		},
+		{
+			name:       "other lecture",
+			env:        Envelope{Version: 1, Payload: []byte(`{"lecture_id": 8, "person_id": "123", "timestamp": "2025-03-01T10:00:00Z"}`)},
+			wantReason: ReasonLectureMismatch,
+		},
+		{
+			name:       "confidence above one",
+			env:        Envelope{Version: 1, Payload: []byte(`{"person_id": "123", "timestamp": "2025-03-01T10:00:00Z", "confidence": 1.2}`)},
+			wantReason: ReasonInvalidPayload,
+		},
+		{
+			name:       "malformed timestamp",
+			env:        Envelope{Version: 1, Payload: []byte(`{"person_id": "123", "timestamp": "yesterday"}`)},
+			wantReason: ReasonInvalidPayload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.env.Type = TypeRecognition
+			msg, err := decodeRecognition(tt.env, lectureID)
+			if reason := rejectReason(err); reason != tt.wantReason {
+				t.Fatalf("reason = %q, want %q (err %v)", reason, tt.wantReason, err)
+			}
+			if tt.wantReason != "" {
+				return
+			}
+			if msg.PersonID != tt.wantPerson || msg.LectureID != lectureID {
+				t.Errorf("message = %s/%d, want %s/%d", msg.PersonID, msg.LectureID, tt.wantPerson, lectureID)
+			}
+			switch {
+			case (msg.CameraID == nil) != (tt.wantCamera == nil):
+				t.Errorf("camera = %v, want %v", msg.CameraID, tt.wantCamera)
+			case msg.CameraID != nil && *msg.CameraID != *tt.wantCamera:
+				t.Errorf("camera = %q, want %q", *msg.CameraID, *tt.wantCamera)
+			}
+		})
+	}
+}
+
+func TestCapturedAtClockSkew(t *testing.T) {
+	received := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	const (
+		maxSkew = 30 * time.Second
+		maxAge  = time.Hour
+	)
+
+	tests := []struct {
+		name       string
+		captured   time.Time
+		maxAge     time.Duration
+		wantReason string
+	}{
+		{name: "same time", captured: received, maxAge: maxAge},
+		{name: "camera clock slightly ahead", captured: received.Add(maxSkew), maxAge: maxAge},
+		{name: "too far in the future", captured: received.Add(maxSkew + time.Second), maxAge: maxAge, wantReason: ReasonClockSkew},
+		{name: "old but within max age", captured: received.Add(-maxAge), maxAge: maxAge},
+		{name: "older than max age", captured: received.Add(-maxAge - time.Second), maxAge: maxAge, wantReason: ReasonClockSkew},
+		{name: "age is not checked on replay", captured: received.Add(-30 * 24 * time.Hour), maxAge: 0},
+		{name: "future is rejected on replay too", captured: received.Add(time.Hour), maxAge: 0, wantReason: ReasonClockSkew},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := RecognitionMessage{PersonID: "123", Timestamp: &tt.captured}
+			got, err := msg.capturedAt(received, maxSkew, tt.maxAge)
+			if reason := rejectReason(err); reason != tt.wantReason {
+				t.Fatalf("reason = %q, want %q (err %v)", reason, tt.wantReason, err)
+			}
+			if !got.Equal(tt.captured) {
+				t.Errorf("capturedAt = %s, want %s", got, tt.captured)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package ingest
+
+import (
+	"net/http"
+
+	"monitoring_backend/internal/http/middleware"
+	"monitoring_backend/internal/http/response"
+)
+
+// StatsHandler godoc
+// @Summary Recognition queue ingest stats
+// @Description Счётчики сообщений очереди распознавания с момента старта процесса: принятые по типам, отброшенные в dead-letter queue по причинам и временные ошибки.
+// @Tags service
+// @Produce json
+// @Success 200 {object} ingest.StatsSnapshot
+// @Failure 403 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /api/service/ingest/stats [get]
+func StatsHandler(p *Pipeline) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !middleware.IsAdmin(r.Context()) {
+			response.WriteError(w, http.StatusForbidden, "Invalid role")
+			return
+		}
+
+		response.WriteJSON(w, http.StatusOK, p.Stats())
+	}
+}
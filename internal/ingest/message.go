@@ -0,0 +1,78 @@
+package ingest
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// RecognitionMessage payload сообщения распознавания (type=recognition).
+type RecognitionMessage struct {
+	LectureID int64  `json:"lecture_id"`
+	PersonID  string `json:"person_id"`
+	// Timestamp время кадра на камере. Вместе с lecture_id и person_id образует ключ дедупликации.
+	// Обязательно с версии 1; в legacy-формате его нет — тогда берётся время, когда сообщение
+	// впервые получено из очереди (rabbit.ReceivedAt), одинаковое у всех его повторов.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	CameraID  *string    `json:"camera_id,omitempty"`
+	// Confidence similarity лица с эталоном студента, [0, 1]. По ней снапшот принимается,
+	// уходит на проверку преподавателю или отбрасывается (пороги департамента группы студента).
+	Confidence *float64 `json:"confidence,omitempty"`
+}
+
+// decodeRecognition разбирает и валидирует payload распознавания. Все ошибки здесь
+// невосстановимы — повторная доставка того же тела даст тот же результат.
+func decodeRecognition(env Envelope, lectureID int64) (RecognitionMessage, error) {
+	var msg RecognitionMessage
+	if err := json.Unmarshal(env.Payload, &msg); err != nil {
+		return msg, reject(ReasonInvalidPayload, "invalid recognition payload: %v", err)
+	}
+
+	msg.PersonID = strings.TrimSpace(msg.PersonID)
+	if msg.PersonID == "" {
+		return msg, reject(ReasonInvalidPayload, "person_id is empty")
+	}
+
+	// lecture_id в теле необязателен, но если передан — должен совпадать с лекцией очереди
+	if msg.LectureID == 0 {
+		msg.LectureID = lectureID
+	}
+	if msg.LectureID != lectureID {
+		return msg, reject(ReasonLectureMismatch, "lecture_id %d does not match consumer lecture %d", msg.LectureID, lectureID)
+	}
+
+	// начиная с версии 1 время кадра обязательно
+	if env.Version >= 1 && msg.Timestamp == nil {
+		return msg, reject(ReasonInvalidPayload, "timestamp is required")
+	}
+
+	if msg.CameraID != nil {
+		cameraID := strings.TrimSpace(*msg.CameraID)
+		msg.CameraID = &cameraID
+		if cameraID == "" {
+			msg.CameraID = nil
+		}
+	}
+
+	if msg.Confidence != nil && (*msg.Confidence < 0 || *msg.Confidence > 1) {
+		return msg, reject(ReasonInvalidPayload, "confidence %v is out of range [0, 1]", *msg.Confidence)
+	}
+
+	return msg, nil
+}
+
+// capturedAt возвращает время кадра и проверяет расхождение часов камеры и сервера:
+// кадр не может быть сильно из будущего и не может быть старше maxAge (0 — возраст не проверяется).
+func (msg RecognitionMessage) capturedAt(receivedAt time.Time, maxSkew, maxAge time.Duration) (time.Time, error) {
+	capturedAt := *msg.Timestamp
+	if capturedAt.After(receivedAt.Add(maxSkew)) {
+		return capturedAt, reject(ReasonClockSkew, "timestamp %s is ahead of server time by more than %s",
+			capturedAt.Format(time.RFC3339), maxSkew)
+	}
+	if maxAge > 0 && capturedAt.Before(receivedAt.Add(-maxAge)) {
+		return capturedAt, reject(ReasonClockSkew, "timestamp %s is older than %s",
+			capturedAt.Format(time.RFC3339), maxAge)
+	}
+
+	return capturedAt, nil
+}
@@ -0,0 +1,234 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"monitoring_backend/internal/domain"
+	"monitoring_backend/internal/rabbit"
+	"monitoring_backend/internal/ws"
+)
+
+type Writer interface {
+	Write(ctx context.Context, visit domain.ClassVisit) (WriteResult, error)
+}
+
+// Publisher шина событий, в которую попадают только уже сохранённые посещения.
+type Publisher interface {
+	Broadcast(lectureID int64, data []byte)
+}
+
+// CameraTracker учёт состояния камер по heartbeat и camera_status.
+type CameraTracker interface {
+	Heartbeat(ctx context.Context, hb domain.CameraHeartbeat) error
+	Report(ctx context.Context, r domain.CameraReport) error
+}
+
+// Pipeline обрабатывает сообщения распознавания: валидация → запись в БД → публикация события.
+// Посещение записывается независимо от того, открыт ли у кого-то дашборд лекции.
+// publisher может быть nil — тогда события не публикуются; cameras nil — heartbeat
+// и camera_status только проверяются.
+type Pipeline struct {
+	writer    Writer
+	publisher Publisher
+	cameras   CameraTracker
+	stats     *Stats
+
+	maxClockSkew time.Duration
+	maxEventAge  time.Duration
+}
+
+const (
+	defaultMaxClockSkew = 30 * time.Second
+	defaultMaxEventAge  = 24 * time.Hour
+)
+
+func NewPipeline(writer Writer, publisher Publisher, cameras CameraTracker, maxClockSkew, maxEventAge time.Duration) *Pipeline {
+	if maxClockSkew <= 0 {
+		maxClockSkew = defaultMaxClockSkew
+	}
+	if maxEventAge <= 0 {
+		maxEventAge = defaultMaxEventAge
+	}
+
+	return &Pipeline{
+		writer:       writer,
+		publisher:    publisher,
+		cameras:      cameras,
+		stats:        newStats(),
+		maxClockSkew: maxClockSkew,
+		maxEventAge:  maxEventAge,
+	}
+}
+
+func (p *Pipeline) Handle(ctx context.Context, lectureID int64, body []byte) error {
+	return p.handle(ctx, lectureID, body, p.maxEventAge)
+}
+
+// Replay обрабатывает сообщение, переигрываемое из dead-letter. Возраст кадра не проверяется:
+// сообщение могло пролежать в dead-letter дольше max_event_age.
+func (p *Pipeline) Replay(ctx context.Context, lectureID int64, body []byte) error {
+	return p.handle(ctx, lectureID, body, 0)
+}
+
+// handle maxEventAge 0 — без ограничения возраста кадра.
+func (p *Pipeline) handle(ctx context.Context, lectureID int64, body []byte, maxEventAge time.Duration) error {
+	env, err := decodeEnvelope(body)
+	if err == nil {
+		err = p.dispatch(ctx, lectureID, env, maxEventAge)
+	}
+	p.stats.observe(env.Type, err)
+
+	return err
+}
+
+func (p *Pipeline) Stats() StatsSnapshot {
+	return p.stats.Snapshot()
+}
+
+func (p *Pipeline) dispatch(ctx context.Context, lectureID int64, env Envelope, maxEventAge time.Duration) error {
+	switch env.Type {
+	case TypeRecognition:
+		msg, err := decodeRecognition(env, lectureID)
+		if err != nil {
+			return err
+		}
+		return p.handleRecognition(ctx, msg, maxEventAge)
+
+	case TypeLectureEnd:
+		end, err := decodeLectureEnd(env, lectureID)
+		if err != nil {
+			return err
+		}
+		log.Printf("INFO: lecture %d is end (reason=%q)", end.LectureID, end.Reason)
+		return rabbit.ErrLectureEnd
+
+	case TypeHeartbeat:
+		hb, err := decodeHeartbeat(env)
+		if err != nil {
+			return err
+		}
+		return p.handleHeartbeat(ctx, hb)
+
+	case TypeCameraStatus:
+		st, err := decodeCameraStatus(env)
+		if err != nil {
+			return err
+		}
+		return p.handleCameraStatus(ctx, st)
+	}
+
+	return reject(ReasonUnknownType, "unknown message type %q", env.Type)
+}
+
+func (p *Pipeline) handleRecognition(ctx context.Context, msg RecognitionMessage, maxEventAge time.Duration) error {
+	receivedAt := time.Now()
+
+	// legacy-сообщение без времени кадра: ключ дедупликации — время первого получения из очереди
+	if msg.Timestamp == nil {
+		firstReceivedAt := rabbit.ReceivedAt(ctx, receivedAt)
+		msg.Timestamp = &firstReceivedAt
+	}
+
+	capturedAt, err := msg.capturedAt(receivedAt, p.maxClockSkew, maxEventAge)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.writer.Write(ctx, domain.ClassVisit{
+		LectureID:  msg.LectureID,
+		UserID:     msg.PersonID,
+		CapturedAt: capturedAt,
+		ReceivedAt: receivedAt,
+		CameraID:   msg.CameraID,
+		Confidence: msg.Confidence,
+	})
+	if err != nil {
+		return classify(fmt.Errorf("apply visit for %s: %w", msg.PersonID, err))
+	}
+
+	// на проверке и отброшенные снапшоты в присутствии не учитываются, события по ним нет
+	if res.Verdict != domain.RecognitionAccepted {
+		if !res.Duplicate {
+			p.stats.held(res.Verdict)
+		}
+		return nil
+	}
+
+	// повторная доставка уже записанного снапшота — событие уже было разослано;
+	// у конвейера практик шины событий нет
+	if res.Duplicate || p.publisher == nil {
+		return nil
+	}
+
+	// id события = id снапшота: по нему клиент может догнать пропущенное
+	data, err := ws.Encode(ws.TypeVisit, strconv.FormatInt(res.Visit.ID, 10), ws.NewVisitResponse(res.Visit, res.User))
+	if err != nil {
+		// посещение уже записано, повторная доставка ничего не исправит
+		log.Printf("ERROR: marshal visit event for %s: %v", msg.PersonID, err)
+		return nil
+	}
+
+	p.publisher.Broadcast(msg.LectureID, data)
+
+	return nil
+}
+
+// handleHeartbeat обновляет last_seen камеры. Время камеры не используется:
+// last_seen — когда сообщение дошло до бэкенда, как и для таймаута heartbeat.
+func (p *Pipeline) handleHeartbeat(ctx context.Context, hb HeartbeatPayload) error {
+	if p.cameras == nil {
+		return nil
+	}
+
+	err := p.cameras.Heartbeat(ctx, domain.CameraHeartbeat{
+		CameraID: hb.CameraID,
+		SeenAt:   time.Now(),
+		FPS:      hb.FPS,
+		Errors:   hb.Errors,
+	})
+	if err != nil {
+		return classify(fmt.Errorf("track heartbeat of camera %s: %w", hb.CameraID, err))
+	}
+	return nil
+}
+
+func (p *Pipeline) handleCameraStatus(ctx context.Context, st CameraStatusPayload) error {
+	if p.cameras == nil {
+		return nil
+	}
+
+	err := p.cameras.Report(ctx, domain.CameraReport{
+		CameraID: st.CameraID,
+		Status:   domain.CameraHealth(st.Status),
+		Message:  st.Message,
+		SeenAt:   time.Now(),
+	})
+	if err != nil {
+		return classify(fmt.Errorf("track status of camera %s: %w", st.CameraID, err))
+	}
+	return nil
+}
+
+// classify отделяет ошибки данных (неизвестный ISU, студент без группы, нарушение FK)
+// от временных ошибок БД, при которых сообщение нужно вернуть в очередь.
+func classify(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return reject(ReasonUnknownStudent, "%v", err)
+	}
+
+	var pgErr *pgconn.PgError
+	// класс 23 — integrity constraint violation
+	if errors.As(err, &pgErr) && len(pgErr.Code) == 5 && pgErr.Code[:2] == "23" {
+		return reject(ReasonConstraint, "%v", err)
+	}
+
+	return err
+}
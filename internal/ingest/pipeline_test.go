@@ -0,0 +1,132 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"monitoring_backend/internal/domain"
+	"monitoring_backend/internal/rabbit"
+)
+
+// recordingWriter запоминает снапшоты, которые конвейер отдал на запись.
+type recordingWriter struct {
+	visits []domain.ClassVisit
+}
+
+func (w *recordingWriter) Write(_ context.Context, visit domain.ClassVisit) (WriteResult, error) {
+	w.visits = append(w.visits, visit)
+	return WriteResult{Visit: visit, Verdict: domain.RecognitionAccepted}, nil
+}
+
+func TestPipelineRecognitionTimestamp(t *testing.T) {
+	const lectureID = 7
+	firstReceived := time.Now().Add(-time.Minute).Truncate(time.Microsecond)
+	frame := time.Now().Add(-2 * time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name string
+		body string
+		// receivedAt время первого получения, которое консьюмер кладёт в контекст; нулевое — не кладёт
+		receivedAt   time.Time
+		wantCaptured time.Time
+		wantReason   string
+	}{
+		{
+			name:         "legacy flat payload uses first receive time",
+			body:         `{"person_id": "123"}`,
+			receivedAt:   firstReceived,
+			wantCaptured: firstReceived,
+		},
+		{
+			name:         "legacy flat payload keeps its own timestamp",
+			body:         `{"person_id": "123", "timestamp": "` + frame.Format(time.RFC3339) + `"}`,
+			receivedAt:   firstReceived,
+			wantCaptured: frame,
+		},
+		{
+			name:       "v1 without timestamp is rejected",
+			body:       `{"type": "recognition", "version": 1, "payload": {"person_id": "123"}}`,
+			receivedAt: firstReceived,
+			wantReason: ReasonInvalidPayload,
+		},
+		{
+			name:         "v1 uses frame timestamp",
+			body:         `{"type": "recognition", "version": 1, "payload": {"person_id": "123", "timestamp": "` + frame.Format(time.RFC3339) + `"}}`,
+			receivedAt:   firstReceived,
+			wantCaptured: frame,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &recordingWriter{}
+			p := NewPipeline(writer, nil, nil, 0, 0)
+
+			ctx := context.Background()
+			if !tt.receivedAt.IsZero() {
+				ctx = rabbit.WithReceivedAt(ctx, tt.receivedAt)
+			}
+
+			err := p.Handle(ctx, lectureID, []byte(tt.body))
+			if reason := rejectReason(err); reason != tt.wantReason {
+				t.Fatalf("reason = %q, want %q (err %v)", reason, tt.wantReason, err)
+			}
+			if tt.wantReason != "" {
+				if len(writer.visits) != 0 {
+					t.Errorf("rejected message was written: %+v", writer.visits)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+			if len(writer.visits) != 1 {
+				t.Fatalf("written %d visits, want 1", len(writer.visits))
+			}
+			if got := writer.visits[0].CapturedAt; !got.Equal(tt.wantCaptured) {
+				t.Errorf("captured at = %s, want %s", got, tt.wantCaptured)
+			}
+		})
+	}
+}
+
+// Повторная доставка legacy-сообщения без времени кадра не должна стать вторым снапшотом.
+func TestPipelineLegacyRedeliveryIsDeduplicated(t *testing.T) {
+	repo := newFakeVisits("123")
+	w := NewBatchWriter(repo, nil, 1, time.Hour)
+	defer w.Close(context.Background())
+
+	p := NewPipeline(w, nil, nil, 0, 0)
+	body := []byte(`{"person_id": "123"}`)
+	ctx := rabbit.WithReceivedAt(context.Background(), time.Now().Add(-time.Second))
+
+	for attempt := range 3 {
+		if err := p.Handle(ctx, 1, body); err != nil {
+			t.Fatalf("attempt %d: %v", attempt, err)
+		}
+	}
+
+	if len(repo.stored) != 1 {
+		t.Errorf("stored %d snapshots, want 1", len(repo.stored))
+	}
+	if got := p.Stats(); got.Failed != 0 || len(got.Rejected) != 0 {
+		t.Errorf("stats = %+v, want no failures", got)
+	}
+}
+
+func TestPipelineLegacyWithoutReceiveTime(t *testing.T) {
+	writer := &recordingWriter{}
+	p := NewPipeline(writer, nil, nil, 0, 0)
+
+	before := time.Now()
+	if err := p.Handle(context.Background(), 1, []byte(`{"person_id": "123"}`)); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if len(writer.visits) != 1 {
+		t.Fatalf("written %d visits, want 1", len(writer.visits))
+	}
+	if got := writer.visits[0].CapturedAt; got.Before(before) || got.After(time.Now()) {
+		t.Errorf("captured at = %s, want current time", got)
+	}
+}
@@ -0,0 +1,42 @@
+package ingest
+
+import (
+	"fmt"
+
+	"monitoring_backend/internal/rabbit"
+)
+
+// Причины, по которым сообщение отбрасывается в dead-letter queue.
+const (
+	ReasonInvalidJSON        = "invalid_json"
+	ReasonUnknownType        = "unknown_type"
+	ReasonUnsupportedVersion = "unsupported_version"
+	ReasonInvalidPayload     = "invalid_payload"
+	ReasonLectureMismatch    = "lecture_mismatch"
+	ReasonClockSkew          = "clock_skew"
+	ReasonUnknownStudent     = "unknown_student"
+	ReasonConstraint         = "constraint_violation"
+)
+
+// RejectError невосстановимая ошибка обработки сообщения с машиночитаемой причиной.
+type RejectError struct {
+	reason string
+	err    error
+}
+
+func reject(reason string, format string, args ...any) error {
+	return &RejectError{reason: reason, err: fmt.Errorf(format, args...)}
+}
+
+func (e *RejectError) Error() string {
+	return e.reason + ": " + e.err.Error()
+}
+
+// Reason используется консьюмером для заголовка сообщения в dead-letter queue.
+func (e *RejectError) Reason() string {
+	return e.reason
+}
+
+func (e *RejectError) Unwrap() []error {
+	return []error{rabbit.ErrReject, e.err}
+}
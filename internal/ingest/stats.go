@@ -0,0 +1,96 @@
+package ingest
+
+import (
+	"errors"
+	"sync"
+
+	"monitoring_backend/internal/domain"
+	"monitoring_backend/internal/rabbit"
+)
+
+// Stats счётчики обработанных сообщений с момента старта процесса.
+type Stats struct {
+	mu       sync.Mutex
+	received map[MessageType]int64
+	rejected map[string]int64
+	failed   int64
+
+	pendingReview int64
+	lowConfidence int64
+}
+
+type StatsSnapshot struct {
+	// Received принятые сообщения по типам (включая отброшенные после разбора конверта)
+	Received map[string]int64 `json:"received"`
+	// Rejected сообщения, отправленные в dead-letter queue, по причинам
+	Rejected map[string]int64 `json:"rejected"`
+	// Failed временные ошибки, после которых сообщение вернулось в очередь
+	Failed int64 `json:"failed"`
+	// PendingReview снапшоты с similarity между порогами, ушедшие на проверку преподавателю
+	PendingReview int64 `json:"pending_review"`
+	// LowConfidence снапшоты с similarity ниже порога проверки, отброшенные без dead-letter queue
+	LowConfidence int64 `json:"low_confidence"`
+}
+
+func newStats() *Stats {
+	return &Stats{
+		received: make(map[MessageType]int64),
+		rejected: make(map[string]int64),
+	}
+}
+
+func (s *Stats) observe(t MessageType, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t != "" {
+		s.received[t]++
+	}
+
+	// конец лекции — штатный исход, сообщение подтверждается
+	if err == nil || errors.Is(err, rabbit.ErrLectureEnd) {
+		return
+	}
+
+	var rejectErr *RejectError
+	if errors.As(err, &rejectErr) {
+		s.rejected[rejectErr.Reason()]++
+		return
+	}
+
+	s.failed++
+}
+
+// held учитывает снапшот, не записанный как посещение.
+func (s *Stats) held(v domain.RecognitionVerdict) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch v {
+	case domain.RecognitionReview:
+		s.pendingReview++
+	case domain.RecognitionDiscarded:
+		s.lowConfidence++
+	}
+}
+
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := StatsSnapshot{
+		Received: make(map[string]int64, len(s.received)),
+		Rejected: make(map[string]int64, len(s.rejected)),
+		Failed:   s.failed,
+
+		PendingReview: s.pendingReview,
+		LowConfidence: s.lowConfidence,
+	}
+	for t, n := range s.received {
+		out.Received[string(t)] = n
+	}
+	for reason, n := range s.rejected {
+		out.Rejected[reason] = n
+	}
+	return out
+}
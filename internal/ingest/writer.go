@@ -0,0 +1,284 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"monitoring_backend/internal/domain"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 500 * time.Millisecond
+)
+
+type VisitsRepository interface {
+	AddBatch(ctx context.Context, visits []domain.ClassVisit) ([]domain.ClassVisit, error)
+	AddPendingBatch(ctx context.Context, visits []domain.ClassVisit) ([]domain.ClassVisit, error)
+	ListStudentsByISU(ctx context.Context, isus []string) (map[string]domain.User, error)
+}
+
+// ThresholdResolver пороги similarity для каждого из студентов isus.
+type ThresholdResolver interface {
+	ForStudents(ctx context.Context, isus []string) (map[string]domain.RecognitionThresholds, error)
+}
+
+// WriteResult итог записи одного снапшота.
+type WriteResult struct {
+	Visit domain.ClassVisit
+	User  domain.User
+	// Verdict accepted — снапшот записан как посещение, review — на проверку преподавателю
+	// (Visit.ID тогда id записи на проверке), discarded — не записан совсем.
+	Verdict domain.RecognitionVerdict
+	// Duplicate — такой снапшот (lecture_id, user_id, captured_at) уже был записан раньше,
+	// например при повторной доставке сообщения из RabbitMQ.
+	Duplicate bool
+}
+
+type pendingVisit struct {
+	visit  domain.ClassVisit
+	result chan writeOutcome
+}
+
+type writeOutcome struct {
+	res WriteResult
+	err error
+}
+
+// BatchWriter копит снапшоты по занятиям и пишет их в visits.classes_visiting
+// одним запросом: при наборе batchSize штук или раз в flushInterval.
+// Снапшоты с similarity ниже порога приёмки уходят на проверку (visits.pending_sightings) или отбрасываются;
+// thresholds nil — принимаются все.
+// Write блокируется до записи пачки, чтобы сообщение ack-алось только после сохранения.
+type BatchWriter struct {
+	repo          VisitsRepository
+	thresholds    ThresholdResolver
+	batchSize     int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	buffers map[int64][]pendingVisit // lecture_id → снапшоты в ожидании записи
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewBatchWriter(repo VisitsRepository, thresholds ThresholdResolver, batchSize int, flushInterval time.Duration) *BatchWriter {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	w := &BatchWriter{
+		repo:          repo,
+		thresholds:    thresholds,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		buffers:       make(map[int64][]pendingVisit),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+func (w *BatchWriter) Write(ctx context.Context, visit domain.ClassVisit) (WriteResult, error) {
+	// Postgres хранит микросекунды — приводим заранее, чтобы ключ дедупликации совпадал
+	visit.CapturedAt = visit.CapturedAt.Truncate(time.Microsecond)
+
+	p := pendingVisit{visit: visit, result: make(chan writeOutcome, 1)}
+
+	w.mu.Lock()
+	buf := append(w.buffers[visit.LectureID], p)
+	if len(buf) >= w.batchSize {
+		delete(w.buffers, visit.LectureID)
+		w.mu.Unlock()
+		w.flush(buf)
+	} else {
+		w.buffers[visit.LectureID] = buf
+		w.mu.Unlock()
+	}
+
+	select {
+	case out := <-p.result:
+		return out.res, out.err
+	case <-ctx.Done():
+		// снапшот всё равно может записаться позже — повторная доставка отсечётся по ключу
+		return WriteResult{}, ctx.Err()
+	}
+}
+
+// Close дописывает накопленные снапшоты и останавливает фоновый flush.
+func (w *BatchWriter) Close(ctx context.Context) error {
+	close(w.stop)
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("flush visits: %w", ctx.Err())
+	}
+}
+
+func (w *BatchWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.flushAll()
+		case <-w.stop:
+			w.flushAll()
+			return
+		}
+	}
+}
+
+func (w *BatchWriter) flushAll() {
+	w.mu.Lock()
+	buffers := w.buffers
+	w.buffers = make(map[int64][]pendingVisit)
+	w.mu.Unlock()
+
+	for _, buf := range buffers {
+		w.flush(buf)
+	}
+}
+
+func (w *BatchWriter) flush(batch []pendingVisit) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	isus := make([]string, 0, len(batch))
+	seen := make(map[string]struct{}, len(batch))
+	for _, p := range batch {
+		if _, ok := seen[p.visit.UserID]; ok {
+			continue
+		}
+		seen[p.visit.UserID] = struct{}{}
+		isus = append(isus, p.visit.UserID)
+	}
+
+	users, err := w.repo.ListStudentsByISU(ctx, isus)
+	if err != nil {
+		failAll(batch, err)
+		return
+	}
+
+	thresholds := map[string]domain.RecognitionThresholds{}
+	if w.thresholds != nil {
+		thresholds, err = w.thresholds.ForStudents(ctx, isus)
+		if err != nil {
+			failAll(batch, err)
+			return
+		}
+	}
+
+	// неизвестные ISU и студенты без группы не пишем — это ошибка данных, а не БД
+	var accepted, review []pendingVisit
+	for _, p := range batch {
+		user, ok := users[p.visit.UserID]
+		if !ok {
+			p.result <- writeOutcome{err: fmt.Errorf("student %s: %w", p.visit.UserID, pgx.ErrNoRows)}
+			continue
+		}
+
+		switch thresholds[p.visit.UserID].Classify(p.visit.Confidence) {
+		case domain.RecognitionAccepted:
+			accepted = append(accepted, p)
+		case domain.RecognitionReview:
+			review = append(review, p)
+		default:
+			p.result <- writeOutcome{res: WriteResult{Visit: p.visit, User: user, Verdict: domain.RecognitionDiscarded}}
+		}
+	}
+
+	inserted, err := w.write(ctx, accepted, domain.RecognitionAccepted, users, w.repo.AddBatch)
+	if err != nil {
+		failAll(append(accepted, review...), err)
+		return
+	}
+	held, err := w.write(ctx, review, domain.RecognitionReview, users, w.repo.AddPendingBatch)
+	if err != nil {
+		failAll(review, err)
+		return
+	}
+
+	log.Printf("INFO: flushed %d visits (inserted=%d, pending_review=%d)", len(batch), inserted, held)
+}
+
+// write пишет снапшоты одного вердикта через add и отдаёт результат каждому ожидающему Write.
+// Возвращает, сколько снапшотов реально вставлено; при ошибке результаты не отправляются.
+func (w *BatchWriter) write(
+	ctx context.Context,
+	batch []pendingVisit,
+	verdict domain.RecognitionVerdict,
+	users map[string]domain.User,
+	add func(context.Context, []domain.ClassVisit) ([]domain.ClassVisit, error),
+) (int, error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	visits := make([]domain.ClassVisit, 0, len(batch))
+	for _, p := range batch {
+		visits = append(visits, p.visit)
+	}
+
+	inserted, err := add(ctx, visits)
+	if err != nil {
+		return 0, err
+	}
+
+	byKey := make(map[visitKey]domain.ClassVisit, len(inserted))
+	for _, v := range inserted {
+		byKey[keyOf(v)] = v
+	}
+
+	for _, p := range batch {
+		key := keyOf(p.visit)
+		v, ok := byKey[key]
+		if ok {
+			// один и тот же снапшот мог прийти в пачке дважды — новым считаем только первый
+			delete(byKey, key)
+		} else {
+			v = p.visit
+		}
+		p.result <- writeOutcome{res: WriteResult{
+			Visit:     v,
+			User:      users[p.visit.UserID],
+			Verdict:   verdict,
+			Duplicate: !ok,
+		}}
+	}
+
+	return len(inserted), nil
+}
+
+func failAll(batch []pendingVisit, err error) {
+	for _, p := range batch {
+		p.result <- writeOutcome{err: err}
+	}
+}
+
+type visitKey struct {
+	lectureID  int64
+	userID     string
+	capturedAt int64
+}
+
+func keyOf(v domain.ClassVisit) visitKey {
+	return visitKey{lectureID: v.LectureID, userID: v.UserID, capturedAt: v.CapturedAt.UnixMicro()}
+}
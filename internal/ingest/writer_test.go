@@ -0,0 +1,300 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"monitoring_backend/internal/domain"
+)
+
+// fakeVisits хранит снапшоты в памяти и, как ON CONFLICT DO NOTHING, возвращает только новые.
+type fakeVisits struct {
+	mu       sync.Mutex
+	nextID   int64
+	stored   map[visitKey]struct{}
+	students map[string]domain.User
+	batches  [][]domain.ClassVisit
+	pending  [][]domain.ClassVisit
+	err      error
+}
+
+func newFakeVisits(isus ...string) *fakeVisits {
+	students := make(map[string]domain.User, len(isus))
+	for _, isu := range isus {
+		students[isu] = domain.User{ISU: isu}
+	}
+	return &fakeVisits{stored: map[visitKey]struct{}{}, students: students}
+}
+
+func (f *fakeVisits) add(visits []domain.ClassVisit, log *[][]domain.ClassVisit) ([]domain.ClassVisit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	*log = append(*log, visits)
+
+	inserted := make([]domain.ClassVisit, 0, len(visits))
+	for _, v := range visits {
+		if _, ok := f.stored[keyOf(v)]; ok {
+			continue
+		}
+		f.stored[keyOf(v)] = struct{}{}
+		f.nextID++
+		v.ID = f.nextID
+		inserted = append(inserted, v)
+	}
+	return inserted, nil
+}
+
+func (f *fakeVisits) AddBatch(_ context.Context, visits []domain.ClassVisit) ([]domain.ClassVisit, error) {
+	return f.add(visits, &f.batches)
+}
+
+func (f *fakeVisits) AddPendingBatch(_ context.Context, visits []domain.ClassVisit) ([]domain.ClassVisit, error) {
+	return f.add(visits, &f.pending)
+}
+
+func (f *fakeVisits) ListStudentsByISU(_ context.Context, isus []string) (map[string]domain.User, error) {
+	users := make(map[string]domain.User, len(isus))
+	for _, isu := range isus {
+		if u, ok := f.students[isu]; ok {
+			users[isu] = u
+		}
+	}
+	return users, nil
+}
+
+type fixedThresholds domain.RecognitionThresholds
+
+func (t fixedThresholds) ForStudents(_ context.Context, isus []string) (map[string]domain.RecognitionThresholds, error) {
+	out := make(map[string]domain.RecognitionThresholds, len(isus))
+	for _, isu := range isus {
+		out[isu] = domain.RecognitionThresholds(t)
+	}
+	return out, nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// writeAll пишет visits параллельно, как это делают обработчики сообщений, и ждёт все результаты.
+func writeAll(t *testing.T, w *BatchWriter, visits []domain.ClassVisit) ([]WriteResult, []error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results := make([]WriteResult, len(visits))
+	errs := make([]error, len(visits))
+	var wg sync.WaitGroup
+	for i, v := range visits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = w.Write(ctx, v)
+		}()
+	}
+	wg.Wait()
+	return results, errs
+}
+
+func TestBatchWriterFlushAndDedup(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	visit := func(isu string, offset time.Duration, confidence *float64) domain.ClassVisit {
+		return domain.ClassVisit{LectureID: 1, UserID: isu, CapturedAt: at.Add(offset), Confidence: confidence}
+	}
+
+	tests := []struct {
+		name       string
+		thresholds ThresholdResolver
+		stored     []domain.ClassVisit
+		visits     []domain.ClassVisit
+		// ожидания по каждому снапшоту visits
+		verdicts   []domain.RecognitionVerdict
+		duplicates int
+		wantErr    []error
+		// сколько вызовов AddBatch и AddPendingBatch
+		batches, pending int
+	}{
+		{
+			name:     "batch is written with one query",
+			visits:   []domain.ClassVisit{visit("1", 0, nil), visit("2", 0, nil), visit("1", time.Second, nil)},
+			verdicts: []domain.RecognitionVerdict{domain.RecognitionAccepted, domain.RecognitionAccepted, domain.RecognitionAccepted},
+			wantErr:  []error{nil, nil, nil},
+			batches:  1,
+		},
+		{
+			name:       "same snapshot twice in a batch is new only once",
+			visits:     []domain.ClassVisit{visit("1", 0, nil), visit("1", 0, nil)},
+			verdicts:   []domain.RecognitionVerdict{domain.RecognitionAccepted, domain.RecognitionAccepted},
+			duplicates: 1,
+			wantErr:    []error{nil, nil},
+			batches:    1,
+		},
+		{
+			name:       "redelivered snapshot is a duplicate",
+			stored:     []domain.ClassVisit{visit("1", 0, nil)},
+			visits:     []domain.ClassVisit{visit("1", 0, nil), visit("2", 0, nil)},
+			verdicts:   []domain.RecognitionVerdict{domain.RecognitionAccepted, domain.RecognitionAccepted},
+			duplicates: 1,
+			wantErr:    []error{nil, nil},
+			batches:    1,
+		},
+		{
+			name:       "redelivery with extra nanoseconds matches stored microseconds",
+			stored:     []domain.ClassVisit{visit("1", 0, nil)},
+			visits:     []domain.ClassVisit{visit("1", 700*time.Nanosecond, nil)},
+			verdicts:   []domain.RecognitionVerdict{domain.RecognitionAccepted},
+			duplicates: 1,
+			wantErr:    []error{nil},
+			batches:    1,
+		},
+		{
+			name:     "unknown student is not written",
+			visits:   []domain.ClassVisit{visit("1", 0, nil), visit("404", 0, nil)},
+			verdicts: []domain.RecognitionVerdict{domain.RecognitionAccepted, ""},
+			wantErr:  []error{nil, pgx.ErrNoRows},
+			batches:  1,
+		},
+		{
+			name:       "snapshots are split by thresholds",
+			thresholds: fixedThresholds{Accept: 0.8, Review: 0.5},
+			visits: []domain.ClassVisit{
+				visit("1", 0, ptr(0.9)),
+				visit("2", 0, ptr(0.6)),
+				visit("3", 0, ptr(0.1)),
+				visit("4", 0, nil),
+			},
+			verdicts: []domain.RecognitionVerdict{
+				domain.RecognitionAccepted,
+				domain.RecognitionReview,
+				domain.RecognitionDiscarded,
+				domain.RecognitionReview,
+			},
+			wantErr: []error{nil, nil, nil, nil},
+			batches: 1,
+			pending: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeVisits("1", "2", "3", "4")
+			if _, err := repo.AddBatch(context.Background(), tt.stored); err != nil {
+				t.Fatal(err)
+			}
+			repo.batches = nil
+
+			// пачка набирается ровно на последнем снапшоте, таймер не должен успеть сработать
+			w := NewBatchWriter(repo, tt.thresholds, len(tt.visits), time.Hour)
+			defer w.Close(context.Background())
+
+			results, errs := writeAll(t, w, tt.visits)
+
+			duplicates := 0
+			for i := range tt.visits {
+				if !errors.Is(errs[i], tt.wantErr[i]) {
+					t.Errorf("visit %d: err = %v, want %v", i, errs[i], tt.wantErr[i])
+					continue
+				}
+				if errs[i] != nil {
+					continue
+				}
+				if results[i].Verdict != tt.verdicts[i] {
+					t.Errorf("visit %d: verdict = %q, want %q", i, results[i].Verdict, tt.verdicts[i])
+				}
+				if results[i].Duplicate {
+					duplicates++
+				} else if results[i].Verdict != domain.RecognitionDiscarded && results[i].Visit.ID == 0 {
+					t.Errorf("visit %d: new snapshot has no id", i)
+				}
+			}
+			if duplicates != tt.duplicates {
+				t.Errorf("duplicates = %d, want %d", duplicates, tt.duplicates)
+			}
+			if len(repo.batches) != tt.batches {
+				t.Errorf("AddBatch calls = %d, want %d", len(repo.batches), tt.batches)
+			}
+			if len(repo.pending) != tt.pending {
+				t.Errorf("AddPendingBatch calls = %d, want %d", len(repo.pending), tt.pending)
+			}
+		})
+	}
+}
+
+func TestBatchWriterFlushesOnInterval(t *testing.T) {
+	repo := newFakeVisits("1")
+	w := NewBatchWriter(repo, nil, 100, 10*time.Millisecond)
+	defer w.Close(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := w.Write(ctx, domain.ClassVisit{LectureID: 1, UserID: "1", CapturedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if res.Duplicate || res.Visit.ID == 0 {
+		t.Errorf("result = %+v, want new snapshot", res)
+	}
+}
+
+func TestBatchWriterCloseFlushesBuffered(t *testing.T) {
+	repo := newFakeVisits("1")
+	w := NewBatchWriter(repo, nil, 100, time.Hour)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Write(context.Background(), domain.ClassVisit{LectureID: 1, UserID: "1", CapturedAt: time.Now()})
+		done <- err
+	}()
+
+	// ждём, пока снапшот окажется в буфере, иначе Close может закончиться раньше Write
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		n := len(w.buffers[1])
+		w.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("snapshot was not buffered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(repo.batches) != 1 {
+		t.Errorf("AddBatch calls = %d, want 1", len(repo.batches))
+	}
+}
+
+func TestBatchWriterRepositoryError(t *testing.T) {
+	repo := newFakeVisits("1", "2")
+	repo.err = errors.New("db is down")
+	w := NewBatchWriter(repo, nil, 2, time.Hour)
+	defer w.Close(context.Background())
+
+	visits := []domain.ClassVisit{
+		{LectureID: 1, UserID: "1", CapturedAt: time.Now()},
+		{LectureID: 1, UserID: "2", CapturedAt: time.Now()},
+	}
+	_, errs := writeAll(t, w, visits)
+	for i, err := range errs {
+		if !errors.Is(err, repo.err) {
+			t.Errorf("visit %d: err = %v, want %v", i, err, repo.err)
+		}
+	}
+}
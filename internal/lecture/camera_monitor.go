@@ -0,0 +1,77 @@
+package lecture
+
+import (
+	"context"
+	"log"
+	"monitoring_backend/internal/domain"
+	"monitoring_backend/internal/ws"
+	"time"
+)
+
+// CameraRepository отказавшие камеры, о которых ещё не сообщили подписчикам лекций.
+type CameraRepository interface {
+	ClaimOutages(ctx context.Context, now time.Time, timeout time.Duration) ([]domain.CameraOutage, error)
+}
+
+// CameraMonitor раз в interval ищет камеры, от которых нет heartbeat дольше timeout
+// или которые сами сообщили об отказе, и рассылает camera_down подписчикам лекций в их аудиториях.
+type CameraMonitor struct {
+	cameras   CameraRepository
+	publisher Publisher
+	interval  time.Duration
+	timeout   time.Duration
+}
+
+func NewCameraMonitor(cameras CameraRepository, publisher Publisher, interval, timeout time.Duration) *CameraMonitor {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	if timeout <= 0 {
+		timeout = domain.DefaultCameraHeartbeatTimeout
+	}
+	return &CameraMonitor{
+		cameras:   cameras,
+		publisher: publisher,
+		interval:  interval,
+		timeout:   timeout,
+	}
+}
+
+// Run проверяет камеры раз в interval до отмены ctx.
+func (m *CameraMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.tick(ctx, time.Now())
+	}
+}
+
+func (m *CameraMonitor) tick(ctx context.Context, now time.Time) {
+	outages, err := m.cameras.ClaimOutages(ctx, now, m.timeout)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("ERROR: camera monitor: claim outages: %v", err)
+		}
+		return
+	}
+
+	for _, o := range outages {
+		log.Printf("WARN: camera %s is down (reported=%s, lectures=%v)", o.Status.CameraID, o.Status.Reported, o.LectureIDs)
+
+		for _, lectureID := range o.LectureIDs {
+			data, err := ws.Encode(ws.TypeCameraDown, "", ws.NewCameraDownPayload(lectureID, o.Status))
+			if err != nil {
+				log.Printf("ERROR: encode %s event (lecture_id=%d): %v", ws.TypeCameraDown, lectureID, err)
+				continue
+			}
+			m.publisher.Broadcast(lectureID, data)
+		}
+	}
+}
@@ -2,105 +2,343 @@ package lecture
 
 import (
 	"context"
-	"encoding/json"
-	"monitoring_backend/internal/http/response"
-	"net/http"
+	"errors"
+	"fmt"
+	"log"
+	"monitoring_backend/internal/domain"
 	"sync"
+	"time"
 
-	"monitoring_backend/internal/rabbit"
 	"monitoring_backend/internal/ws"
 )
 
+// SessionRepository хранит состояние запущенных консьюмеров, чтобы оно переживало рестарт API.
+type SessionRepository interface {
+	Start(ctx context.Context, s domain.ClassSession) (domain.ClassSession, error)
+	Finish(ctx context.Context, lectureID int64, state domain.ClassSessionState, stoppedBy *string) (domain.ClassSession, error)
+	ListRunning(ctx context.Context) ([]domain.ClassSession, error)
+	List(ctx context.Context, filter domain.ClassSessionFilter) ([]domain.ClassSession, int, error)
+}
+
+// Source доставляет сообщения лекции в обработку: своя очередь на лекцию
+// или routing key на общем topic exchange (см. rabbit.QueueSource, rabbit.TopicSource).
+type Source interface {
+	// DefaultRoute route лекции, если при старте он не указан; "" — route обязателен.
+	DefaultRoute(lectureID int64) string
+	// Consume блокируется до отмены ctx или конца лекции, передавая в report состояние подключения.
+	// Ошибка — консьюмер сдался (например, исчерпал попытки переподключения).
+	Consume(ctx context.Context, route string, lectureID int64, report func(domain.ConsumerHealth)) error
+	// Release вызывается после явной остановки лекции, чтобы источник освободил route.
+	Release(ctx context.Context, route string) error
+}
+
+// RouteResolver route занятия по его аудитории; "" — у аудитории нет очереди или занятие без аудитории.
+type RouteResolver interface {
+	Route(ctx context.Context, kind domain.ClassKind, classID int64) (string, error)
+}
+
+// Publisher рассылает сообщения подписчикам лекции (WebSocket).
+// Есть только у менеджера лекций: события остальных занятий не публикуются.
+type Publisher interface {
+	Broadcast(lectureID int64, data []byte)
+}
+
+// Manager управляет консьюмерами занятий одного вида (лекций, практик, лабораторных и т.д.).
+// Для занятий не-лекций lecture_id в сессиях и сообщениях — id занятия этого вида.
 type Manager struct {
 	mu sync.Mutex
+	wg sync.WaitGroup
+
+	kind    domain.ClassKind
+	running map[int64]*consumer // lecture_id → запущенный консьюмер
+	// busy занятия, которые сейчас запускаются или останавливаются: запросы к БД идут без m.mu,
+	// а второй Start или Stop того же занятия в это время получает ErrClassSessionBusy
+	busy      map[int64]struct{}
+	closed    bool
+	source    Source
+	routes    RouteResolver
+	sessions  SessionRepository
+	publisher Publisher
+}
 
-	started map[int64]bool // lecture_id → consumer started
-	running map[int64]context.CancelFunc
-	hub     *ws.Hub
-	amqpURL string
+type consumer struct {
+	sessionID int64
+	cancel    context.CancelFunc
+	health    domain.ConsumerHealth
 }
 
-func NewManager(hub *ws.Hub, amqpURL string) *Manager {
+func NewManager(kind domain.ClassKind, source Source, routes RouteResolver, sessions SessionRepository, publisher Publisher) *Manager {
 	return &Manager{
-		started: make(map[int64]bool),
-		running: make(map[int64]context.CancelFunc),
-		hub:     hub,
-		amqpURL: amqpURL,
-	}
-}
-
-// StartLecture godoc
-// @Summary Start lecture processing
-// @Description Запускает обработку очереди RabbitMQ для лекции
-// @Tags lecture
-// @Accept json
-// @Produce json
-// @Param request body StartLectureRequest true "Lecture and RabbitMQ queue"
-// @Success 202 {string} string "Consumer started"
-// @Success 200 {string} string "Consumer already running"
-// @Failure 400 {string} string "Invalid request body"
-// @Router /api/lecture/start [post]
-func (m *Manager) StartLecture(w http.ResponseWriter, r *http.Request) {
-
-	var req StartLectureRequest
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
-		return
+		kind:      kind,
+		running:   make(map[int64]*consumer),
+		busy:      make(map[int64]struct{}),
+		source:    source,
+		routes:    routes,
+		sessions:  sessions,
+		publisher: publisher,
 	}
-	if req.Queue == "" {
-		response.WriteError(w, http.StatusBadRequest, "Invalid queue name")
-		return
+}
+
+// Kind вид занятий, которыми управляет менеджер.
+func (m *Manager) Kind() domain.ClassKind {
+	return m.kind
+}
+
+// Restore поднимает консьюмеры для всех сессий, которые были активны до рестарта.
+func (m *Manager) Restore(ctx context.Context) error {
+	sessions, err := m.sessions.ListRunning(ctx)
+	if err != nil {
+		return err
 	}
 
 	m.mu.Lock()
-	if m.started[req.LectureID] {
-		m.mu.Unlock()
-		response.WriteJSON(w, http.StatusOK, "Consumer already running")
-		return
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return domain.ErrClassSessionShuttingDown
+	}
+
+	for _, s := range sessions {
+		if _, ok := m.running[s.LectureID]; ok {
+			continue
+		}
+		m.run(s)
+		log.Printf("INFO: restored %s consumer (id=%d queue=%s)", m.kind, s.LectureID, s.Queue)
 	}
-	m.started[req.LectureID] = true
 
+	return nil
+}
+
+// run запускает консьюмер сессии. Вызывается под m.mu.
+func (m *Manager) run(s domain.ClassSession) {
 	ctx, cancel := context.WithCancel(context.Background())
-	m.running[req.LectureID] = cancel
+	c := &consumer{sessionID: s.ID, cancel: cancel}
+	m.running[s.LectureID] = c
 
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		report := func(h domain.ConsumerHealth) { m.reportHealth(s.LectureID, c, h) }
+
+		state := domain.ClassSessionFinished
+		if err := m.source.Consume(ctx, s.Queue, s.LectureID, report); err != nil {
+			log.Printf("ERROR: consume %s %d (route=%s): %v", m.kind, s.LectureID, s.Queue, err)
+			state = domain.ClassSessionFailed
+		}
+
+		m.mu.Lock()
+		if m.running[s.LectureID] == c {
+			delete(m.running, s.LectureID)
+		}
+		m.mu.Unlock()
+
+		// контекст не отменяли — консьюмер завершился сам: конец лекции или не смог переподключиться.
+		// При остановке через API или Shutdown сессию не трогаем здесь.
+		if ctx.Err() != nil {
+			return
+		}
+		cancel()
+
+		finishCtx, finishCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer finishCancel()
+
+		finished, err := m.sessions.Finish(finishCtx, s.LectureID, state, nil)
+		if err != nil {
+			if !errors.Is(err, domain.ErrClassSessionNotFound) {
+				log.Printf("ERROR: finish %s session (id=%d): %v", m.kind, s.LectureID, err)
+			}
+			return
+		}
+		m.publishState(finished)
+	}()
+}
+
+// reportHealth запоминает состояние подключения консьюмера и рассылает его подписчикам лекции.
+func (m *Manager) reportHealth(lectureID int64, c *consumer, h domain.ConsumerHealth) {
+	m.mu.Lock()
+	if m.running[lectureID] != c {
+		m.mu.Unlock()
+		return
+	}
+	c.health = h
 	m.mu.Unlock()
 
-	go rabbit.StartConsumer(ctx, m.amqpURL, req.Queue, req.LectureID, m.hub)
+	m.publish(lectureID, ws.TypeConsumerStatus, ws.NewConsumerStatusPayload(lectureID, h))
+}
+
+// publishState сообщает подписчикам лекции о смене состояния сессии.
+func (m *Manager) publishState(s domain.ClassSession) {
+	msgType := ws.TypeLectureEnded
+	at := time.Now()
+	if s.State == domain.ClassSessionRunning {
+		msgType = ws.TypeLectureStarted
+		at = s.StartedAt
+	} else if s.StoppedAt != nil {
+		at = *s.StoppedAt
+	}
 
-	response.WriteJSON(w, http.StatusOK, "Consumer started")
+	m.publish(s.LectureID, msgType, ws.LectureStatePayload{
+		LectureID: s.LectureID,
+		SessionID: s.ID,
+		State:     string(s.State),
+		At:        at,
+	})
 }
 
-// StopLecture godoc
-// @Summary Stop lecture processing
-// @Description Stops RabbitMQ consumer and WebSocket broadcasting for the specified lecture.
-// @Tags lecture
-// @Accept json
-// @Produce json
-// @Param request body StopLectureRequest true "Lecture identifier"
-// @Success 200 {string} string "ok"
-// @Failure 400 {object} map[string]string "Invalid request payload"
-// @Failure 404 {object} map[string]string "Lecture not found"
-// @Router /api/lecture/stop [post]
-func (m *Manager) StopLecture(w http.ResponseWriter, r *http.Request) {
-	var req StopLectureRequest
+func (m *Manager) publish(lectureID int64, msgType string, payload any) {
+	if m.publisher == nil {
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.WriteError(w, http.StatusBadRequest, "Invalid request payload")
+	data, err := ws.Encode(msgType, "", payload)
+	if err != nil {
+		log.Printf("ERROR: encode %s event (lecture_id=%d): %v", msgType, lectureID, err)
 		return
 	}
+	m.publisher.Broadcast(lectureID, data)
+}
 
+// Shutdown отменяет контексты всех консьюмеров и ждёт, пока они дообработают текущее сообщение.
+// Сессии остаются в состоянии running, чтобы после рестарта Restore поднял их снова.
+func (m *Manager) Shutdown(ctx context.Context) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.closed = true
+	for lectureID, c := range m.running {
+		c.cancel()
+		delete(m.running, lectureID)
+	}
+	m.mu.Unlock()
 
-	if _, ok := m.running[req.LectureID]; !ok {
-		response.WriteError(w, http.StatusNotFound, "Lecture not found")
-		return
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait %s consumers: %w", m.kind, ctx.Err())
 	}
+}
+
+// Start сохраняет сессию и запускает консьюмер занятия id. Пустой route — очередь аудитории занятия,
+// а без неё — route источника по умолчанию.
+// Если консьюмер уже запущен, возвращает domain.ErrClassSessionAlreadyRunning,
+// если занятие в этот момент запускается или останавливается — domain.ErrClassSessionBusy.
+func (m *Manager) Start(ctx context.Context, id int64, route string, startedBy *string) (domain.ClassSession, error) {
+	if route == "" {
+		var err error
+		if route, err = m.routes.Route(ctx, m.kind, id); err != nil {
+			return domain.ClassSession{}, err
+		}
+	}
+	if route == "" {
+		route = m.source.DefaultRoute(id)
+	}
+	if route == "" {
+		return domain.ClassSession{}, domain.ErrClassSessionRouteRequired
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return domain.ClassSession{}, domain.ErrClassSessionShuttingDown
+	}
+	if _, ok := m.running[id]; ok {
+		m.mu.Unlock()
+		return domain.ClassSession{}, domain.ErrClassSessionAlreadyRunning
+	}
+	if !m.acquire(id) {
+		m.mu.Unlock()
+		return domain.ClassSession{}, domain.ErrClassSessionBusy
+	}
+	m.mu.Unlock()
+	defer m.release(id)
+
+	session, err := m.sessions.Start(ctx, domain.ClassSession{
+		LectureID: id,
+		Queue:     route,
+		StartedAt: time.Now(),
+		StartedBy: startedBy,
+	})
+	if err != nil {
+		return domain.ClassSession{}, err
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		// сессия уже сохранена как running — после рестарта её поднимет Restore
+		m.mu.Unlock()
+		return domain.ClassSession{}, domain.ErrClassSessionShuttingDown
+	}
+	m.run(session)
+	m.mu.Unlock()
+
+	m.publishState(session)
+
+	return session, nil
+}
 
-	m.running[req.LectureID]()
-	delete(m.running, req.LectureID)
-	delete(m.started, req.LectureID)
+// Stop останавливает консьюмер занятия id и завершает его сессию с состоянием state.
+// stoppedBy nil — остановлено не пользователем (например, по расписанию).
+func (m *Manager) Stop(ctx context.Context, id int64, state domain.ClassSessionState, stoppedBy *string) (domain.ClassSession, error) {
+	m.mu.Lock()
+	if !m.acquire(id) {
+		m.mu.Unlock()
+		return domain.ClassSession{}, domain.ErrClassSessionBusy
+	}
+	if c, ok := m.running[id]; ok {
+		c.cancel()
+		delete(m.running, id)
+	}
+	m.mu.Unlock()
+	defer m.release(id)
+
+	session, err := m.sessions.Finish(ctx, id, state, stoppedBy)
+	if err != nil {
+		return domain.ClassSession{}, err
+	}
+
+	if err := m.source.Release(ctx, session.Queue); err != nil {
+		log.Printf("WARN: release route %s (%s id=%d): %v", session.Queue, m.kind, id, err)
+	}
+	m.publishState(session)
+
+	return session, nil
+}
+
+// acquire помечает занятие id как запускаемое или останавливаемое; false — им уже занят другой запрос.
+// Вызывается под m.mu.
+func (m *Manager) acquire(id int64) bool {
+	if _, ok := m.busy[id]; ok {
+		return false
+	}
+	m.busy[id] = struct{}{}
+	return true
+}
+
+func (m *Manager) release(id int64) {
+	m.mu.Lock()
+	delete(m.busy, id)
+	m.mu.Unlock()
+}
 
-	response.WriteJSON(w, http.StatusOK, "ok")
+// ListSessions сессии занятий этого вида по фильтру (новые сверху) и их общее число.
+func (m *Manager) ListSessions(ctx context.Context, filter domain.ClassSessionFilter) ([]domain.ClassSession, int, error) {
+	return m.sessions.List(ctx, filter)
+}
+
+// ConsumersHealth состояние подключения запущенных консьюмеров по id сессии.
+func (m *Manager) ConsumersHealth() map[int64]domain.ConsumerHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[int64]domain.ConsumerHealth, len(m.running))
+	for _, c := range m.running {
+		out[c.sessionID] = c.health
+	}
+	return out
 }
@@ -0,0 +1,56 @@
+package lecture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monitoring_backend/internal/domain"
+)
+
+// Registry менеджеры консьюмеров по видам занятий. Через него работают эндпоинты мониторинга
+// /api/classes/{kind}/... и планировщик, а приложение поднимает и останавливает все менеджеры разом.
+type Registry struct {
+	managers map[domain.ClassKind]*Manager
+}
+
+func NewRegistry(managers ...*Manager) *Registry {
+	out := make(map[domain.ClassKind]*Manager, len(managers))
+	for _, m := range managers {
+		out[m.Kind()] = m
+	}
+	return &Registry{managers: out}
+}
+
+// Manager менеджер занятий вида kind, nil — если такой вид не обслуживается.
+func (reg *Registry) Manager(kind domain.ClassKind) *Manager {
+	return reg.managers[kind]
+}
+
+// Restore поднимает консьюмеры, активные до рестарта, у всех менеджеров.
+func (reg *Registry) Restore(ctx context.Context) error {
+	for _, kind := range domain.ClassKinds {
+		m, ok := reg.managers[kind]
+		if !ok {
+			continue
+		}
+		if err := m.Restore(ctx); err != nil {
+			return fmt.Errorf("restore %s sessions: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+// Shutdown останавливает консьюмеры всех менеджеров, сессии остаются running.
+func (reg *Registry) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, kind := range domain.ClassKinds {
+		m, ok := reg.managers[kind]
+		if !ok {
+			continue
+		}
+		if err := m.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
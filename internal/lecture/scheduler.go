@@ -0,0 +1,139 @@
+package lecture
+
+import (
+	"context"
+	"errors"
+	"log"
+	"monitoring_backend/internal/domain"
+	"time"
+)
+
+// ScheduleRepository занятия и сессии, которые планировщику пора запустить или остановить.
+type ScheduleRepository interface {
+	ListDueToStart(ctx context.Context, now time.Time, lead time.Duration) ([]domain.Class, error)
+	ListDueToStop(ctx context.Context, now time.Time, grace time.Duration) ([]domain.ClassSession, error)
+	AddAudit(ctx context.Context, e domain.ScheduleAuditEntry) error
+}
+
+// Scheduler запускает консьюмеры занятий за lead до начала и останавливает через grace после конца.
+// Очередь берётся из аудитории занятия, как и при ручном старте без очереди.
+// Внутри окна занятие перезапускается, если его сессия упала или закончилась сама; остановленное
+// пользователем занятие планировщик не поднимает снова. Занятия в режиме manual он не трогает совсем.
+type Scheduler struct {
+	managers *Registry
+	schedule ScheduleRepository
+	interval time.Duration
+	lead     time.Duration
+	grace    time.Duration
+}
+
+func NewScheduler(managers *Registry, schedule ScheduleRepository, interval, lead, grace time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Scheduler{
+		managers: managers,
+		schedule: schedule,
+		interval: interval,
+		lead:     lead,
+		grace:    grace,
+	}
+}
+
+// Run проверяет расписание раз в interval до отмены ctx.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context, now time.Time) {
+	sessions, err := s.schedule.ListDueToStop(ctx, now, s.grace)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("ERROR: scheduler: list sessions to stop: %v", err)
+		}
+		return
+	}
+	for _, session := range sessions {
+		s.stop(ctx, session)
+	}
+
+	classes, err := s.schedule.ListDueToStart(ctx, now, s.lead)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("ERROR: scheduler: list classes to start: %v", err)
+		}
+		return
+	}
+	for _, c := range classes {
+		s.start(ctx, c)
+	}
+}
+
+func (s *Scheduler) start(ctx context.Context, c domain.Class) {
+	m := s.managers.Manager(c.Kind)
+	if m == nil {
+		return
+	}
+
+	session, err := m.Start(ctx, c.ID, "", nil)
+	switch {
+	case errors.Is(err, domain.ErrClassSessionAlreadyRunning), errors.Is(err, domain.ErrClassSessionShuttingDown),
+		errors.Is(err, domain.ErrClassSessionBusy):
+		return
+	case errors.Is(err, domain.ErrClassSessionRouteRequired):
+		log.Printf("WARN: scheduler: no queue for %s id=%d (room %q), start it manually", c.Kind, c.ID, c.Room)
+		return
+	case err != nil:
+		log.Printf("ERROR: scheduler: start %s id=%d: %v", c.Kind, c.ID, err)
+		return
+	}
+
+	log.Printf("INFO: scheduler: started %s id=%d (route=%s)", c.Kind, c.ID, session.Queue)
+	s.audit(ctx, c.Kind, c.ID, domain.ScheduleActionAutoStart)
+}
+
+func (s *Scheduler) stop(ctx context.Context, session domain.ClassSession) {
+	m := s.managers.Manager(session.Kind)
+	if m == nil {
+		return
+	}
+
+	_, err := m.Stop(ctx, session.LectureID, domain.ClassSessionFinished, nil)
+	if errors.Is(err, domain.ErrClassSessionNotFound) {
+		// сессию успели остановить вручную
+		return
+	}
+	if errors.Is(err, domain.ErrClassSessionBusy) {
+		// занятие сейчас останавливают вручную, иначе остановим на следующем тике
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: scheduler: stop %s id=%d: %v", session.Kind, session.LectureID, err)
+		return
+	}
+
+	log.Printf("INFO: scheduler: stopped %s id=%d", session.Kind, session.LectureID)
+	s.audit(ctx, session.Kind, session.LectureID, domain.ScheduleActionAutoStop)
+}
+
+func (s *Scheduler) audit(ctx context.Context, kind domain.ClassKind, classID int64, action domain.ScheduleAction) {
+	err := s.schedule.AddAudit(ctx, domain.ScheduleAuditEntry{
+		Kind:    kind,
+		ClassID: classID,
+		Action:  action,
+	})
+	if err != nil {
+		log.Printf("ERROR: scheduler: audit %s (%s id=%d): %v", action, kind, classID, err)
+	}
+}
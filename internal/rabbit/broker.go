@@ -0,0 +1,60 @@
+package rabbit
+
+import (
+	"errors"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"monitoring_backend/internal/config"
+)
+
+var ErrBrokerClosed = errors.New("rabbit broker is closed")
+
+// Broker одно AMQP-соединение на весь процесс. Каждый консьюмер берёт из него свой канал,
+// а после обрыва соединения первый же Channel переподключается, остальные переиспользуют его.
+type Broker struct {
+	url string
+
+	mu     sync.Mutex
+	conn   *amqp.Connection
+	closed bool
+}
+
+func NewBroker(cfg config.RabbitConfig) *Broker {
+	return &Broker{url: cfg.AMPQURL}
+}
+
+// Channel открывает новый канал на общем соединении. Закрывать канал — забота вызывающего.
+func (b *Broker) Channel() (*amqp.Channel, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	if b.conn == nil || b.conn.IsClosed() {
+		conn, err := amqp.Dial(b.url)
+		if err != nil {
+			return nil, err
+		}
+		b.conn = conn
+	}
+
+	// ошибка открытия канала (например, исчерпан channel_max) не значит, что соединение мертво:
+	// общее соединение не трогаем, умершее переподключится по IsClosed при следующем вызове
+	return b.conn.Channel()
+}
+
+// Close закрывает соединение. Вызывать после остановки всех консьюмеров.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	if b.conn == nil || b.conn.IsClosed() {
+		return nil
+	}
+	return b.conn.Close()
+}
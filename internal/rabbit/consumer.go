@@ -2,69 +2,170 @@ package rabbit
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 
-	"monitoring_backend/internal/ws"
+	"monitoring_backend/internal/config"
+	"monitoring_backend/internal/domain"
 )
 
-// StartConsumer читает очередь queue из RabbitMQ и рассылает сообщения всем WS-клиентам lecture_id.
-// Реализован reconnect loop: если RabbitMQ временно недоступен — переподключаемся.
-func StartConsumer(ctx context.Context, amqpURL string, queue string, lectureID int64, hub *ws.Hub) {
-	backoff := 1 * time.Second
-	maxBackoff := 20 * time.Second
-	connectCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+const (
+	prefetchCount = 50
+	// handleTimeout ограничивает обработку одного сообщения. Контекст консьюмера сюда
+	// не передаётся, чтобы при остановке текущее сообщение дообработалось до конца.
+	handleTimeout = 60 * time.Second
+	// requeueDelay пауза перед возвратом сообщения в очередь после временной ошибки,
+	// чтобы при недоступной БД не крутить одно и то же сообщение в горячем цикле.
+	requeueDelay = time.Second
+	// defaultMaxRetries повторов временной ошибки до отправки сообщения в dead-letter.
+	defaultMaxRetries = 5
+)
 
-	for {
+// Заголовки, которые консьюмер добавляет к сообщениям при повторе и отправке в dead-letter.
+const (
+	headerRetryCount  = "x-retry-count"
+	headerRoute       = "x-route"
+	headerReason      = "x-reject-reason"
+	headerError       = "x-reject-error"
+	headerSourceQueue = "x-source-queue"
+	headerLectureID   = "x-lecture-id"
+	headerKind        = "x-class-kind"
+	headerFailedAt    = "x-failed-at"
+	// headerReceivedAt когда сообщение впервые получено из очереди, unix-микросекунды:
+	// у копий при повторе и в dead-letter время публикации уже другое
+	headerReceivedAt = "x-received-at"
+)
+
+const (
+	// reasonRetriesExhausted причина для сообщений, которые не удалось записать за MaxRetries попыток.
+	reasonRetriesExhausted = "retries_exhausted"
+	// reasonNoSubscriber сообщение пришло в общую очередь для лекции, которая сейчас не запущена.
+	reasonNoSubscriber = "no_subscriber"
+)
+
+// StartConsumer читает очередь queue из RabbitMQ и передаёт сообщения занятия lectureID вида kind в handler.
+// Канал берётся на общем соединении broker. При обрыве переподключается по cfg.Reconnect,
+// изменения состояния подключения передаются в report (может быть nil).
+// Возвращает nil при отмене ctx или конце лекции, ErrReconnectFailed — если попытки исчерпаны.
+func StartConsumer(ctx context.Context, broker *Broker, cfg config.RabbitConfig, kind domain.ClassKind, queue string, lectureID int64, handler Handler, report func(domain.ConsumerHealth)) error {
+	name := fmt.Sprintf("rabbit consumer (%s_id=%d queue=%s)", kind, lectureID, queue)
+
+	err := runWithReconnect(ctx, newReconnectPolicy(cfg.Reconnect), name, report, func(ready func()) error {
+		return consumeQueue(ctx, broker, cfg, kind, queue, lectureID, handler, ready)
+	})
+	if err == nil {
+		log.Printf("%s stopped", name)
+	}
+
+	return err
+}
+
+func consumeQueue(ctx context.Context, broker *Broker, cfg config.RabbitConfig, kind domain.ClassKind, queue string, lectureID int64, handler Handler, ready func()) error {
+	ch, err := broker.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := declareDeadLetter(ch, cfg); err != nil {
+		return err
+	}
+
+	ended := make(chan struct{})
+	var endOnce sync.Once
+
+	d := newDispatcher(ch, cfg, kind, queue, handler, func(int64) {
+		endOnce.Do(func() { close(ended) })
+	})
+
+	err = d.consume(ctx, ended, ready, func(amqp.Delivery) (int64, bool) {
+		return lectureID, true
+	})
+	if err == nil {
 		select {
-		case <-connectCtx.Done():
-			return
+		case <-ended:
+			log.Printf("%s %d is end", kind, lectureID)
 		default:
 		}
+	}
 
-		err := consumeOnce(ctx, amqpURL, queue, lectureID, hub)
-		if err == nil {
-			log.Printf("rabbit consumer stopped (lecture_id=%d queue=%s)", lectureID, queue)
-			return
+	return err
+}
+
+// declareDeadLetter объявляет dead-letter exchange и очередь и связывает их.
+// Объявление идемпотентно, поэтому его делает каждый консьюмер при подключении.
+func declareDeadLetter(ch *amqp.Channel, cfg config.RabbitConfig) error {
+	if cfg.DeadLetterExchange != "" {
+		if err := ch.ExchangeDeclare(cfg.DeadLetterExchange, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+			return err
 		}
+	}
 
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(backoff):
+	if cfg.DeadLetterQueue != "" {
+		if _, err := ch.QueueDeclare(cfg.DeadLetterQueue, true, false, false, false, nil); err != nil {
+			return err
 		}
+	}
 
-		backoff *= 2
-		if backoff > maxBackoff {
-			backoff = maxBackoff
+	if cfg.DeadLetterExchange != "" && cfg.DeadLetterQueue != "" {
+		if err := ch.QueueBind(cfg.DeadLetterQueue, "", cfg.DeadLetterExchange, false, nil); err != nil {
+			return err
 		}
 	}
+
+	return nil
 }
 
-func consumeOnce(ctx context.Context, amqpURL string, queue string, lectureID int64, hub *ws.Hub) error {
-	conn, err := amqp.Dial(amqpURL)
-	if err != nil {
-		return err
+// dispatcher передаёт сообщения одного канала в handler и подтверждает их по результату.
+type dispatcher struct {
+	ch                 *amqp.Channel
+	kind               domain.ClassKind
+	queue              string
+	deadLetterExchange string
+	deadLetterQueue    string
+	maxRetries         int
+	handler            Handler
+
+	// onEnd вызывается, когда handler сообщил о конце лекции
+	onEnd func(lectureID int64)
+}
+
+func newDispatcher(ch *amqp.Channel, cfg config.RabbitConfig, kind domain.ClassKind, queue string, handler Handler, onEnd func(lectureID int64)) *dispatcher {
+	maxRetries := cfg.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
 	}
-	defer conn.Close()
 
-	ch, err := conn.Channel()
-	if err != nil {
-		return err
+	return &dispatcher{
+		ch:                 ch,
+		kind:               kind,
+		queue:              queue,
+		deadLetterExchange: cfg.DeadLetterExchange,
+		deadLetterQueue:    cfg.DeadLetterQueue,
+		maxRetries:         maxRetries,
+		handler:            handler,
+		onEnd:              onEnd,
 	}
-	defer ch.Close()
+}
 
-	// QoS: чтобы не заливать память при ручных ack
-	if err := ch.Qos(50, 0, false); err != nil {
+// consume читает очередь d.queue до отмены ctx, закрытия stop или обрыва канала.
+// ready вызывается, когда подписка на очередь оформлена.
+// lectureOf определяет лекцию сообщения; false — у сообщения нет получателя.
+// nil возвращается только при штатной остановке.
+func (d *dispatcher) consume(ctx context.Context, stop <-chan struct{}, ready func(), lectureOf func(amqp.Delivery) (int64, bool)) error {
+	// QoS: чтобы не заливать память при ручных ack.
+	// Он же ограничивает число сообщений, обрабатываемых параллельно.
+	if err := d.ch.Qos(prefetchCount, 0, false); err != nil {
 		return err
 	}
 
-	msgs, err := ch.Consume(
-		queue,
+	msgs, err := d.ch.Consume(
+		d.queue,
 		"",    // consumer tag
 		false, // autoAck = false (делаем ack сами)
 		false, // exclusive
@@ -76,55 +177,215 @@ func consumeOnce(ctx context.Context, amqpURL string, queue string, lectureID in
 		return err
 	}
 
-	// Отслеживаем закрытие соединения/канала
-	conn_closed := make(chan *amqp.Error, 1)
-	ch_closed := make(chan *amqp.Error, 1)
-	conn.NotifyClose(conn_closed)
-	ch.NotifyClose(ch_closed)
+	// Канал закрывается и при обрыве общего соединения
+	closed := d.ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	ready()
+
+	// Сообщения обрабатываются параллельно, чтобы writer мог собрать их в пачку.
+	// Перед закрытием канала дожидаемся ack/nack всех сообщений в обработке.
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-stop:
+			return nil
 		case msg, ok := <-msgs:
 			if !ok {
 				return amqp.ErrClosed
 			}
 
-			if isLectureEnd(msg.Body) {
-				_ = msg.Ack(false)
-				log.Printf("lecture %d is end", lectureID)
-				return nil
+			lectureID, ok := lectureOf(msg)
+			if !ok {
+				inFlight.Add(1)
+				go func() {
+					defer inFlight.Done()
+					d.noSubscriber(ctx, msg)
+				}()
+				continue
 			}
 
-			// Здесь можно парсить JSON, валидировать, писать в БД.
-			// Для минимального рабочего варианта — просто транслируем body как есть.
-			hub.Broadcast(lectureID, msg.Body)
-
-			// Ack после успешной передачи в Hub (передача асинхронная, но это достаточный критерий для учебного проекта)
-			_ = msg.Ack(false)
+			inFlight.Add(1)
+			go func() {
+				defer inFlight.Done()
+				d.handle(ctx, msg, lectureID)
+			}()
 
-		case err := <-conn_closed:
+		case err := <-closed:
 			if err != nil {
 				return err
 			}
 			return amqp.ErrClosed
+		}
+	}
+}
 
-		case err := <-ch_closed:
-			if err != nil {
-				return err
-			}
-			return amqp.ErrClosed
+// handle передаёт сообщение в handler и подтверждает его по результату обработки:
+// ack после успешной записи, dead-letter для битых сообщений, повтор для временных ошибок.
+func (d *dispatcher) handle(ctx context.Context, msg amqp.Delivery, lectureID int64) {
+	handleCtx, cancel := context.WithTimeout(context.Background(), handleTimeout)
+	defer cancel()
+
+	// время первого получения уходит и в копии сообщения: повтор должен дать тот же снапшот
+	receivedAt := firstReceivedAt(msg)
+	msg.Headers = copyHeaders(msg.Headers)
+	msg.Headers[headerReceivedAt] = receivedAt.UnixMicro()
+
+	err := d.handler.Handle(WithReceivedAt(handleCtx, receivedAt), lectureID, msg.Body)
+	switch {
+	case err == nil:
+		_ = msg.Ack(false)
+
+	case errors.Is(err, ErrLectureEnd):
+		_ = msg.Ack(false)
+		d.onEnd(lectureID)
+
+	case errors.Is(err, ErrReject):
+		log.Printf("WARN: rejected message (lecture_id=%d): %v", lectureID, err)
+		d.deadLetter(ctx, handleCtx, msg, lectureID, Reason(err, "rejected"), err)
+
+	default:
+		retries := retryCount(msg)
+		if retries >= d.maxRetries {
+			log.Printf("ERROR: handle message (lecture_id=%d), %d retries exhausted: %v", lectureID, retries, err)
+			d.deadLetter(ctx, handleCtx, msg, lectureID, reasonRetriesExhausted, err)
+			return
 		}
+
+		log.Printf("ERROR: handle message (lecture_id=%d), retry %d/%d: %v", lectureID, retries+1, d.maxRetries, err)
+		d.retry(ctx, handleCtx, msg, retries+1)
+	}
+}
+
+// noSubscriber обрабатывает сообщение, для маршрута которого нет запущенной лекции.
+// Маршрут мог ещё не зарегистрироваться (старт сервиса, восстановление сессий), поэтому сообщение
+// сначала повторяется как при временной ошибке и уходит в dead-letter только после MaxRetries попыток.
+func (d *dispatcher) noSubscriber(ctx context.Context, msg amqp.Delivery) {
+	publishCtx, cancel := context.WithTimeout(context.Background(), handleTimeout)
+	defer cancel()
+
+	retries := retryCount(msg)
+	if retries >= d.maxRetries {
+		log.Printf("WARN: no running lecture for message (queue=%s route=%s), %d retries exhausted", d.queue, routeOf(msg), retries)
+		d.deadLetter(ctx, publishCtx, msg, 0, reasonNoSubscriber, errors.New("no running lecture for route "+routeOf(msg)))
+		return
+	}
+
+	d.retry(ctx, publishCtx, msg, retries+1)
+}
+
+// retry публикует копию сообщения в конец исходной очереди с увеличенным счётчиком попыток
+// и ack-ает оригинал. Если публикация не удалась — обычный nack с requeue.
+func (d *dispatcher) retry(ctx, publishCtx context.Context, msg amqp.Delivery, attempt int) {
+	select {
+	case <-ctx.Done():
+		// консьюмер останавливается — вернём сообщение как есть, счётчик не тратим
+		_ = msg.Nack(false, true)
+		return
+	case <-time.After(requeueDelay):
+	}
+
+	headers := copyHeaders(msg.Headers)
+	headers[headerRetryCount] = int32(attempt)
+	// копия идёт в очередь напрямую, мимо exchange — сохраняем исходный routing key
+	headers[headerRoute] = routeOf(msg)
+
+	if err := d.publish(publishCtx, "", d.queue, msg, headers); err != nil {
+		log.Printf("ERROR: republish message to %s, requeue: %v", d.queue, err)
+		_ = msg.Nack(false, true)
+		return
+	}
+	_ = msg.Ack(false)
+}
+
+func (d *dispatcher) requeue(ctx context.Context, msg amqp.Delivery) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(requeueDelay):
+	}
+	_ = msg.Nack(false, true)
+}
+
+// deadLetter публикует исходное сообщение в dead-letter exchange (или напрямую в очередь)
+// с причиной отказа в заголовках и ack-ает оригинал. lectureID 0 — лекция неизвестна.
+func (d *dispatcher) deadLetter(ctx, publishCtx context.Context, msg amqp.Delivery, lectureID int64, reason string, cause error) {
+	exchange, key := d.deadLetterExchange, d.queue
+	if exchange == "" {
+		key = d.deadLetterQueue
+	}
+	if key == "" {
+		_ = msg.Nack(false, false)
+		return
+	}
+
+	headers := copyHeaders(msg.Headers)
+	headers[headerReason] = reason
+	headers[headerError] = cause.Error()
+	headers[headerSourceQueue] = d.queue
+	headers[headerRoute] = routeOf(msg)
+	headers[headerFailedAt] = time.Now().UTC()
+	headers[headerKind] = string(d.kind)
+	if lectureID > 0 {
+		headers[headerLectureID] = lectureID
+	}
+
+	if err := d.publish(publishCtx, exchange, key, msg, headers); err != nil {
+		log.Printf("ERROR: publish to dead-letter (exchange=%q key=%s lecture_id=%d), requeue: %v", exchange, key, lectureID, err)
+		d.requeue(ctx, msg)
+		return
+	}
+	_ = msg.Ack(false)
+}
+
+func (d *dispatcher) publish(ctx context.Context, exchange, key string, msg amqp.Delivery, headers amqp.Table) error {
+	return d.ch.PublishWithContext(ctx, exchange, key, false, false, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  msg.ContentType,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         msg.Body,
+	})
+}
+
+func copyHeaders(src amqp.Table) amqp.Table {
+	headers := make(amqp.Table, len(src)+7)
+	for k, v := range src {
+		headers[k] = v
 	}
+	return headers
 }
 
-func isLectureEnd(body []byte) bool {
-	var msg struct {
-		End bool `json:"end"`
+// retryCount число уже сделанных повторов сообщения.
+func retryCount(msg amqp.Delivery) int {
+	switch v := msg.Headers[headerRetryCount].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
 	}
-	if err := json.Unmarshal(body, &msg); err != nil {
-		return false
+	return 0
+}
+
+// firstReceivedAt время из заголовка повторённой копии, иначе timestamp издателя, иначе текущее.
+func firstReceivedAt(msg amqp.Delivery) time.Time {
+	if t, ok := ReceivedAtHeader(msg.Headers); ok {
+		return t
+	}
+	if !msg.Timestamp.IsZero() {
+		return msg.Timestamp
+	}
+	return time.Now()
+}
+
+// routeOf routing key, с которым сообщение изначально пришло в exchange.
+func routeOf(msg amqp.Delivery) string {
+	if route, ok := msg.Headers[headerRoute].(string); ok && route != "" {
+		return route
 	}
-	return msg.End
+	return msg.RoutingKey
 }
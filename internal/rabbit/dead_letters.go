@@ -0,0 +1,145 @@
+package rabbit
+
+import (
+	"context"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"monitoring_backend/internal/config"
+	"monitoring_backend/internal/domain"
+)
+
+// archivePrefetch сообщений dead-letter queue немного, пишем их по одному.
+const archivePrefetch = 10
+
+// DeadLetterStore сохраняет сообщения из dead-letter queue, чтобы их можно было
+// просмотреть и переиграть через API.
+type DeadLetterStore interface {
+	Add(ctx context.Context, dl domain.DeadLetter) (int64, error)
+}
+
+// StartDeadLetterArchiver читает cfg.DeadLetterQueue и переносит сообщения в store.
+// Сообщение ack-ается только после сохранения. Работает до отмены ctx.
+func StartDeadLetterArchiver(ctx context.Context, broker *Broker, cfg config.RabbitConfig, store DeadLetterStore) {
+	if cfg.DeadLetterQueue == "" {
+		return
+	}
+
+	// архиватор не сдаётся: без него dead-letter queue просто копится в RabbitMQ
+	policy := newReconnectPolicy(cfg.Reconnect)
+	policy.maxAttempts = 0
+
+	_ = runWithReconnect(ctx, policy, "dead-letter archiver", nil, func(ready func()) error {
+		return archiveOnce(ctx, broker, cfg, store, ready)
+	})
+}
+
+func archiveOnce(ctx context.Context, broker *Broker, cfg config.RabbitConfig, store DeadLetterStore, ready func()) error {
+	ch, err := broker.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.Qos(archivePrefetch, 0, false); err != nil {
+		return err
+	}
+
+	if err := declareDeadLetter(ch, cfg); err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(cfg.DeadLetterQueue, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+	ready()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case msg, ok := <-msgs:
+			if !ok {
+				return amqp.ErrClosed
+			}
+
+			saveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			id, err := store.Add(saveCtx, deadLetterFromDelivery(msg))
+			cancel()
+
+			if err != nil {
+				log.Printf("ERROR: archive dead letter, requeue: %v", err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(requeueDelay):
+				}
+				_ = msg.Nack(false, true)
+				continue
+			}
+
+			_ = msg.Ack(false)
+			log.Printf("INFO: archived dead letter %d (reason=%v queue=%v)", id, msg.Headers[headerReason], msg.Headers[headerSourceQueue])
+
+		case err := <-closed:
+			if err != nil {
+				return err
+			}
+			return amqp.ErrClosed
+		}
+	}
+}
+
+func deadLetterFromDelivery(msg amqp.Delivery) domain.DeadLetter {
+	dl := domain.DeadLetter{
+		SourceQueue: stringHeader(msg.Headers, headerSourceQueue),
+		Kind:        domain.ClassKind(stringHeader(msg.Headers, headerKind)),
+		Reason:      stringHeader(msg.Headers, headerReason),
+		Error:       stringHeader(msg.Headers, headerError),
+		Headers:     map[string]any(msg.Headers),
+		Body:        msg.Body,
+		FailedAt:    msg.Timestamp,
+	}
+
+	if dl.SourceQueue == "" {
+		dl.SourceQueue = msg.RoutingKey
+	}
+	if dl.Reason == "" {
+		dl.Reason = "unknown"
+	}
+	// сообщения, упавшие до появления практик, без заголовка вида
+	if dl.Kind == "" {
+		dl.Kind = domain.ClassLecture
+	}
+	if msg.ContentType != "" {
+		contentType := msg.ContentType
+		dl.ContentType = &contentType
+	}
+
+	switch v := msg.Headers[headerLectureID].(type) {
+	case int64:
+		dl.LectureID = &v
+	case int32:
+		id := int64(v)
+		dl.LectureID = &id
+	}
+
+	if t, ok := msg.Headers[headerFailedAt].(time.Time); ok {
+		dl.FailedAt = t
+	}
+	if dl.FailedAt.IsZero() {
+		dl.FailedAt = time.Now()
+	}
+
+	return dl
+}
+
+func stringHeader(headers amqp.Table, key string) string {
+	s, _ := headers[key].(string)
+	return s
+}
@@ -0,0 +1,77 @@
+package rabbit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrReject помечает сообщение, которое бессмысленно обрабатывать повторно
+// (битый JSON, неизвестный студент и т.п.). Такое сообщение публикуется в dead-letter exchange
+// с причиной в заголовках, а если он не настроен — nack-ается без requeue.
+var ErrReject = errors.New("message rejected")
+
+// ErrLectureEnd сообщение о конце лекции: оно ack-ается, а консьюмер останавливается.
+var ErrLectureEnd = errors.New("lecture end")
+
+// Handler обрабатывает одно сообщение из очереди лекции.
+// nil — сообщение обработано и будет ack-нуто, ErrReject — отправлено в dead-letter queue,
+// ErrLectureEnd — ack-нуто и консьюмер остановлен,
+// любая другая ошибка считается временной: сообщение повторяется до RabbitConfig.MaxRetries раз,
+// после чего тоже уходит в dead-letter.
+type Handler interface {
+	Handle(ctx context.Context, lectureID int64, body []byte) error
+}
+
+// Reason возвращает машиночитаемую причину отказа из ошибки handler-а
+// (если ошибка реализует Reason() string) или fallback.
+func Reason(err error, fallback string) string {
+	var r interface{ Reason() string }
+	if errors.As(err, &r) {
+		return r.Reason()
+	}
+	return fallback
+}
+
+type receivedAtKey struct{}
+
+// WithReceivedAt передаёт handler-у время, когда сообщение впервые получено из очереди.
+// Оно одинаково у всех повторов сообщения, поэтому годится в ключ дедупликации.
+func WithReceivedAt(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, receivedAtKey{}, t)
+}
+
+// ReceivedAt время из WithReceivedAt или fallback, если его нет.
+func ReceivedAt(ctx context.Context, fallback time.Time) time.Time {
+	if t, ok := ctx.Value(receivedAtKey{}).(time.Time); ok {
+		return t
+	}
+	return fallback
+}
+
+// ReceivedAtHeader время первого получения из заголовков сообщения — в том числе
+// сохранённых в dead-letter, где после JSON число становится float64.
+func ReceivedAtHeader(headers map[string]any) (time.Time, bool) {
+	var micros int64
+	switch v := headers[headerReceivedAt].(type) {
+	case int64:
+		micros = v
+	case int32:
+		micros = int64(v)
+	case float64:
+		micros = int64(v)
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		micros = n
+	default:
+		return time.Time{}, false
+	}
+	if micros <= 0 {
+		return time.Time{}, false
+	}
+	return time.UnixMicro(micros), true
+}
@@ -0,0 +1,78 @@
+package rabbit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestReceivedAtHeader(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 123456000, time.UTC)
+	micros := at.UnixMicro()
+
+	tests := []struct {
+		name    string
+		headers map[string]any
+		want    time.Time
+		wantOK  bool
+	}{
+		{name: "int64 from broker", headers: map[string]any{headerReceivedAt: micros}, want: at, wantOK: true},
+		{name: "float64 from dead-letter json", headers: map[string]any{headerReceivedAt: float64(micros)}, want: at, wantOK: true},
+		{name: "json number", headers: map[string]any{headerReceivedAt: json.Number("1740823200123456")}, want: at, wantOK: true},
+		{name: "missing", headers: map[string]any{}},
+		{name: "nil headers"},
+		{name: "wrong type", headers: map[string]any{headerReceivedAt: "yesterday"}},
+		{name: "zero", headers: map[string]any{headerReceivedAt: int64(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ReceivedAtHeader(tt.headers)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("ReceivedAtHeader = %s, %v; want %s, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFirstReceivedAt(t *testing.T) {
+	published := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	retried := published.Add(-time.Minute)
+
+	tests := []struct {
+		name string
+		msg  amqp.Delivery
+		want time.Time
+	}{
+		{name: "publisher timestamp", msg: amqp.Delivery{Timestamp: published}, want: published},
+		{
+			name: "retried copy keeps first receive time",
+			msg:  amqp.Delivery{Timestamp: published, Headers: amqp.Table{headerReceivedAt: retried.UnixMicro()}},
+			want: retried,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstReceivedAt(tt.msg); !got.Equal(tt.want) {
+				t.Errorf("firstReceivedAt = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	before := time.Now()
+	if got := firstReceivedAt(amqp.Delivery{}); got.Before(before) {
+		t.Errorf("firstReceivedAt without timestamp = %s, want current time", got)
+	}
+
+	ctx := WithReceivedAt(context.Background(), retried)
+	if got := ReceivedAt(ctx, published); !got.Equal(retried) {
+		t.Errorf("ReceivedAt = %s, want %s", got, retried)
+	}
+	if got := ReceivedAt(context.Background(), published); !got.Equal(published) {
+		t.Errorf("ReceivedAt without value = %s, want fallback %s", got, published)
+	}
+}
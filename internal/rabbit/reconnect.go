@@ -0,0 +1,115 @@
+package rabbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"monitoring_backend/internal/config"
+	"monitoring_backend/internal/domain"
+)
+
+const (
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 20 * time.Second
+)
+
+var ErrReconnectFailed = errors.New("rabbit reconnect attempts exhausted")
+
+// reconnectPolicy нормализованная config.ReconnectConfig.
+type reconnectPolicy struct {
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	jitter         float64
+	maxAttempts    int // 0 — бесконечно
+}
+
+func newReconnectPolicy(cfg config.ReconnectConfig) reconnectPolicy {
+	p := reconnectPolicy{
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		jitter:         cfg.Jitter,
+		maxAttempts:    cfg.MaxAttempts,
+	}
+	if p.initialBackoff <= 0 {
+		p.initialBackoff = defaultInitialBackoff
+	}
+	if p.maxBackoff <= 0 {
+		p.maxBackoff = defaultMaxBackoff
+	}
+	if p.maxBackoff < p.initialBackoff {
+		p.maxBackoff = p.initialBackoff
+	}
+	if p.jitter < 0 {
+		p.jitter = 0
+	}
+	if p.jitter > 1 {
+		p.jitter = 1
+	}
+	if p.maxAttempts < 0 {
+		p.maxAttempts = 0
+	}
+	return p
+}
+
+// backoff пауза перед попыткой attempt (с 1): экспоненциальный рост до maxBackoff плюс jitter,
+// чтобы сотни консьюмеров не ломились в брокер одновременно после его рестарта.
+func (p reconnectPolicy) backoff(attempt int) time.Duration {
+	d := p.initialBackoff
+	for i := 1; i < attempt && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	if p.jitter > 0 {
+		d = time.Duration(float64(d) * (1 + p.jitter*(2*rand.Float64()-1)))
+	}
+	return d
+}
+
+// runWithReconnect вызывает run, пока не отменён ctx, с паузой по policy после ошибок.
+// run вызывает ready, когда подключился и начал читать: счётчик попыток сбрасывается.
+// run возвращает nil только при штатной остановке.
+// Возвращает ErrReconnectFailed, если исчерпаны policy.maxAttempts, иначе nil.
+// report получает каждое изменение состояния, может быть nil.
+func runWithReconnect(ctx context.Context, policy reconnectPolicy, name string, report func(domain.ConsumerHealth), run func(ready func()) error) error {
+	if report == nil {
+		report = func(domain.ConsumerHealth) {}
+	}
+
+	attempt := 0
+	report(domain.ConsumerHealth{State: domain.ConsumerConnecting, Since: time.Now()})
+
+	ready := func() {
+		attempt = 0
+		report(domain.ConsumerHealth{State: domain.ConsumerConnected, Since: time.Now()})
+	}
+
+	for {
+		err := run(ready)
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+
+		attempt++
+		if policy.maxAttempts > 0 && attempt >= policy.maxAttempts {
+			log.Printf("ERROR: %s: giving up after %d attempts: %v", name, attempt, err)
+			report(domain.ConsumerHealth{State: domain.ConsumerFailed, Attempt: attempt, LastError: err.Error(), Since: time.Now()})
+			return fmt.Errorf("%w: %w", ErrReconnectFailed, err)
+		}
+
+		backoff := policy.backoff(attempt)
+		log.Printf("WARN: %s disconnected, reconnect in %s (attempt %d): %v", name, backoff.Round(time.Millisecond), attempt, err)
+		report(domain.ConsumerHealth{State: domain.ConsumerReconnecting, Attempt: attempt, LastError: err.Error(), Since: time.Now()})
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package rabbit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitoring_backend/internal/config"
+	"monitoring_backend/internal/domain"
+)
+
+func TestNewReconnectPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ReconnectConfig
+		want reconnectPolicy
+	}{
+		{
+			name: "defaults",
+			want: reconnectPolicy{initialBackoff: defaultInitialBackoff, maxBackoff: defaultMaxBackoff},
+		},
+		{
+			name: "configured",
+			cfg:  config.ReconnectConfig{InitialBackoff: 2 * time.Second, MaxBackoff: time.Minute, Jitter: 0.2, MaxAttempts: 5},
+			want: reconnectPolicy{initialBackoff: 2 * time.Second, maxBackoff: time.Minute, jitter: 0.2, maxAttempts: 5},
+		},
+		{
+			name: "max below initial is raised",
+			cfg:  config.ReconnectConfig{InitialBackoff: 30 * time.Second, MaxBackoff: 10 * time.Second},
+			want: reconnectPolicy{initialBackoff: 30 * time.Second, maxBackoff: 30 * time.Second},
+		},
+		{
+			name: "negative values",
+			cfg:  config.ReconnectConfig{InitialBackoff: -time.Second, MaxBackoff: -time.Second, Jitter: -0.5, MaxAttempts: -1},
+			want: reconnectPolicy{initialBackoff: defaultInitialBackoff, maxBackoff: defaultMaxBackoff},
+		},
+		{
+			name: "jitter is capped",
+			cfg:  config.ReconnectConfig{Jitter: 3},
+			want: reconnectPolicy{initialBackoff: defaultInitialBackoff, maxBackoff: defaultMaxBackoff, jitter: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newReconnectPolicy(tt.cfg); got != tt.want {
+				t.Errorf("policy = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	policy := reconnectPolicy{initialBackoff: time.Second, maxBackoff: 20 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 5, want: 16 * time.Second},
+		{attempt: 6, want: 20 * time.Second},
+		{attempt: 1000, want: 20 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestReconnectBackoffJitter(t *testing.T) {
+	policy := reconnectPolicy{initialBackoff: time.Second, maxBackoff: 20 * time.Second, jitter: 0.2}
+
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 1, min: 800 * time.Millisecond, max: 1200 * time.Millisecond},
+		{attempt: 3, min: 3200 * time.Millisecond, max: 4800 * time.Millisecond},
+		{attempt: 10, min: 16 * time.Second, max: 24 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 100 {
+			if got := policy.backoff(tt.attempt); got < tt.min || got > tt.max {
+				t.Fatalf("backoff(%d) = %s, want within [%s, %s]", tt.attempt, got, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestRunWithReconnect(t *testing.T) {
+	errBroken := errors.New("connection reset")
+	policy := reconnectPolicy{initialBackoff: time.Millisecond, maxBackoff: time.Millisecond, maxAttempts: 3}
+
+	tests := []struct {
+		name string
+		// runs результаты вызовов run по порядку; ready — вызвать ready перед возвратом
+		runs      []error
+		ready     []bool
+		wantErr   error
+		wantCalls int
+		wantLast  domain.ConsumerState
+	}{
+		{
+			name:      "clean stop",
+			runs:      []error{nil},
+			ready:     []bool{true},
+			wantCalls: 1,
+			wantLast:  domain.ConsumerConnected,
+		},
+		{
+			name:      "gives up after max attempts",
+			runs:      []error{errBroken, errBroken, errBroken},
+			ready:     []bool{false, false, false},
+			wantErr:   ErrReconnectFailed,
+			wantCalls: 3,
+			wantLast:  domain.ConsumerFailed,
+		},
+		{
+			name:      "successful connect resets attempts",
+			runs:      []error{errBroken, errBroken, errBroken, errBroken, nil},
+			ready:     []bool{false, false, true, false, true},
+			wantCalls: 5,
+			wantLast:  domain.ConsumerConnected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var states []domain.ConsumerState
+			report := func(h domain.ConsumerHealth) { states = append(states, h.State) }
+
+			calls := 0
+			err := runWithReconnect(context.Background(), policy, "test", report, func(ready func()) error {
+				i := calls
+				calls++
+				if i >= len(tt.runs) {
+					t.Fatalf("unexpected run call %d", calls)
+				}
+				if tt.ready[i] {
+					ready()
+				}
+				return tt.runs[i]
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("run calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if len(states) == 0 || states[0] != domain.ConsumerConnecting {
+				t.Fatalf("states = %v, want to start with %s", states, domain.ConsumerConnecting)
+			}
+			if last := states[len(states)-1]; last != tt.wantLast {
+				t.Errorf("last state = %s, want %s", last, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestRunWithReconnectStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := reconnectPolicy{initialBackoff: time.Hour, maxBackoff: time.Hour}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runWithReconnect(ctx, policy, "test", nil, func(func()) error {
+			return errors.New("connection refused")
+		})
+	}()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("err = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runWithReconnect did not stop after cancel")
+	}
+}
@@ -0,0 +1,241 @@
+package rabbit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"monitoring_backend/internal/config"
+	"monitoring_backend/internal/domain"
+)
+
+var ErrRouteInUse = errors.New("routing key is already used by another lecture")
+
+// QueueSource читает для каждого занятия вида kind свою очередь (route — имя очереди).
+// Все очереди читаются через каналы общего соединения broker.
+type QueueSource struct {
+	broker  *Broker
+	cfg     config.RabbitConfig
+	kind    domain.ClassKind
+	handler Handler
+}
+
+func NewQueueSource(broker *Broker, cfg config.RabbitConfig, kind domain.ClassKind, handler Handler) *QueueSource {
+	return &QueueSource{broker: broker, cfg: cfg, kind: kind, handler: handler}
+}
+
+// DefaultRoute очередь лекции нужно передать явно.
+func (s *QueueSource) DefaultRoute(int64) string {
+	return ""
+}
+
+func (s *QueueSource) Consume(ctx context.Context, route string, lectureID int64, report func(domain.ConsumerHealth)) error {
+	return StartConsumer(ctx, s.broker, s.cfg, s.kind, route, lectureID, s.handler, report)
+}
+
+// Release очередь принадлежит внешней стороне, отпускать нечего.
+func (s *QueueSource) Release(context.Context, string) error {
+	return nil
+}
+
+// TopicSource читает одну общую очередь cfg.Queue, привязанную к topic exchange cfg.Exchange,
+// и раскладывает сообщения по лекциям по routing key. Для каждой запущенной лекции
+// в очередь добавляется binding с её routing key. Практики читаются отдельным TopicSource
+// со своей очередью и routing key practice.<id>.
+type TopicSource struct {
+	broker  *Broker
+	cfg     config.RabbitConfig
+	kind    domain.ClassKind
+	handler Handler
+
+	mu     sync.Mutex
+	routes map[string]*subscription // routing key → лекция
+	health domain.ConsumerHealth    // состояние общего консьюмера, оно же у всех лекций
+}
+
+type subscription struct {
+	lectureID int64
+	report    func(domain.ConsumerHealth)
+	ended     chan struct{}
+	endOnce   sync.Once
+}
+
+func NewTopicSource(broker *Broker, cfg config.RabbitConfig, kind domain.ClassKind, handler Handler) *TopicSource {
+	return &TopicSource{
+		broker:  broker,
+		cfg:     cfg,
+		kind:    kind,
+		handler: handler,
+		routes:  make(map[string]*subscription),
+		health:  domain.ConsumerHealth{State: domain.ConsumerConnecting, Since: time.Now()},
+	}
+}
+
+func (s *TopicSource) DefaultRoute(lectureID int64) string {
+	return fmt.Sprintf("%s.%d", s.kind, lectureID)
+}
+
+// Consume подписывает лекцию на routing key и блокируется до отмены ctx или конца лекции.
+// При отмене ctx binding остаётся, чтобы сообщения копились в очереди до рестарта;
+// убирает его Release или конец лекции. report получает состояние общего консьюмера.
+func (s *TopicSource) Consume(ctx context.Context, route string, lectureID int64, report func(domain.ConsumerHealth)) error {
+	if report == nil {
+		report = func(domain.ConsumerHealth) {}
+	}
+
+	s.mu.Lock()
+	if existing, ok := s.routes[route]; ok && existing.lectureID != lectureID {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s (lecture_id=%d)", ErrRouteInUse, route, existing.lectureID)
+	}
+	sub := &subscription{lectureID: lectureID, report: report, ended: make(chan struct{})}
+	s.routes[route] = sub
+	health := s.health
+	s.mu.Unlock()
+
+	report(health)
+
+	// при ошибке binding добавится при следующем переподключении Run
+	if err := s.bind(route); err != nil {
+		log.Printf("WARN: bind %s to %s (lecture_id=%d): %v", route, s.cfg.Exchange, lectureID, err)
+	}
+
+	var ended bool
+	select {
+	case <-ctx.Done():
+	case <-sub.ended:
+		ended = true
+	}
+
+	s.mu.Lock()
+	if s.routes[route] == sub {
+		delete(s.routes, route)
+	}
+	s.mu.Unlock()
+
+	if ended {
+		log.Printf("%s %d is end", s.kind, lectureID)
+		return s.Release(context.Background(), route)
+	}
+	return nil
+}
+
+// Release убирает binding routing key: сообщения остановленной лекции больше не попадают в очередь.
+func (s *TopicSource) Release(_ context.Context, route string) error {
+	s.mu.Lock()
+	delete(s.routes, route)
+	s.mu.Unlock()
+
+	ch, err := s.broker.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	return ch.QueueUnbind(s.cfg.Queue, route, s.cfg.Exchange, nil)
+}
+
+// Run читает общую очередь до отмены ctx, переподключаясь при обрывах.
+// Общий консьюмер не сдаётся после max_attempts: его остановка остановила бы все лекции.
+func (s *TopicSource) Run(ctx context.Context) {
+	policy := newReconnectPolicy(s.cfg.Reconnect)
+	policy.maxAttempts = 0
+
+	_ = runWithReconnect(ctx, policy, "topic consumer "+s.cfg.Queue, s.setHealth, func(ready func()) error {
+		return s.consumeOnce(ctx, ready)
+	})
+}
+
+// setHealth запоминает состояние общего консьюмера и рассылает его всем подписанным лекциям.
+func (s *TopicSource) setHealth(h domain.ConsumerHealth) {
+	s.mu.Lock()
+	s.health = h
+	subs := make([]*subscription, 0, len(s.routes))
+	for _, sub := range s.routes {
+		subs = append(subs, sub)
+	}
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.report(h)
+	}
+}
+
+func (s *TopicSource) consumeOnce(ctx context.Context, ready func()) error {
+	ch, err := s.broker.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := s.declare(ch); err != nil {
+		return err
+	}
+	if err := declareDeadLetter(ch, s.cfg); err != nil {
+		return err
+	}
+
+	// восстанавливаем bindings лекций, запущенных пока соединения не было
+	s.mu.Lock()
+	routes := make([]string, 0, len(s.routes))
+	for route := range s.routes {
+		routes = append(routes, route)
+	}
+	s.mu.Unlock()
+	for _, route := range routes {
+		if err := ch.QueueBind(s.cfg.Queue, route, s.cfg.Exchange, false, nil); err != nil {
+			return err
+		}
+	}
+
+	d := newDispatcher(ch, s.cfg, s.kind, s.cfg.Queue, s.handler, s.end)
+	return d.consume(ctx, nil, ready, s.lectureOf)
+}
+
+func (s *TopicSource) declare(ch *amqp.Channel) error {
+	if err := ch.ExchangeDeclare(s.cfg.Exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		return err
+	}
+	_, err := ch.QueueDeclare(s.cfg.Queue, true, false, false, false, nil)
+	return err
+}
+
+func (s *TopicSource) bind(route string) error {
+	ch, err := s.broker.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := s.declare(ch); err != nil {
+		return err
+	}
+	return ch.QueueBind(s.cfg.Queue, route, s.cfg.Exchange, false, nil)
+}
+
+func (s *TopicSource) lectureOf(msg amqp.Delivery) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.routes[routeOf(msg)]
+	if !ok {
+		return 0, false
+	}
+	return sub.lectureID, true
+}
+
+func (s *TopicSource) end(lectureID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sub := range s.routes {
+		if sub.lectureID == lectureID {
+			sub.endOnce.Do(func() { close(sub.ended) })
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"monitoring_backend/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type attendanceOverrideRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttendanceOverrideRepository(db *pgxpool.Pool) AttendanceOverrideRepository {
+	return &attendanceOverrideRepository{db: db}
+}
+
+const attendanceOverrideColumns = `kind, class_id, user_id, status, reason, updated_at, updated_by`
+
+func (r *attendanceOverrideRepository) Set(ctx context.Context, o domain.AttendanceOverride) (out domain.AttendanceOverride, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return out, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+		} else if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	// Отметку можно поставить только студенту из групп, прикреплённых к занятию.
+	const rosterQuery = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM universities_data.classes_groups cg
+			JOIN universities_data.students_groups sg ON sg.group_code = cg.group_id
+			WHERE cg.kind = $1 AND cg.class_id = $2 AND sg.user_id = $3
+		)
+	`
+
+	var enrolled bool
+	if err = tx.QueryRow(ctx, rosterQuery, o.Kind, o.ClassID, o.UserID).Scan(&enrolled); err != nil {
+		return out, err
+	}
+	if !enrolled {
+		return out, domain.ErrStudentNotFound
+	}
+
+	const previousQuery = `
+		SELECT status
+		FROM visits.attendance_overrides
+		WHERE kind = $1 AND class_id = $2 AND user_id = $3
+		FOR UPDATE
+	`
+
+	var previous *domain.AttendanceStatus
+	err = tx.QueryRow(ctx, previousQuery, o.Kind, o.ClassID, o.UserID).Scan(&previous)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return out, err
+	}
+
+	const upsertQuery = `
+		INSERT INTO visits.attendance_overrides (kind, class_id, user_id, status, reason, updated_at, updated_by)
+		VALUES ($1, $2, $3, $4, $5, now(), $6)
+		ON CONFLICT (kind, class_id, user_id) DO UPDATE
+		SET status = EXCLUDED.status,
+		    reason = EXCLUDED.reason,
+		    updated_at = EXCLUDED.updated_at,
+		    updated_by = EXCLUDED.updated_by
+		RETURNING ` + attendanceOverrideColumns + `
+	`
+
+	out, err = scanAttendanceOverride(tx.QueryRow(ctx, upsertQuery, o.Kind, o.ClassID, o.UserID, o.Status, o.Reason, o.UpdatedBy))
+	if isStudentViolation(err) {
+		return out, domain.ErrStudentNotFound
+	}
+	if err != nil {
+		return out, err
+	}
+
+	err = addAttendanceAudit(ctx, tx, domain.AttendanceAuditEntry{
+		Kind:           out.Kind,
+		ClassID:        out.ClassID,
+		UserID:         out.UserID,
+		Action:         domain.AttendanceOverrideSet,
+		Status:         &out.Status,
+		PreviousStatus: previous,
+		Reason:         &out.Reason,
+		Actor:          out.UpdatedBy,
+		CreatedAt:      out.UpdatedAt,
+	})
+	return out, err
+}
+
+func (r *attendanceOverrideRepository) Clear(
+	ctx context.Context,
+	kind domain.ClassKind,
+	classID int64,
+	userID string,
+	reason, actor *string,
+) (out domain.AttendanceOverride, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return out, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+		} else if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	const deleteQuery = `
+		DELETE FROM visits.attendance_overrides
+		WHERE kind = $1 AND class_id = $2 AND user_id = $3
+		RETURNING ` + attendanceOverrideColumns + `
+	`
+
+	out, err = scanAttendanceOverride(tx.QueryRow(ctx, deleteQuery, kind, classID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return out, domain.ErrAttendanceOverrideNotFound
+	}
+	if err != nil {
+		return out, err
+	}
+
+	err = addAttendanceAudit(ctx, tx, domain.AttendanceAuditEntry{
+		Kind:           kind,
+		ClassID:        classID,
+		UserID:         userID,
+		Action:         domain.AttendanceOverrideClear,
+		PreviousStatus: &out.Status,
+		Reason:         reason,
+		Actor:          actor,
+	})
+	return out, err
+}
+
+func (r *attendanceOverrideRepository) ListByClass(ctx context.Context, kind domain.ClassKind, classID int64) ([]domain.AttendanceOverride, error) {
+	query := `
+		SELECT o.kind, o.class_id, o.user_id, o.status, o.reason, o.updated_at, o.updated_by,
+		       u.last_name, u.first_name, u.patronymic, sg.group_code
+		FROM visits.attendance_overrides o
+		JOIN cores.users u ON u.isu = o.user_id
+		LEFT JOIN universities_data.students_groups sg ON sg.user_id = o.user_id
+		WHERE o.kind = $1 AND o.class_id = $2
+		ORDER BY u.last_name, u.first_name, o.user_id
+	`
+
+	rows, err := r.db.Query(ctx, query, kind, classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.AttendanceOverride, 0)
+	for rows.Next() {
+		var o domain.AttendanceOverride
+		if err := rows.Scan(
+			&o.Kind,
+			&o.ClassID,
+			&o.UserID,
+			&o.Status,
+			&o.Reason,
+			&o.UpdatedAt,
+			&o.UpdatedBy,
+			&o.User.LastName,
+			&o.User.FirstName,
+			&o.User.Patronymic,
+			&o.User.GroupCode,
+		); err != nil {
+			return nil, err
+		}
+		o.User.ISU = o.UserID
+		out = append(out, o)
+	}
+
+	return out, rows.Err()
+}
+
+func (r *attendanceOverrideRepository) ListByStudents(ctx context.Context, kind domain.ClassKind, classIDs []int64, userIDs []string) ([]domain.AttendanceOverride, error) {
+	query := `
+		SELECT ` + attendanceOverrideColumns + `
+		FROM visits.attendance_overrides
+		WHERE kind = $1 AND class_id = ANY($2) AND user_id = ANY($3)
+	`
+
+	rows, err := r.db.Query(ctx, query, kind, classIDs, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.AttendanceOverride, 0)
+	for rows.Next() {
+		o, err := scanAttendanceOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+
+	return out, rows.Err()
+}
+
+func (r *attendanceOverrideRepository) ListAudit(ctx context.Context, filter domain.AttendanceAuditFilter) ([]domain.AttendanceAuditEntry, int, error) {
+	limit := filter.PageSize
+	offset := (filter.Page - 1) * filter.PageSize
+
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM visits.attendance_override_audit
+		WHERE kind = $1 AND class_id = $2
+		  AND ($3::text IS NULL OR user_id = $3);
+	`
+
+	var total int
+	if err := r.db.QueryRow(ctx, totalQuery, filter.Kind, filter.ClassID, filter.UserID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery := `
+		SELECT id, kind, class_id, user_id, action, status, previous_status, reason, actor, created_at
+		FROM visits.attendance_override_audit
+		WHERE kind = $1 AND class_id = $2
+		  AND ($3::text IS NULL OR user_id = $3)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4 OFFSET $5;
+	`
+
+	rows, err := r.db.Query(ctx, listQuery, filter.Kind, filter.ClassID, filter.UserID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.AttendanceAuditEntry, 0)
+	for rows.Next() {
+		var e domain.AttendanceAuditEntry
+		if err := rows.Scan(
+			&e.ID,
+			&e.Kind,
+			&e.ClassID,
+			&e.UserID,
+			&e.Action,
+			&e.Status,
+			&e.PreviousStatus,
+			&e.Reason,
+			&e.Actor,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// addAttendanceAudit без CreatedAt запись получает время вставки.
+func addAttendanceAudit(ctx context.Context, tx pgx.Tx, e domain.AttendanceAuditEntry) error {
+	const query = `
+		INSERT INTO visits.attendance_override_audit (kind, class_id, user_id, action, status, previous_status, reason, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, now()))
+	`
+
+	var createdAt *time.Time
+	if !e.CreatedAt.IsZero() {
+		createdAt = &e.CreatedAt
+	}
+
+	_, err := tx.Exec(ctx, query, e.Kind, e.ClassID, e.UserID, e.Action, e.Status, e.PreviousStatus, e.Reason, e.Actor, createdAt)
+	return err
+}
+
+// isStudentViolation студента нет в cores.users.
+func isStudentViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "attendance_overrides_user_id_fkey"
+}
+
+func scanAttendanceOverride(row pgx.Row) (domain.AttendanceOverride, error) {
+	var o domain.AttendanceOverride
+	err := row.Scan(&o.Kind, &o.ClassID, &o.UserID, &o.Status, &o.Reason, &o.UpdatedAt, &o.UpdatedBy)
+	return o, err
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"monitoring_backend/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type attendancePolicyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttendancePolicyRepository(db *pgxpool.Pool) AttendancePolicyRepository {
+	return &attendancePolicyRepository{db: db}
+}
+
+const attendancePolicyColumns = `id, subject_id, department_id, late_after_seconds, left_early_after_seconds,
+	min_coverage_percent, updated_at, updated_by`
+
+func (r *attendancePolicyRepository) Resolve(ctx context.Context, subjectID int64, groupCode *string) (domain.AttendancePolicy, bool, error) {
+	query := `
+		SELECT late_after_seconds, left_early_after_seconds, min_coverage_percent
+		FROM universities_data.attendance_policies
+		WHERE subject_id = $1
+		   OR department_id = (
+			   SELECT department_id
+			   FROM universities_data.groups
+			   WHERE $2::text IS NOT NULL AND code = $2
+		   )
+		ORDER BY subject_id IS NOT NULL DESC
+		LIMIT 1
+	`
+
+	var lateAfter, leftEarlyAfter int
+	var p domain.AttendancePolicy
+	err := r.db.QueryRow(ctx, query, subjectID, groupCode).Scan(&lateAfter, &leftEarlyAfter, &p.MinCoveragePercent)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.AttendancePolicy{}, false, nil
+	}
+	if err != nil {
+		return domain.AttendancePolicy{}, false, err
+	}
+
+	p.LateAfter = time.Duration(lateAfter) * time.Second
+	p.LeftEarlyAfter = time.Duration(leftEarlyAfter) * time.Second
+	return p, true, nil
+}
+
+func (r *attendancePolicyRepository) List(ctx context.Context) ([]domain.AttendancePolicyRule, error) {
+	query := `
+		SELECT ` + attendancePolicyColumns + `
+		FROM universities_data.attendance_policies
+		ORDER BY subject_id NULLS LAST, department_id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]domain.AttendancePolicyRule, 0)
+	for rows.Next() {
+		rule, err := scanAttendancePolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *attendancePolicyRepository) Upsert(ctx context.Context, rule domain.AttendancePolicyRule) (domain.AttendancePolicyRule, error) {
+	// у subject_id и department_id разные уникальные ограничения, поэтому цель ON CONFLICT выбирается по тому,
+	// для чего задаётся политика
+	target := "department_id"
+	if rule.SubjectID != nil {
+		target = "subject_id"
+	}
+
+	query := `
+		INSERT INTO universities_data.attendance_policies
+			(subject_id, department_id, late_after_seconds, left_early_after_seconds, min_coverage_percent, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (` + target + `) DO UPDATE SET
+			late_after_seconds = EXCLUDED.late_after_seconds,
+			left_early_after_seconds = EXCLUDED.left_early_after_seconds,
+			min_coverage_percent = EXCLUDED.min_coverage_percent,
+			updated_at = now(),
+			updated_by = EXCLUDED.updated_by
+		RETURNING ` + attendancePolicyColumns
+
+	return scanAttendancePolicy(r.db.QueryRow(ctx, query,
+		rule.SubjectID,
+		rule.DepartmentID,
+		int(rule.Policy.LateAfter/time.Second),
+		int(rule.Policy.LeftEarlyAfter/time.Second),
+		rule.Policy.MinCoveragePercent,
+		rule.UpdatedBy,
+	))
+}
+
+func (r *attendancePolicyRepository) Delete(ctx context.Context, subjectID, departmentID *int64) error {
+	query := `
+		DELETE FROM universities_data.attendance_policies
+		WHERE ($1::bigint IS NOT NULL AND subject_id = $1)
+		   OR ($2::bigint IS NOT NULL AND department_id = $2)
+	`
+
+	tag, err := r.db.Exec(ctx, query, subjectID, departmentID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAttendancePolicyNotFound
+	}
+	return nil
+}
+
+func scanAttendancePolicy(row pgx.Row) (domain.AttendancePolicyRule, error) {
+	var rule domain.AttendancePolicyRule
+	var lateAfter, leftEarlyAfter int
+	err := row.Scan(
+		&rule.ID,
+		&rule.SubjectID,
+		&rule.DepartmentID,
+		&lateAfter,
+		&leftEarlyAfter,
+		&rule.Policy.MinCoveragePercent,
+		&rule.UpdatedAt,
+		&rule.UpdatedBy,
+	)
+	rule.Policy.LateAfter = time.Duration(lateAfter) * time.Second
+	rule.Policy.LeftEarlyAfter = time.Duration(leftEarlyAfter) * time.Second
+	return rule, err
+}
@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"monitoring_backend/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type cameraStatusRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCameraStatusRepository(db *pgxpool.Pool) CameraStatusRepository {
+	return &cameraStatusRepository{db: db}
+}
+
+// Heartbeat сбрасывает отметку о событии camera_down, если сама камера не сообщала, что она down:
+// камера снова жива, и следующий её отказ тоже дойдёт до подписчиков.
+func (r *cameraStatusRepository) Heartbeat(ctx context.Context, hb domain.CameraHeartbeat) error {
+	query := `
+		INSERT INTO visits.camera_status (camera_id, last_seen_at, fps, error_count, last_error_at)
+		VALUES ($1, $2::timestamptz, $3, $4::bigint, CASE WHEN $4::bigint > 0 THEN $2::timestamptz END)
+		ON CONFLICT (camera_id) DO UPDATE
+		SET last_seen_at = GREATEST(camera_status.last_seen_at, EXCLUDED.last_seen_at),
+		    fps = COALESCE(EXCLUDED.fps, camera_status.fps),
+		    error_count = camera_status.error_count + EXCLUDED.error_count,
+		    last_error_at = COALESCE(EXCLUDED.last_error_at, camera_status.last_error_at),
+		    down_notified_at = CASE WHEN camera_status.status = 'down' THEN camera_status.down_notified_at END
+	`
+
+	_, err := r.db.Exec(ctx, query, hb.CameraID, hb.SeenAt, hb.FPS, hb.Errors)
+	return err
+}
+
+func (r *cameraStatusRepository) Report(ctx context.Context, rep domain.CameraReport) error {
+	query := `
+		INSERT INTO visits.camera_status (camera_id, last_seen_at, status, error_count, last_error, last_error_at)
+		VALUES (
+			$1, $2::timestamptz, $3::text,
+			CASE WHEN $3::text <> 'ok' THEN 1 ELSE 0 END,
+			CASE WHEN $3::text <> 'ok' THEN NULLIF($4::text, '') END,
+			CASE WHEN $3::text <> 'ok' THEN $2::timestamptz END
+		)
+		ON CONFLICT (camera_id) DO UPDATE
+		SET last_seen_at = GREATEST(camera_status.last_seen_at, EXCLUDED.last_seen_at),
+		    status = EXCLUDED.status,
+		    error_count = camera_status.error_count + EXCLUDED.error_count,
+		    last_error = COALESCE(EXCLUDED.last_error, camera_status.last_error),
+		    last_error_at = COALESCE(EXCLUDED.last_error_at, camera_status.last_error_at),
+		    down_notified_at = CASE WHEN EXCLUDED.status = 'down' THEN camera_status.down_notified_at END
+	`
+
+	_, err := r.db.Exec(ctx, query, rep.CameraID, rep.SeenAt, rep.Status, rep.Message)
+	return err
+}
+
+func (r *cameraStatusRepository) List(ctx context.Context, roomCode *string) ([]domain.CameraStatus, error) {
+	query := `
+		SELECT COALESCE(c.id, s.camera_id), c.room_code, c.id IS NOT NULL,
+		       s.last_seen_at, s.fps, COALESCE(s.status, ''), COALESCE(s.error_count, 0), s.last_error, s.last_error_at
+		FROM universities_data.cameras c
+		FULL JOIN visits.camera_status s ON s.camera_id = c.id
+		WHERE ($1::text IS NULL OR c.room_code = $1)
+		ORDER BY c.room_code NULLS LAST, 1
+	`
+
+	rows, err := r.db.Query(ctx, query, roomCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.CameraStatus, 0)
+	for rows.Next() {
+		s, err := scanCameraStatus(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+
+	return out, rows.Err()
+}
+
+// ClaimOutages: между занятиями очередь аудитории никто не читает и heartbeat камеры устаревает сам,
+// поэтому отказ засчитывается только при запущенной лекции в аудитории, и не раньше чем через
+// timeout после её старта. О той же камере снова сообщается, если после события началась новая лекция.
+// Камеры берутся из справочника: камера, не приславшая ни одного сообщения, тоже считается отказавшей.
+func (r *cameraStatusRepository) ClaimOutages(ctx context.Context, now time.Time, timeout time.Duration) ([]domain.CameraOutage, error) {
+	const running = `
+		WITH running AS (
+			SELECT s.class_id, s.started_at, cl.room
+			FROM visits.class_sessions s
+			JOIN universities_data.classes cl ON cl.kind = s.kind AND cl.id = s.class_id
+			WHERE s.kind = $3 AND s.state = 'running' AND cl.room IS NOT NULL
+		)`
+
+	// у молчащих камер строки состояния нет — заводим её, чтобы было где отметить событие camera_down
+	seedQuery := running + `
+		INSERT INTO visits.camera_status (camera_id)
+		SELECT c.id
+		FROM universities_data.cameras c
+		WHERE EXISTS (
+			SELECT 1
+			FROM running rn
+			WHERE rn.room = c.room_code
+			  AND rn.started_at < $1::timestamptz - make_interval(secs => $2)
+		)
+		ON CONFLICT (camera_id) DO NOTHING
+	`
+
+	// события отказа камер получают только подписчики лекций
+	if _, err := r.db.Exec(ctx, seedQuery, now, timeout.Seconds(), domain.ClassLecture); err != nil {
+		return nil, err
+	}
+
+	// строку отмечает один UPDATE, поэтому при нескольких репликах отказ получает одна из них
+	query := running + `,
+		down AS (
+			UPDATE visits.camera_status st
+			SET down_notified_at = $1
+			FROM universities_data.cameras c
+			WHERE c.id = st.camera_id
+			  AND (st.status = 'down' OR st.last_seen_at IS NULL OR st.last_seen_at < $1::timestamptz - make_interval(secs => $2))
+			  AND EXISTS (
+				  SELECT 1
+				  FROM running rn
+				  WHERE rn.room = c.room_code
+				    AND (st.down_notified_at IS NULL OR rn.started_at > st.down_notified_at)
+				    AND (st.status = 'down' OR rn.started_at < $1::timestamptz - make_interval(secs => $2))
+			  )
+			RETURNING st.camera_id, c.room_code, st.last_seen_at, st.fps, st.status,
+			          st.error_count, st.last_error, st.last_error_at
+		)
+		SELECT d.camera_id, d.room_code, true,
+		       d.last_seen_at, d.fps, d.status, d.error_count, d.last_error, d.last_error_at,
+		       ARRAY(SELECT rn.class_id FROM running rn WHERE rn.room = d.room_code ORDER BY rn.class_id)
+		FROM down d
+		ORDER BY d.camera_id
+	`
+
+	rows, err := r.db.Query(ctx, query, now, timeout.Seconds(), domain.ClassLecture)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.CameraOutage, 0)
+	for rows.Next() {
+		var o domain.CameraOutage
+		err := rows.Scan(
+			&o.Status.CameraID,
+			&o.Status.RoomCode,
+			&o.Status.Registered,
+			&o.Status.LastSeenAt,
+			&o.Status.FPS,
+			&o.Status.Reported,
+			&o.Status.ErrorCount,
+			&o.Status.LastError,
+			&o.Status.LastErrorAt,
+			&o.LectureIDs,
+		)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+
+	return out, rows.Err()
+}
+
+func scanCameraStatus(row pgx.Row) (domain.CameraStatus, error) {
+	var s domain.CameraStatus
+	err := row.Scan(
+		&s.CameraID,
+		&s.RoomCode,
+		&s.Registered,
+		&s.LastSeenAt,
+		&s.FPS,
+		&s.Reported,
+		&s.ErrorCount,
+		&s.LastError,
+		&s.LastErrorAt,
+	)
+	return s, err
+}
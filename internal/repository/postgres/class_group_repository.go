@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"monitoring_backend/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type classGroupRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewClassGroupRepository(db *pgxpool.Pool) ClassGroupRepository {
+	return &classGroupRepository{db: db}
+}
+
+func (r *classGroupRepository) AddGroup(ctx context.Context, kind domain.ClassKind, classID int64, groupCode string) error {
+	query := `
+		INSERT INTO universities_data.classes_groups (kind, class_id, group_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (kind, class_id, group_id) DO NOTHING
+	`
+
+	_, err := r.db.Exec(ctx, query, kind, classID, groupCode)
+	return err
+}
+
+func (r *classGroupRepository) RemoveGroup(ctx context.Context, kind domain.ClassKind, classID int64, groupCode string) error {
+	query := `
+		DELETE FROM universities_data.classes_groups
+		WHERE kind = $1 AND class_id = $2 AND group_id = $3
+	`
+
+	_, err := r.db.Exec(ctx, query, kind, classID, groupCode)
+	return err
+}
+
+func (r *classGroupRepository) ListGroups(ctx context.Context, kind domain.ClassKind, classID int64) ([]string, error) {
+	query := `
+		SELECT group_id
+		FROM universities_data.classes_groups
+		WHERE kind = $1 AND class_id = $2
+		ORDER BY group_id
+	`
+
+	rows, err := r.db.Query(ctx, query, kind, classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []string
+	for rows.Next() {
+		var groupCode string
+		if err := rows.Scan(&groupCode); err != nil {
+			return nil, err
+		}
+		groups = append(groups, groupCode)
+	}
+
+	return groups, rows.Err()
+}
+
+// TODO как будто тоже лучше с лимитом
+func (r *classGroupRepository) ListByGroup(ctx context.Context, kind domain.ClassKind, groupCode string, from, to time.Time) ([]domain.Class, error) {
+	query := `
+		SELECT c.id, c.kind, c.date, c.subject_id, c.teacher_id, c.ends_at, COALESCE(c.room, ''), c.online
+		FROM universities_data.classes c
+		INNER JOIN universities_data.classes_groups cg ON cg.kind = c.kind AND cg.class_id = c.id
+		WHERE c.kind = $1
+		  AND cg.group_id = $2
+		  AND ($3::timestamptz IS NULL OR c.date >= $3)
+		  AND ($4::timestamptz IS NULL OR c.date <= $4)
+		ORDER BY c.date
+	`
+
+	rows, err := r.db.Query(ctx, query, kind, groupCode, timeOrNil(from), timeOrNil(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanClasses(rows)
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"monitoring_backend/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type classRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewClassRepository(db *pgxpool.Pool) ClassRepository {
+	return &classRepository{db: db}
+}
+
+const classColumns = `id, kind, date, subject_id, teacher_id, ends_at, COALESCE(room, ''), online`
+
+// Create вставляет занятие с c.ID, если он задан, иначе берёт id из общей последовательности.
+func (r *classRepository) Create(ctx context.Context, c domain.Class) (int64, error) {
+	query := `
+		INSERT INTO universities_data.classes (kind, id, date, subject_id, teacher_id, ends_at, room, online)
+		VALUES ($1, COALESCE(NULLIF($2::bigint, 0), nextval('universities_data.classes_id_seq')), $3, $4, $5, $6, NULLIF($7, ''), $8)
+		RETURNING id
+	`
+
+	var id int64
+	err := r.db.QueryRow(ctx, query, c.Kind, c.ID, c.Date, c.SubjectID, c.TeacherID, c.EndsAt, c.Room, c.Online).Scan(&id)
+	if isRoomViolation(err) {
+		return 0, domain.ErrRoomNotFound
+	}
+	return id, err
+}
+
+func (r *classRepository) SetRoom(ctx context.Context, kind domain.ClassKind, id int64, room string) error {
+	query := `
+		UPDATE universities_data.classes
+		SET room = NULLIF($3, '')
+		WHERE kind = $1 AND id = $2
+	`
+
+	tag, err := r.db.Exec(ctx, query, kind, id, room)
+	if isRoomViolation(err) {
+		return domain.ErrRoomNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrClassNotFound
+	}
+	return nil
+}
+
+// isRoomViolation аудитории занятия нет в справочнике (fk_classes_room).
+func isRoomViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "fk_classes_room"
+}
+
+func (r *classRepository) GetByID(ctx context.Context, kind domain.ClassKind, id int64) (domain.Class, error) {
+	query := `
+		SELECT ` + classColumns + `
+		FROM universities_data.classes
+		WHERE kind = $1 AND id = $2
+	`
+
+	var class domain.Class
+	err := r.db.QueryRow(ctx, query, kind, id).Scan(
+		&class.ID,
+		&class.Kind,
+		&class.Date,
+		&class.SubjectID,
+		&class.TeacherID,
+		&class.EndsAt,
+		&class.Room,
+		&class.Online,
+	)
+
+	return class, err
+}
+
+// ListByTeacher нулевые from/to не ограничивают период.
+func (r *classRepository) ListByTeacher(ctx context.Context, kind domain.ClassKind, teacherID string, from, to time.Time) ([]domain.Class, error) {
+	query := `
+		SELECT ` + classColumns + `
+		FROM universities_data.classes
+		WHERE kind = $1
+		  AND teacher_id = $2
+		  AND ($3::timestamptz IS NULL OR date >= $3)
+		  AND ($4::timestamptz IS NULL OR date <= $4)
+		ORDER BY date
+	`
+
+	rows, err := r.db.Query(ctx, query, kind, teacherID, timeOrNil(from), timeOrNil(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanClasses(rows)
+}
+
+func (r *classRepository) ListBySubject(ctx context.Context, kind domain.ClassKind, subjectID int64, from, to time.Time) ([]domain.Class, error) {
+	query := `
+		SELECT ` + classColumns + `
+		FROM universities_data.classes
+		WHERE kind = $1
+		  AND subject_id = $2
+		  AND ($3::timestamptz IS NULL OR date >= $3)
+		  AND ($4::timestamptz IS NULL OR date <= $4)
+		ORDER BY date
+	`
+
+	rows, err := r.db.Query(ctx, query, kind, subjectID, timeOrNil(from), timeOrNil(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanClasses(rows)
+}
+
+func scanClasses(rows pgx.Rows) ([]domain.Class, error) {
+	var classes []domain.Class
+	for rows.Next() {
+		var class domain.Class
+		if err := rows.Scan(&class.ID, &class.Kind, &class.Date, &class.SubjectID, &class.TeacherID, &class.EndsAt, &class.Room, &class.Online); err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
+	}
+
+	return classes, rows.Err()
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
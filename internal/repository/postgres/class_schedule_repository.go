@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"monitoring_backend/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type classScheduleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewClassScheduleRepository(db *pgxpool.Pool) ClassScheduleRepository {
+	return &classScheduleRepository{db: db}
+}
+
+// classEnd конец занятия c; без ends_at занятие длится domain.DefaultClassDuration.
+var classEnd = fmt.Sprintf(
+	`COALESCE(c.ends_at, c.date + make_interval(secs => %d))`,
+	int(domain.DefaultClassDuration.Seconds()),
+)
+
+func (r *classScheduleRepository) ListDueToStart(ctx context.Context, now time.Time, lead time.Duration) ([]domain.Class, error) {
+	query := `
+		SELECT c.id, c.kind, c.date, c.subject_id, c.teacher_id, c.ends_at, COALESCE(c.room, ''), c.online
+		FROM universities_data.classes c
+		LEFT JOIN visits.class_schedule_overrides o ON o.kind = c.kind AND o.class_id = c.id
+		LEFT JOIN LATERAL (
+			SELECT s.state, s.stopped_by
+			FROM visits.class_sessions s
+			WHERE s.kind = c.kind AND s.class_id = c.id
+			ORDER BY s.started_at DESC, s.id DESC
+			LIMIT 1
+		) latest ON true
+		WHERE c.date <= $1::timestamptz + make_interval(secs => $2)
+		  AND ` + classEnd + ` > $1
+		  AND COALESCE(o.mode, 'auto') = 'auto'
+		  AND (latest.state IS NULL OR (latest.state <> 'running' AND latest.stopped_by IS NULL))
+		ORDER BY c.date
+	`
+
+	rows, err := r.db.Query(ctx, query, now, lead.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanClasses(rows)
+}
+
+func (r *classScheduleRepository) ListDueToStop(ctx context.Context, now time.Time, grace time.Duration) ([]domain.ClassSession, error) {
+	query := `
+		SELECT s.id, s.kind, s.class_id, s.queue, s.state, s.started_at, s.stopped_at, s.started_by, s.stopped_by
+		FROM visits.class_sessions s
+		JOIN universities_data.classes c ON c.kind = s.kind AND c.id = s.class_id
+		LEFT JOIN visits.class_schedule_overrides o ON o.kind = c.kind AND o.class_id = c.id
+		WHERE s.state = 'running'
+		  AND ` + classEnd + ` <= $1::timestamptz - make_interval(secs => $2)
+		  AND COALESCE(o.mode, 'auto') = 'auto'
+		ORDER BY s.started_at
+	`
+
+	rows, err := r.db.Query(ctx, query, now, grace.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.ClassSession, 0)
+	for rows.Next() {
+		s, err := scanClassSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r *classScheduleRepository) GetOverride(ctx context.Context, kind domain.ClassKind, classID int64) (domain.ScheduleOverride, error) {
+	query := `
+		SELECT kind, class_id, mode, reason, updated_at, updated_by
+		FROM visits.class_schedule_overrides
+		WHERE kind = $1 AND class_id = $2
+	`
+
+	var o domain.ScheduleOverride
+	err := r.db.QueryRow(ctx, query, kind, classID).Scan(&o.Kind, &o.ClassID, &o.Mode, &o.Reason, &o.UpdatedAt, &o.UpdatedBy)
+	return o, err
+}
+
+func (r *classScheduleRepository) SetOverride(ctx context.Context, o domain.ScheduleOverride) (out domain.ScheduleOverride, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return out, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+		} else if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	const upsertQuery = `
+		INSERT INTO visits.class_schedule_overrides (kind, class_id, mode, reason, updated_at, updated_by)
+		VALUES ($1, $2, $3, $4, now(), $5)
+		ON CONFLICT (kind, class_id) DO UPDATE
+		SET mode = EXCLUDED.mode,
+		    reason = EXCLUDED.reason,
+		    updated_at = EXCLUDED.updated_at,
+		    updated_by = EXCLUDED.updated_by
+		RETURNING kind, class_id, mode, reason, updated_at, updated_by
+	`
+
+	err = tx.QueryRow(ctx, upsertQuery, o.Kind, o.ClassID, o.Mode, o.Reason, o.UpdatedBy).
+		Scan(&out.Kind, &out.ClassID, &out.Mode, &out.Reason, &out.UpdatedAt, &out.UpdatedBy)
+	if err != nil {
+		return out, err
+	}
+
+	const auditQuery = `
+		INSERT INTO visits.class_schedule_audit (kind, class_id, action, mode, reason, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err = tx.Exec(ctx, auditQuery, out.Kind, out.ClassID, domain.ScheduleActionSetMode, out.Mode, out.Reason, out.UpdatedBy, out.UpdatedAt)
+	return out, err
+}
+
+func (r *classScheduleRepository) AddAudit(ctx context.Context, e domain.ScheduleAuditEntry) error {
+	const query = `
+		INSERT INTO visits.class_schedule_audit (kind, class_id, action, mode, reason, actor)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Exec(ctx, query, e.Kind, e.ClassID, e.Action, e.Mode, e.Reason, e.Actor)
+	return err
+}
+
+func (r *classScheduleRepository) ListAudit(ctx context.Context, filter domain.ScheduleAuditFilter) ([]domain.ScheduleAuditEntry, int, error) {
+	limit := filter.PageSize
+	offset := (filter.Page - 1) * filter.PageSize
+
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM visits.class_schedule_audit
+		WHERE kind = $1 AND class_id = $2;
+	`
+
+	var total int
+	if err := r.db.QueryRow(ctx, totalQuery, filter.Kind, filter.ClassID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery := `
+		SELECT id, kind, class_id, action, mode, reason, actor, created_at
+		FROM visits.class_schedule_audit
+		WHERE kind = $1 AND class_id = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4;
+	`
+
+	rows, err := r.db.Query(ctx, listQuery, filter.Kind, filter.ClassID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.ScheduleAuditEntry, 0)
+	for rows.Next() {
+		var e domain.ScheduleAuditEntry
+		if err := rows.Scan(&e.ID, &e.Kind, &e.ClassID, &e.Action, &e.Mode, &e.Reason, &e.Actor, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"monitoring_backend/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// classSessionRepository сессии мониторинга занятий одного вида из visits.class_sessions.
+// LectureID в сессиях — id занятия этого вида.
+type classSessionRepository struct {
+	db   *pgxpool.Pool
+	kind domain.ClassKind
+}
+
+func NewClassSessionRepository(db *pgxpool.Pool, kind domain.ClassKind) ClassSessionRepository {
+	return &classSessionRepository{db: db, kind: kind}
+}
+
+const classSessionColumns = `id, kind, class_id, queue, state, started_at, stopped_at, started_by, stopped_by`
+
+func (r *classSessionRepository) Start(ctx context.Context, s domain.ClassSession) (domain.ClassSession, error) {
+	query := `
+		INSERT INTO visits.class_sessions (kind, class_id, queue, state, started_at, started_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + classSessionColumns
+
+	if s.StartedAt.IsZero() {
+		s.StartedAt = time.Now()
+	}
+
+	out, err := scanClassSession(r.db.QueryRow(ctx, query, r.kind, s.LectureID, s.Queue, domain.ClassSessionRunning, s.StartedAt, s.StartedBy))
+
+	// partial unique index uq_class_sessions_running
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return out, domain.ErrClassSessionAlreadyRunning
+	}
+
+	return out, err
+}
+
+func (r *classSessionRepository) Finish(ctx context.Context, lectureID int64, state domain.ClassSessionState, stoppedBy *string) (domain.ClassSession, error) {
+	query := `
+		UPDATE visits.class_sessions
+		SET state = $3, stopped_at = now(), stopped_by = $4
+		WHERE kind = $1 AND class_id = $2 AND state = 'running'
+		RETURNING ` + classSessionColumns
+
+	out, err := scanClassSession(r.db.QueryRow(ctx, query, r.kind, lectureID, state, stoppedBy))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return out, domain.ErrClassSessionNotFound
+	}
+
+	return out, err
+}
+
+func (r *classSessionRepository) ListRunning(ctx context.Context) ([]domain.ClassSession, error) {
+	query := `
+		SELECT ` + classSessionColumns + `
+		FROM visits.class_sessions
+		WHERE kind = $1 AND state = 'running'
+		ORDER BY started_at
+	`
+
+	rows, err := r.db.Query(ctx, query, r.kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.ClassSession, 0)
+	for rows.Next() {
+		s, err := scanClassSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r *classSessionRepository) List(ctx context.Context, filter domain.ClassSessionFilter) ([]domain.ClassSession, int, error) {
+	limit := filter.PageSize
+	offset := (filter.Page - 1) * filter.PageSize
+
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM visits.class_sessions
+		WHERE kind = $1
+		  AND ($2::bigint IS NULL OR class_id = $2)
+		  AND ($3::text IS NULL OR state = $3);
+	`
+
+	var total int
+	if err := r.db.QueryRow(ctx, totalQuery, r.kind, filter.LectureID, filter.State).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery := `
+		SELECT ` + classSessionColumns + `
+		FROM visits.class_sessions
+		WHERE kind = $1
+		  AND ($2::bigint IS NULL OR class_id = $2)
+		  AND ($3::text IS NULL OR state = $3)
+		ORDER BY started_at DESC, id DESC
+		LIMIT $4 OFFSET $5;
+	`
+
+	rows, err := r.db.Query(ctx, listQuery, r.kind, filter.LectureID, filter.State, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.ClassSession, 0)
+	for rows.Next() {
+		s, err := scanClassSession(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, total, nil
+}
+
+func scanClassSession(row pgx.Row) (domain.ClassSession, error) {
+	var s domain.ClassSession
+	err := row.Scan(
+		&s.ID,
+		&s.Kind,
+		&s.LectureID,
+		&s.Queue,
+		&s.State,
+		&s.StartedAt,
+		&s.StoppedAt,
+		&s.StartedBy,
+		&s.StoppedBy,
+	)
+	return s, err
+}
@@ -0,0 +1,824 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"monitoring_backend/internal/domain"
+	"monitoring_backend/internal/http/handlers/visits"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// classVisitsRepository снапшоты занятий одного вида из visits.classes_visiting.
+// Во всех методах lectureID — id занятия этого вида.
+type classVisitsRepository struct {
+	db   *pgxpool.Pool
+	kind domain.ClassKind
+}
+
+func NewClassVisitsRepository(db *pgxpool.Pool, kind domain.ClassKind) *classVisitsRepository {
+	return &classVisitsRepository{
+		db:   db,
+		kind: kind,
+	}
+}
+
+const classVisitColumns = `id, class_id, user_id, captured_at, received_at, camera_id, confidence`
+
+// inClassWindow условие «снапшот lv снят во время занятия l». Присутствие считается только по таким
+// снапшотам; сырая история (ListByClass, ListByUser) и живая лента отдают все.
+var inClassWindow = fmt.Sprintf(
+	`lv.captured_at >= l.date AND lv.captured_at <= COALESCE(l.ends_at, l.date + make_interval(secs => %d))`,
+	int(domain.DefaultClassDuration.Seconds()),
+)
+
+func (v *classVisitsRepository) AddBatch(ctx context.Context, visits []domain.ClassVisit) ([]domain.ClassVisit, error) {
+	if len(visits) == 0 {
+		return nil, nil
+	}
+
+	const insertQuery = `
+		INSERT INTO visits.classes_visiting(kind, class_id, user_id, captured_at, received_at, camera_id, confidence)
+		SELECT $1, * FROM unnest($2::bigint[], $3::text[], $4::timestamptz[], $5::timestamptz[], $6::text[], $7::real[])
+		ON CONFLICT (kind, class_id, user_id, captured_at) DO NOTHING
+		RETURNING ` + classVisitColumns + `;
+	`
+
+	rows, err := v.db.Query(ctx, insertQuery, unnestVisits(v.kind, visits)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanClassVisits(rows)
+}
+
+func (v *classVisitsRepository) AddPendingBatch(ctx context.Context, visits []domain.ClassVisit) ([]domain.ClassVisit, error) {
+	if len(visits) == 0 {
+		return nil, nil
+	}
+
+	const insertQuery = `
+		INSERT INTO visits.pending_sightings(kind, class_id, user_id, captured_at, received_at, camera_id, confidence)
+		SELECT $1, * FROM unnest($2::bigint[], $3::text[], $4::timestamptz[], $5::timestamptz[], $6::text[], $7::real[])
+		ON CONFLICT (kind, class_id, user_id, captured_at) DO NOTHING
+		RETURNING ` + classVisitColumns + `;
+	`
+
+	rows, err := v.db.Query(ctx, insertQuery, unnestVisits(v.kind, visits)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanClassVisits(rows)
+}
+
+// unnestVisits аргументы $1..$7 вставки снапшотов через unnest: вид занятия и колонки по массивам.
+func unnestVisits(kind domain.ClassKind, visits []domain.ClassVisit) []any {
+	lectureIDs := make([]int64, 0, len(visits))
+	userIDs := make([]string, 0, len(visits))
+	capturedAt := make([]time.Time, 0, len(visits))
+	receivedAt := make([]time.Time, 0, len(visits))
+	cameraIDs := make([]*string, 0, len(visits))
+	confidences := make([]*float64, 0, len(visits))
+	for _, visit := range visits {
+		lectureIDs = append(lectureIDs, visit.LectureID)
+		userIDs = append(userIDs, visit.UserID)
+		capturedAt = append(capturedAt, visit.CapturedAt)
+		receivedAt = append(receivedAt, visit.ReceivedAt)
+		cameraIDs = append(cameraIDs, visit.CameraID)
+		confidences = append(confidences, visit.Confidence)
+	}
+
+	return []any{kind, lectureIDs, userIDs, capturedAt, receivedAt, cameraIDs, confidences}
+}
+
+func (v *classVisitsRepository) ListStudentsByISU(ctx context.Context, isus []string) (map[string]domain.User, error) {
+	return listStudentsByISU(ctx, v.db, isus)
+}
+
+// listStudentsByISU возвращает студентов с группой. Пользователи без группы в результат не попадают.
+func listStudentsByISU(ctx context.Context, db *pgxpool.Pool, isus []string) (map[string]domain.User, error) {
+	const selectQuery = `
+		SELECT
+			u.isu,
+			u.last_name,
+			u.first_name,
+			u.patronymic,
+			sg.group_code
+		FROM cores.users u
+		JOIN universities_data.students_groups sg on u.isu = sg.user_id
+		WHERE u.isu = ANY($1);
+	`
+
+	rows, err := db.Query(ctx, selectQuery, isus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make(map[string]domain.User, len(isus))
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ISU, &user.LastName, &user.FirstName, &user.Patronymic, &user.GroupCode); err != nil {
+			return nil, err
+		}
+		users[user.ISU] = user
+	}
+
+	return users, rows.Err()
+}
+
+func (v *classVisitsRepository) Presence(ctx context.Context, lectureID int64, gapSeconds int) ([]domain.StudentPresence, int64, error) {
+	const cursorQuery = `
+		SELECT COALESCE(MAX(id), 0)
+		FROM visits.classes_visiting
+		WHERE kind = $1 AND class_id = $2;
+	`
+
+	// курсор берём до агрегации: снапшоты, пришедшие позже, клиент получит событиями
+	var lastID int64
+	if err := v.db.QueryRow(ctx, cursorQuery, v.kind, lectureID).Scan(&lastID); err != nil {
+		return nil, 0, err
+	}
+
+	q := `
+		WITH roster AS (
+			SELECT sg.user_id
+			FROM universities_data.classes_groups cg
+			JOIN universities_data.students_groups sg ON sg.group_code = cg.group_id
+			WHERE cg.kind = $4 AND cg.class_id = $1
+			UNION
+			SELECT DISTINCT lv.user_id
+			FROM visits.classes_visiting lv
+			JOIN universities_data.classes l ON l.kind = lv.kind AND l.id = lv.class_id
+			WHERE lv.kind = $4 AND lv.class_id = $1 AND lv.id <= $3
+			  AND ` + inClassWindow + `
+			UNION
+			SELECT ao.user_id
+			FROM visits.attendance_overrides ao
+			WHERE ao.kind = $4 AND ao.class_id = $1
+		),
+		snaps AS (
+			SELECT
+				lv.user_id,
+				lv.captured_at AS snap_time,
+				LEAD(lv.captured_at) OVER (PARTITION BY lv.user_id ORDER BY lv.captured_at) AS next_time
+			FROM visits.classes_visiting lv
+			JOIN universities_data.classes l ON l.kind = lv.kind AND l.id = lv.class_id
+			WHERE lv.kind = $4 AND lv.class_id = $1 AND lv.id <= $3
+			  AND ` + inClassWindow + `
+		),
+		presence AS (
+			SELECT
+				s.user_id,
+				COALESCE(SUM(
+					CASE
+						WHEN s.next_time IS NOT NULL
+						 AND EXTRACT(EPOCH FROM (s.next_time - s.snap_time)) <= $2
+						THEN EXTRACT(EPOCH FROM (s.next_time - s.snap_time))
+						ELSE 0
+					END
+				), 0)::bigint AS present_seconds,
+				COUNT(*) AS snapshots,
+				MIN(s.snap_time) AS first_seen,
+				MAX(s.snap_time) AS last_seen
+			FROM snaps s
+			GROUP BY s.user_id
+		)
+		SELECT
+			u.isu,
+			u.first_name,
+			u.last_name,
+			u.patronymic,
+			sg.group_code,
+			COALESCE(p.present_seconds, 0)::bigint AS present_seconds,
+			COALESCE(p.snapshots, 0)::int AS snapshots,
+			p.first_seen,
+			p.last_seen,
+			ao.status,
+			ao.reason,
+			ao.updated_at,
+			ao.updated_by
+		FROM roster r
+		JOIN cores.users u ON u.isu = r.user_id
+		LEFT JOIN universities_data.students_groups sg ON sg.user_id = r.user_id
+		LEFT JOIN presence p ON p.user_id = r.user_id
+		LEFT JOIN visits.attendance_overrides ao
+			ON ao.kind = $4 AND ao.class_id = $1 AND ao.user_id = r.user_id
+		ORDER BY u.last_name, u.first_name, u.isu;
+	`
+
+	rows, err := v.db.Query(ctx, q, lectureID, gapSeconds, lastID, v.kind)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.StudentPresence, 0)
+	for rows.Next() {
+		var it domain.StudentPresence
+		var (
+			status    *domain.AttendanceStatus
+			reason    *string
+			updatedAt *time.Time
+			updatedBy *string
+		)
+		if err := rows.Scan(
+			&it.User.ISU,
+			&it.User.FirstName,
+			&it.User.LastName,
+			&it.User.Patronymic,
+			&it.User.GroupCode,
+			&it.PresentSeconds,
+			&it.Snapshots,
+			&it.FirstSeen,
+			&it.LastSeen,
+			&status,
+			&reason,
+			&updatedAt,
+			&updatedBy,
+		); err != nil {
+			return nil, 0, err
+		}
+		if status != nil {
+			it.Override = &domain.AttendanceOverride{
+				Kind:      v.kind,
+				ClassID:   lectureID,
+				UserID:    it.User.ISU,
+				Status:    *status,
+				Reason:    *reason,
+				UpdatedAt: *updatedAt,
+				UpdatedBy: updatedBy,
+			}
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return items, lastID, nil
+}
+
+func (v *classVisitsRepository) ListVisitsSince(ctx context.Context, lectureID int64, afterID int64, limit int) ([]domain.ClassVisitDetails, error) {
+	const q = `
+		SELECT
+			lv.id,
+			lv.class_id,
+			lv.user_id,
+			lv.captured_at,
+			lv.received_at,
+			lv.camera_id,
+			lv.confidence,
+			u.isu,
+			u.first_name,
+			u.last_name,
+			u.patronymic,
+			sg.group_code
+		FROM visits.classes_visiting lv
+		JOIN cores.users u ON u.isu = lv.user_id
+		LEFT JOIN universities_data.students_groups sg ON sg.user_id = lv.user_id
+		WHERE lv.kind = $1 AND lv.class_id = $2 AND lv.id > $3
+		ORDER BY lv.id
+		LIMIT $4;
+	`
+
+	rows, err := v.db.Query(ctx, q, v.kind, lectureID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]domain.ClassVisitDetails, 0)
+	for rows.Next() {
+		var it domain.ClassVisitDetails
+		if err := rows.Scan(
+			&it.Visit.ID,
+			&it.Visit.LectureID,
+			&it.Visit.UserID,
+			&it.Visit.CapturedAt,
+			&it.Visit.ReceivedAt,
+			&it.Visit.CameraID,
+			&it.Visit.Confidence,
+			&it.User.ISU,
+			&it.User.FirstName,
+			&it.User.LastName,
+			&it.User.Patronymic,
+			&it.User.GroupCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+
+	return items, rows.Err()
+}
+
+func (v *classVisitsRepository) Exists(ctx context.Context, lectureID int64, userID string) (bool, error) {
+	const q = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM visits.classes_visiting
+			WHERE kind = $1 AND class_id = $2 AND user_id = $3
+		);
+	`
+
+	var exists bool
+	err := v.db.QueryRow(ctx, q, v.kind, lectureID, userID).Scan(&exists)
+	return exists, err
+}
+
+func (v *classVisitsRepository) ListByClass(ctx context.Context, lectureID int64, filter domain.ClassVisitFilter) ([]domain.ClassVisit, int, error) {
+	limit := filter.PageSize
+	offset := (filter.Page - 1) * filter.PageSize
+
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM visits.classes_visiting
+		WHERE kind = $1
+		  AND class_id = $2
+		  AND ($3::timestamptz IS NULL OR captured_at >= $3)
+		  AND ($4::timestamptz IS NULL OR captured_at <= $4)
+		  AND ($5::text IS NULL OR user_id = $5);
+	`
+
+	var total int
+	if err := v.db.QueryRow(ctx, totalQuery, v.kind, lectureID, filter.From, filter.To, filter.UserID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery := `
+		SELECT ` + classVisitColumns + `
+		FROM visits.classes_visiting
+		WHERE kind = $1
+		  AND class_id = $2
+		  AND ($3::timestamptz IS NULL OR captured_at >= $3)
+		  AND ($4::timestamptz IS NULL OR captured_at <= $4)
+		  AND ($5::text IS NULL OR user_id = $5)
+		ORDER BY captured_at, id
+		LIMIT $6 OFFSET $7;
+	`
+
+	rows, err := v.db.Query(ctx, listQuery, v.kind, lectureID, filter.From, filter.To, filter.UserID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items, err := scanClassVisits(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+func (v *classVisitsRepository) ListByUser(ctx context.Context, userID string, from, to time.Time) ([]domain.ClassVisit, error) {
+	q := `
+		SELECT ` + classVisitColumns + `
+		FROM visits.classes_visiting
+		WHERE kind = $1
+		  AND user_id = $2
+		  AND captured_at >= $3
+		  AND captured_at <= $4
+		ORDER BY captured_at, id;
+	`
+
+	rows, err := v.db.Query(ctx, q, v.kind, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanClassVisits(rows)
+}
+
+func scanClassVisits(rows pgx.Rows) ([]domain.ClassVisit, error) {
+	items := make([]domain.ClassVisit, 0)
+	for rows.Next() {
+		var visit domain.ClassVisit
+		if err := rows.Scan(
+			&visit.ID,
+			&visit.LectureID,
+			&visit.UserID,
+			&visit.CapturedAt,
+			&visit.ReceivedAt,
+			&visit.CameraID,
+			&visit.Confidence,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, visit)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *classVisitsRepository) ListVisitedSubjectsByISU(ctx context.Context, isu string) ([]domain.Subject, error) {
+	const q = `
+		SELECT DISTINCT
+			s.id,
+			s.name
+		FROM universities_data.classes l
+		JOIN universities_data.subjects s
+			ON s.id = l.subject_id
+		WHERE l.kind = $1
+		  AND (
+			  EXISTS (
+				  SELECT 1
+				  FROM visits.classes_visiting lv
+				  WHERE lv.kind = l.kind AND lv.class_id = l.id AND lv.user_id = $2
+			  )
+			  OR EXISTS (
+				  SELECT 1
+				  FROM visits.attendance_overrides ao
+				  WHERE ao.kind = l.kind AND ao.class_id = l.id AND ao.user_id = $2
+			  )
+		  )
+		ORDER BY s.name;
+	`
+
+	rows, err := r.db.Query(ctx, q, r.kind, isu)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := make([]domain.Subject, 0)
+	for rows.Next() {
+		var s domain.Subject
+		if err := rows.Scan(&s.ID, &s.Name); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subjects, nil
+}
+
+func (r *classVisitsRepository) ListStudentClassesBySubject(ctx context.Context, isu string, subjectID int64, filter visits.GetLecturesFilter) ([]visits.ClassAttendance, int, error) {
+	isu = strings.TrimSpace(isu)
+	if isu == "" {
+		return nil, 0, fmt.Errorf("isu is empty")
+	}
+
+	order := "DESC"
+	if strings.ToLower(filter.Order) == "asc" {
+		order = "ASC"
+	}
+
+	limit := filter.PageSize
+	offset := (filter.Page - 1) * filter.PageSize
+
+	// 1) total (для пагинации): занятия по subject, где студента видели камеры
+	// или где преподаватель поставил ему отметку вручную
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM universities_data.classes l
+		WHERE l.kind = $5
+		  AND l.subject_id = $1
+		  AND ($2::timestamptz IS NULL OR l.date >= $2)
+		  AND ($3::timestamptz IS NULL OR l.date <= $3)
+		  AND (
+			  EXISTS (
+				  SELECT 1
+				  FROM visits.classes_visiting lv
+				  WHERE lv.kind = l.kind
+				    AND lv.class_id = l.id
+				    AND lv.user_id = $4
+				    AND ` + inClassWindow + `
+			  )
+			  OR EXISTS (
+				  SELECT 1
+				  FROM visits.attendance_overrides ao
+				  WHERE ao.kind = l.kind AND ao.class_id = l.id AND ao.user_id = $4
+			  )
+		  );
+	`
+
+	var total int
+	if err := r.db.QueryRow(ctx, totalQuery, subjectID, filter.DateFrom, filter.DateTo, isu, r.kind).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// 2) list with present_seconds per lecture
+	// present_seconds считаем через LEAD(captured_at) и суммирование разницы, если gap <= filter.GapSeconds;
+	// занятия только с ручной отметкой попадают в список без снапшотов (first_seen/last_seen = NULL)
+	listQuery := fmt.Sprintf(`
+		WITH snaps AS (
+			SELECT
+				lv.class_id,
+				lv.captured_at AS snap_time,
+				LEAD(lv.captured_at) OVER (PARTITION BY lv.class_id ORDER BY lv.captured_at) AS next_time
+			FROM visits.classes_visiting lv
+			JOIN universities_data.classes l ON l.kind = lv.kind AND l.id = lv.class_id
+			WHERE lv.kind = $8
+			  AND lv.user_id = $1
+			  AND l.subject_id = $2
+			  AND ($3::timestamptz IS NULL OR l.date >= $3)
+			  AND ($4::timestamptz IS NULL OR l.date <= $4)
+			  AND `+inClassWindow+`
+		)
+		SELECT
+			l.id,
+			l.date,
+			l.ends_at,
+			l.teacher_id,
+			MIN(s.snap_time) AS first_seen,
+			MAX(s.snap_time) AS last_seen,
+			COALESCE(SUM(
+				CASE
+					WHEN s.next_time IS NOT NULL
+					 AND EXTRACT(EPOCH FROM (s.next_time - s.snap_time)) <= $5
+					THEN EXTRACT(EPOCH FROM (s.next_time - s.snap_time))
+					ELSE 0
+				END
+			), 0)::bigint AS present_seconds
+		FROM universities_data.classes l
+		LEFT JOIN snaps s ON s.class_id = l.id
+		WHERE l.kind = $8
+		  AND l.subject_id = $2
+		  AND ($3::timestamptz IS NULL OR l.date >= $3)
+		  AND ($4::timestamptz IS NULL OR l.date <= $4)
+		  AND (
+			  s.class_id IS NOT NULL
+			  OR EXISTS (
+				  SELECT 1
+				  FROM visits.attendance_overrides ao
+				  WHERE ao.kind = l.kind AND ao.class_id = l.id AND ao.user_id = $1
+			  )
+		  )
+		GROUP BY l.id, l.date, l.ends_at, l.teacher_id
+		ORDER BY l.date %s
+		LIMIT $6 OFFSET $7;
+	`, order)
+
+	rows, err := r.db.Query(ctx, listQuery, isu, subjectID, filter.DateFrom, filter.DateTo, filter.GapSeconds, limit, offset, r.kind)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]visits.ClassAttendance, 0)
+	for rows.Next() {
+		var it visits.ClassAttendance
+		var endsAt *time.Time
+		if err := rows.Scan(&it.ClassID, &it.Date, &endsAt, &it.TeacherISU, &it.FirstSeen, &it.LastSeen, &it.PresentSeconds); err != nil {
+			return nil, 0, err
+		}
+		it.EndsAt = domain.Class{Date: it.Date, EndsAt: endsAt}.End()
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+func (r *classVisitsRepository) ListTeacherClassesBySubject(
+	ctx context.Context,
+	teacherISU string,
+	subjectID int64,
+	filter visits.TeacherLecturesFilter,
+) ([]visits.TeacherClass, int, error) {
+	order := "DESC"
+	if strings.ToLower(filter.Order) == "asc" {
+		order = "ASC"
+	}
+	limit := filter.PageSize
+	offset := (filter.Page - 1) * filter.PageSize
+
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM universities_data.classes l
+		WHERE l.kind = $5
+		  AND l.teacher_id = $1
+		  AND l.subject_id = $2
+		  AND ($3::timestamptz IS NULL OR l.date >= $3)
+		  AND ($4::timestamptz IS NULL OR l.date <= $4);
+	`
+
+	var total int
+	if err := r.db.QueryRow(ctx, totalQuery, teacherISU, subjectID, filter.DateFrom, filter.DateTo, r.kind).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery := fmt.Sprintf(`
+		SELECT l.id, l.date
+		FROM universities_data.classes l
+		WHERE l.kind = $7
+		  AND l.teacher_id = $1
+		  AND l.subject_id = $2
+		  AND ($3::timestamptz IS NULL OR l.date >= $3)
+		  AND ($4::timestamptz IS NULL OR l.date <= $4)
+		ORDER BY l.date %s
+		LIMIT $5 OFFSET $6;
+	`, order)
+
+	rows, err := r.db.Query(ctx, listQuery, teacherISU, subjectID, filter.DateFrom, filter.DateTo, limit, offset, r.kind)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]visits.TeacherClass, 0)
+	for rows.Next() {
+		var it visits.TeacherClass
+		if err := rows.Scan(&it.ClassID, &it.Date); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+func (r *classVisitsRepository) ListClassGroups(ctx context.Context, teacherISU string, lectureID int64) ([]string, error) {
+	// защита: преподаватель может смотреть только свои занятия
+	check := `
+		SELECT 1
+		FROM universities_data.classes l
+		WHERE l.kind = $3 AND l.id = $1 AND l.teacher_id = $2;
+	`
+	var ok int
+	if err := r.db.QueryRow(ctx, check, lectureID, teacherISU, r.kind).Scan(&ok); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT cg.group_id
+		FROM universities_data.classes_groups cg
+		WHERE cg.kind = $1 AND cg.class_id = $2
+		ORDER BY cg.group_id;
+	`, r.kind, lectureID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []string
+	for rows.Next() {
+		var g string
+		if err := rows.Scan(&g); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *classVisitsRepository) ListClassGroupStudents(
+	ctx context.Context,
+	teacherISU string,
+	lectureID int64,
+	groupCode string,
+	page int,
+	pageSize int,
+	gapSeconds int,
+) ([]visits.StudentOnLecture, int, error) {
+	groupCode = strings.TrimSpace(groupCode)
+	limit := pageSize
+	offset := (page - 1) * pageSize
+
+	// защита: занятие принадлежит teacher и group реально привязана к занятию
+	check := `
+		SELECT 1
+		FROM universities_data.classes l
+		JOIN universities_data.classes_groups cg ON cg.kind = l.kind AND cg.class_id = l.id
+		WHERE l.kind = $4 AND l.id = $1 AND l.teacher_id = $2 AND cg.group_id = $3;
+	`
+	var ok int
+	if err := r.db.QueryRow(ctx, check, lectureID, teacherISU, groupCode, r.kind).Scan(&ok); err != nil {
+		return nil, 0, err
+	}
+
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM universities_data.students_groups sg
+		WHERE sg.group_code = $1;
+	`
+	var total int
+	if err := r.db.QueryRow(ctx, totalQuery, groupCode).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// presence per student for this lecture (gapSeconds controls stitching)
+	q := `
+		WITH group_students AS (
+			SELECT sg.user_id
+			FROM universities_data.students_groups sg
+			WHERE sg.group_code = $1
+		),
+		snaps AS (
+			SELECT
+				lv.user_id,
+				lv.captured_at AS snap_time,
+				LEAD(lv.captured_at) OVER (PARTITION BY lv.user_id ORDER BY lv.captured_at) AS next_time
+			FROM visits.classes_visiting lv
+			JOIN group_students gs ON gs.user_id = lv.user_id
+			JOIN universities_data.classes l ON l.kind = lv.kind AND l.id = lv.class_id
+			WHERE lv.kind = $6 AND lv.class_id = $2
+			  AND ` + inClassWindow + `
+		),
+		presence AS (
+			SELECT
+				s.user_id,
+				COALESCE(SUM(
+					CASE
+						WHEN s.next_time IS NOT NULL
+						 AND EXTRACT(EPOCH FROM (s.next_time - s.snap_time)) <= $3
+						THEN EXTRACT(EPOCH FROM (s.next_time - s.snap_time))
+						ELSE 0
+					END
+				), 0)::bigint AS present_seconds,
+				MIN(s.snap_time) AS first_seen,
+				MAX(s.snap_time) AS last_seen
+			FROM snaps s
+			GROUP BY s.user_id
+		)
+		SELECT
+			u.isu,
+			u.first_name,
+			u.last_name,
+			u.patronymic,
+			COALESCE(p.present_seconds, 0)::bigint AS present_seconds,
+			p.first_seen,
+			p.last_seen
+		FROM universities_data.students_groups sg
+		JOIN cores.users u ON u.isu = sg.user_id
+		LEFT JOIN presence p ON p.user_id = sg.user_id
+		WHERE sg.group_code = $1
+		ORDER BY u.last_name, u.first_name, u.isu
+		LIMIT $4 OFFSET $5;
+	`
+
+	rows, err := r.db.Query(ctx, q, groupCode, lectureID, gapSeconds, limit, offset, r.kind)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]visits.StudentOnLecture, 0)
+	for rows.Next() {
+		var it visits.StudentOnLecture
+		if err := rows.Scan(&it.ISU, &it.FirstName, &it.LastName, &it.Patronymic, &it.PresentSeconds, &it.FirstSeen, &it.LastSeen); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+func (r *classVisitsRepository) ListTeacherSubjects(ctx context.Context, teacherISU string) ([]visits.SubjectDTO, error) {
+	const q = `
+		SELECT DISTINCT
+			s.id,
+			s.name
+		FROM universities_data.classes l
+		JOIN universities_data.subjects s
+			ON s.id = l.subject_id
+		WHERE l.kind = $1 AND l.teacher_id = $2
+		ORDER BY s.name;
+	`
+
+	rows, err := r.db.Query(ctx, q, r.kind, teacherISU)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]visits.SubjectDTO, 0)
+	for rows.Next() {
+		var item visits.SubjectDTO
+		if err := rows.Scan(&item.ID, &item.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
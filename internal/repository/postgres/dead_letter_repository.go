@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"monitoring_backend/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type deadLetterRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDeadLetterRepository(db *pgxpool.Pool) DeadLetterRepository {
+	return &deadLetterRepository{db: db}
+}
+
+const deadLetterColumns = `id, source_queue, kind, lecture_id, reason, error, headers, content_type, body,
+	failed_at, archived_at, status, replay_attempts, resolved_at, resolved_by`
+
+func (r *deadLetterRepository) Add(ctx context.Context, dl domain.DeadLetter) (int64, error) {
+	query := `
+		INSERT INTO visits.dead_letters (source_queue, kind, lecture_id, reason, error, headers, content_type, body, failed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	headers, err := json.Marshal(dl.Headers)
+	if err != nil || dl.Headers == nil {
+		headers = []byte(`{}`)
+	}
+
+	kind := dl.Kind
+	if kind == "" {
+		kind = domain.ClassLecture
+	}
+
+	var id int64
+	err = r.db.QueryRow(ctx, query,
+		dl.SourceQueue,
+		kind,
+		dl.LectureID,
+		dl.Reason,
+		dl.Error,
+		headers,
+		dl.ContentType,
+		dl.Body,
+		dl.FailedAt,
+	).Scan(&id)
+
+	return id, err
+}
+
+func (r *deadLetterRepository) GetByID(ctx context.Context, id int64) (domain.DeadLetter, error) {
+	query := `SELECT ` + deadLetterColumns + ` FROM visits.dead_letters WHERE id = $1`
+
+	dl, err := scanDeadLetter(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dl, domain.ErrDeadLetterNotFound
+	}
+
+	return dl, err
+}
+
+func (r *deadLetterRepository) ListByIDs(ctx context.Context, ids []int64) ([]domain.DeadLetter, error) {
+	query := `
+		SELECT ` + deadLetterColumns + `
+		FROM visits.dead_letters
+		WHERE id = ANY($1::bigint[])
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.DeadLetter, 0, len(ids))
+	for rows.Next() {
+		dl, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, dl)
+	}
+
+	return out, rows.Err()
+}
+
+func (r *deadLetterRepository) List(ctx context.Context, filter domain.DeadLetterFilter) ([]domain.DeadLetter, int, error) {
+	limit := filter.PageSize
+	offset := (filter.Page - 1) * filter.PageSize
+
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM visits.dead_letters
+		WHERE ($1::text IS NULL OR status = $1)
+		  AND ($2::text IS NULL OR reason = $2)
+		  AND ($3::bigint IS NULL OR lecture_id = $3)
+		  AND ($4::text IS NULL OR kind = $4);
+	`
+
+	var total int
+	if err := r.db.QueryRow(ctx, totalQuery, filter.Status, filter.Reason, filter.LectureID, filter.Kind).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery := `
+		SELECT ` + deadLetterColumns + `
+		FROM visits.dead_letters
+		WHERE ($1::text IS NULL OR status = $1)
+		  AND ($2::text IS NULL OR reason = $2)
+		  AND ($3::bigint IS NULL OR lecture_id = $3)
+		  AND ($4::text IS NULL OR kind = $4)
+		ORDER BY id
+		LIMIT $5 OFFSET $6;
+	`
+
+	rows, err := r.db.Query(ctx, listQuery, filter.Status, filter.Reason, filter.LectureID, filter.Kind, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.DeadLetter, 0)
+	for rows.Next() {
+		dl, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, dl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return out, total, nil
+}
+
+func (r *deadLetterRepository) Resolve(ctx context.Context, ids []int64, status domain.DeadLetterStatus, resolvedBy *string) ([]int64, error) {
+	query := `
+		UPDATE visits.dead_letters
+		SET status = $2, resolved_at = now(), resolved_by = $3
+		WHERE id = ANY($1::bigint[]) AND status = 'pending'
+		RETURNING id
+	`
+
+	rows, err := r.db.Query(ctx, query, ids, status, resolvedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resolved := make([]int64, 0, len(ids))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, id)
+	}
+
+	return resolved, rows.Err()
+}
+
+func (r *deadLetterRepository) RecordReplayFailure(ctx context.Context, id int64, reason, errText string) error {
+	query := `
+		UPDATE visits.dead_letters
+		SET reason = $2, error = $3, replay_attempts = replay_attempts + 1
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, id, reason, errText)
+	return err
+}
+
+func scanDeadLetter(row pgx.Row) (domain.DeadLetter, error) {
+	var (
+		dl      domain.DeadLetter
+		headers []byte
+	)
+	err := row.Scan(
+		&dl.ID,
+		&dl.SourceQueue,
+		&dl.Kind,
+		&dl.LectureID,
+		&dl.Reason,
+		&dl.Error,
+		&headers,
+		&dl.ContentType,
+		&dl.Body,
+		&dl.FailedAt,
+		&dl.ArchivedAt,
+		&dl.Status,
+		&dl.ReplayAttempts,
+		&dl.ResolvedAt,
+		&dl.ResolvedBy,
+	)
+	if err != nil {
+		return dl, err
+	}
+
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &dl.Headers); err != nil {
+			return dl, err
+		}
+	}
+
+	return dl, nil
+}
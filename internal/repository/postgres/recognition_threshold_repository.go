@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"monitoring_backend/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type recognitionThresholdRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRecognitionThresholdRepository(db *pgxpool.Pool) RecognitionThresholdRepository {
+	return &recognitionThresholdRepository{db: db}
+}
+
+const recognitionThresholdColumns = `department_id, accept_threshold, review_threshold, updated_at, updated_by`
+
+func (r *recognitionThresholdRepository) ByStudents(ctx context.Context, isus []string) (map[string]domain.RecognitionThresholds, error) {
+	query := `
+		SELECT sg.user_id, t.accept_threshold, t.review_threshold
+		FROM universities_data.students_groups sg
+		JOIN universities_data.groups g ON g.code = sg.group_code
+		JOIN universities_data.recognition_thresholds t ON t.department_id = g.department_id
+		WHERE sg.user_id = ANY($1)
+	`
+
+	rows, err := r.db.Query(ctx, query, isus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]domain.RecognitionThresholds, len(isus))
+	for rows.Next() {
+		var isu string
+		var t domain.RecognitionThresholds
+		if err := rows.Scan(&isu, &t.Accept, &t.Review); err != nil {
+			return nil, err
+		}
+		out[isu] = t
+	}
+
+	return out, rows.Err()
+}
+
+func (r *recognitionThresholdRepository) List(ctx context.Context) ([]domain.RecognitionThresholdRule, error) {
+	query := `
+		SELECT ` + recognitionThresholdColumns + `
+		FROM universities_data.recognition_thresholds
+		ORDER BY department_id
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]domain.RecognitionThresholdRule, 0)
+	for rows.Next() {
+		rule, err := scanRecognitionThreshold(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *recognitionThresholdRepository) Upsert(ctx context.Context, rule domain.RecognitionThresholdRule) (domain.RecognitionThresholdRule, error) {
+	query := `
+		INSERT INTO universities_data.recognition_thresholds (department_id, accept_threshold, review_threshold, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (department_id) DO UPDATE SET
+			accept_threshold = EXCLUDED.accept_threshold,
+			review_threshold = EXCLUDED.review_threshold,
+			updated_at = now(),
+			updated_by = EXCLUDED.updated_by
+		RETURNING ` + recognitionThresholdColumns
+
+	out, err := scanRecognitionThreshold(r.db.QueryRow(ctx, query,
+		rule.DepartmentID,
+		rule.Thresholds.Accept,
+		rule.Thresholds.Review,
+		rule.UpdatedBy,
+	))
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "recognition_thresholds_department_id_fkey" {
+		return out, domain.ErrorDepartmentNotFound
+	}
+	return out, err
+}
+
+func (r *recognitionThresholdRepository) Delete(ctx context.Context, departmentID int64) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM universities_data.recognition_thresholds WHERE department_id = $1`, departmentID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRecognitionThresholdNotFound
+	}
+	return nil
+}
+
+func scanRecognitionThreshold(row pgx.Row) (domain.RecognitionThresholdRule, error) {
+	var rule domain.RecognitionThresholdRule
+	err := row.Scan(
+		&rule.DepartmentID,
+		&rule.Thresholds.Accept,
+		&rule.Thresholds.Review,
+		&rule.UpdatedAt,
+		&rule.UpdatedBy,
+	)
+	return rule, err
+}
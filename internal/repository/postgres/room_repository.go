@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"monitoring_backend/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type roomRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRoomRepository(db *pgxpool.Pool) RoomRepository {
+	return &roomRepository{db: db}
+}
+
+func (r *roomRepository) Create(ctx context.Context, room domain.Room) error {
+	query := `
+		INSERT INTO universities_data.rooms (code, building, capacity, queue)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+	`
+
+	_, err := r.db.Exec(ctx, query, room.Code, room.Building, room.Capacity, room.Queue)
+	return err
+}
+
+func (r *roomRepository) GetByCode(ctx context.Context, code string) (domain.Room, error) {
+	query := `
+		SELECT code, building, capacity, COALESCE(queue, '')
+		FROM universities_data.rooms
+		WHERE code = $1
+	`
+
+	var room domain.Room
+	err := r.db.QueryRow(ctx, query, code).Scan(&room.Code, &room.Building, &room.Capacity, &room.Queue)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return room, domain.ErrRoomNotFound
+	}
+	if err != nil {
+		return room, err
+	}
+
+	cameras, err := r.listCameras(ctx, []string{code})
+	if err != nil {
+		return room, err
+	}
+	room.Cameras = cameras[code]
+
+	return room, nil
+}
+
+func (r *roomRepository) List(ctx context.Context) ([]domain.Room, error) {
+	query := `
+		SELECT code, building, capacity, COALESCE(queue, '')
+		FROM universities_data.rooms
+		ORDER BY code
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rooms := make([]domain.Room, 0)
+	codes := make([]string, 0)
+	for rows.Next() {
+		var room domain.Room
+		if err := rows.Scan(&room.Code, &room.Building, &room.Capacity, &room.Queue); err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+		codes = append(codes, room.Code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cameras, err := r.listCameras(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rooms {
+		rooms[i].Cameras = cameras[rooms[i].Code]
+	}
+
+	return rooms, nil
+}
+
+func (r *roomRepository) Update(ctx context.Context, room domain.Room) error {
+	query := `
+		UPDATE universities_data.rooms
+		SET building = $2, capacity = $3, queue = NULLIF($4, '')
+		WHERE code = $1
+	`
+
+	tag, err := r.db.Exec(ctx, query, room.Code, room.Building, room.Capacity, room.Queue)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoomNotFound
+	}
+	return nil
+}
+
+func (r *roomRepository) Delete(ctx context.Context, code string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM universities_data.rooms WHERE code = $1`, code)
+
+	// fk_classes_room: в аудитории есть занятия
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return domain.ErrRoomInUse
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoomNotFound
+	}
+	return nil
+}
+
+func (r *roomRepository) ClassRoute(ctx context.Context, kind domain.ClassKind, classID int64) (string, error) {
+	query := `
+		SELECT COALESCE(rm.queue, '')
+		FROM universities_data.classes c
+		LEFT JOIN universities_data.rooms rm ON rm.code = c.room
+		WHERE c.kind = $1 AND c.id = $2
+	`
+
+	var route string
+	err := r.db.QueryRow(ctx, query, kind, classID).Scan(&route)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", domain.ErrClassNotFound
+	}
+	return route, err
+}
+
+func (r *roomRepository) AddCamera(ctx context.Context, camera domain.Camera) (domain.Camera, error) {
+	query := `
+		INSERT INTO universities_data.cameras (id, room_code, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, room_code, description, created_at
+	`
+
+	var out domain.Camera
+	err := r.db.QueryRow(ctx, query, camera.ID, camera.RoomCode, camera.Description).
+		Scan(&out.ID, &out.RoomCode, &out.Description, &out.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return out, domain.ErrRoomNotFound
+	}
+	return out, err
+}
+
+func (r *roomRepository) DeleteCamera(ctx context.Context, roomCode, cameraID string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM universities_data.cameras WHERE room_code = $1 AND id = $2`, roomCode, cameraID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCameraNotFound
+	}
+	return nil
+}
+
+// listCameras камеры аудиторий codes по коду аудитории.
+func (r *roomRepository) listCameras(ctx context.Context, codes []string) (map[string][]domain.Camera, error) {
+	query := `
+		SELECT id, room_code, description, created_at
+		FROM universities_data.cameras
+		WHERE room_code = ANY($1)
+		ORDER BY room_code, id
+	`
+
+	rows, err := r.db.Query(ctx, query, codes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]domain.Camera, len(codes))
+	for rows.Next() {
+		var c domain.Camera
+		if err := rows.Scan(&c.ID, &c.RoomCode, &c.Description, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		out[c.RoomCode] = append(out[c.RoomCode], c)
+	}
+
+	return out, rows.Err()
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"monitoring_backend/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type sightingReviewRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSightingReviewRepository(db *pgxpool.Pool) SightingReviewRepository {
+	return &sightingReviewRepository{db: db}
+}
+
+// pendingSightingColumns колонки снапшота на проверке p вместе со студентом u и его группой sg.
+const pendingSightingColumns = `p.id, p.kind, p.class_id, p.user_id, p.captured_at, p.received_at, p.camera_id, p.confidence,
+	p.status, p.reviewed_by, p.reviewed_at, u.last_name, u.first_name, u.patronymic, sg.group_code`
+
+func (r *sightingReviewRepository) List(ctx context.Context, filter domain.PendingSightingFilter) ([]domain.PendingSighting, int, error) {
+	limit := filter.PageSize
+	offset := (filter.Page - 1) * filter.PageSize
+
+	totalQuery := `
+		SELECT COUNT(*)
+		FROM visits.pending_sightings
+		WHERE kind = $1 AND class_id = $2
+		  AND ($3::text IS NULL OR status = $3);
+	`
+
+	var total int
+	if err := r.db.QueryRow(ctx, totalQuery, filter.Kind, filter.ClassID, filter.Status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery := `
+		SELECT ` + pendingSightingColumns + `
+		FROM visits.pending_sightings p
+		JOIN cores.users u ON u.isu = p.user_id
+		LEFT JOIN universities_data.students_groups sg ON sg.user_id = p.user_id
+		WHERE p.kind = $1 AND p.class_id = $2
+		  AND ($3::text IS NULL OR p.status = $3)
+		ORDER BY p.captured_at, p.id
+		LIMIT $4 OFFSET $5;
+	`
+
+	rows, err := r.db.Query(ctx, listQuery, filter.Kind, filter.ClassID, filter.Status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items, err := scanPendingSightings(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+func (r *sightingReviewRepository) Review(
+	ctx context.Context,
+	kind domain.ClassKind,
+	classID int64,
+	ids []int64,
+	status domain.SightingStatus,
+	reviewedBy *string,
+) (out []domain.PendingSighting, confirmed []domain.ClassVisit, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+		} else if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	const reviewQuery = `
+		WITH p AS (
+			UPDATE visits.pending_sightings
+			SET status = $4, reviewed_by = $5, reviewed_at = now()
+			WHERE kind = $1 AND class_id = $2 AND id = ANY($3) AND status = 'pending'
+			RETURNING *
+		)
+		SELECT ` + pendingSightingColumns + `
+		FROM p
+		JOIN cores.users u ON u.isu = p.user_id
+		LEFT JOIN universities_data.students_groups sg ON sg.user_id = p.user_id
+		ORDER BY p.captured_at, p.id
+	`
+
+	rows, err := tx.Query(ctx, reviewQuery, kind, classID, ids, status, reviewedBy)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err = scanPendingSightings(rows)
+	rows.Close()
+	if err != nil || status != domain.SightingConfirmed || len(out) == 0 {
+		return out, nil, err
+	}
+
+	reviewed := make([]int64, 0, len(out))
+	for _, s := range out {
+		reviewed = append(reviewed, s.Visit.ID)
+	}
+
+	// подтверждённый снапшот становится обычным и учитывается в присутствии
+	const confirmQuery = `
+		INSERT INTO visits.classes_visiting (kind, class_id, user_id, captured_at, received_at, camera_id, confidence)
+		SELECT kind, class_id, user_id, captured_at, received_at, camera_id, confidence
+		FROM visits.pending_sightings
+		WHERE id = ANY($1)
+		ON CONFLICT (kind, class_id, user_id, captured_at) DO NOTHING
+		RETURNING ` + classVisitColumns + `
+	`
+
+	rows, err = tx.Query(ctx, confirmQuery, reviewed)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	confirmed, err = scanClassVisits(rows)
+	return out, confirmed, err
+}
+
+func scanPendingSightings(rows pgx.Rows) ([]domain.PendingSighting, error) {
+	items := make([]domain.PendingSighting, 0)
+	for rows.Next() {
+		var s domain.PendingSighting
+		if err := rows.Scan(
+			&s.Visit.ID,
+			&s.Kind,
+			&s.Visit.LectureID,
+			&s.Visit.UserID,
+			&s.Visit.CapturedAt,
+			&s.Visit.ReceivedAt,
+			&s.Visit.CameraID,
+			&s.Visit.Confidence,
+			&s.Status,
+			&s.ReviewedBy,
+			&s.ReviewedAt,
+			&s.User.LastName,
+			&s.User.FirstName,
+			&s.User.Patronymic,
+			&s.User.GroupCode,
+		); err != nil {
+			return nil, err
+		}
+		s.User.ISU = s.Visit.UserID
+		items = append(items, s)
+	}
+
+	return items, rows.Err()
+}
@@ -32,30 +32,58 @@ type SubjectRepository interface {
 	List(ctx context.Context, limit, offset int) ([]domain.Subject, error)
 }
 
-type LectureRepository interface {
-	Create(ctx context.Context, l domain.Lecture) (int64, error)
-	GetByID(ctx context.Context, id int64) (domain.Lecture, error)
-	ListByTeacher(ctx context.Context, teacherID string, from, to time.Time) ([]domain.Lecture, error)
-	ListBySubject(ctx context.Context, subjectID int64, from, to time.Time) ([]domain.Lecture, error)
+// ClassRepository занятия всех видов; id уникален в пределах вида.
+type ClassRepository interface {
+	Create(ctx context.Context, c domain.Class) (int64, error)
+	GetByID(ctx context.Context, kind domain.ClassKind, id int64) (domain.Class, error)
+	ListByTeacher(ctx context.Context, kind domain.ClassKind, teacherID string, from, to time.Time) ([]domain.Class, error)
+	ListBySubject(ctx context.Context, kind domain.ClassKind, subjectID int64, from, to time.Time) ([]domain.Class, error)
+	// SetRoom "" — занятие без аудитории; domain.ErrRoomNotFound, если аудитории нет в справочнике.
+	SetRoom(ctx context.Context, kind domain.ClassKind, id int64, room string) error
 }
 
-type LectureGroupRepository interface {
-	AddGroup(ctx context.Context, lectureID int64, groupCode string) error
-	RemoveGroup(ctx context.Context, lectureID int64, groupCode string) error
-	ListGroups(ctx context.Context, lectureID int64) ([]string, error)
-	ListLecturesByGroup(ctx context.Context, groupCode string, from, to time.Time) ([]domain.Lecture, error)
+type ClassGroupRepository interface {
+	AddGroup(ctx context.Context, kind domain.ClassKind, classID int64, groupCode string) error
+	RemoveGroup(ctx context.Context, kind domain.ClassKind, classID int64, groupCode string) error
+	ListGroups(ctx context.Context, kind domain.ClassKind, classID int64) ([]string, error)
+	ListByGroup(ctx context.Context, kind domain.ClassKind, groupCode string, from, to time.Time) ([]domain.Class, error)
 }
 
-type PracticeRepository interface {
-	Create(ctx context.Context, p domain.Practice) error
-	GetByID(ctx context.Context, id int64) (domain.Practice, error)
-	ListByTeacher(ctx context.Context, teacherID string, from, to time.Time) ([]domain.Practice, error)
-	ListBySubject(ctx context.Context, subjectID int64, from, to time.Time) ([]domain.Practice, error)
+// AttendancePolicyRepository пороги статуса посещаемости для предметов и департаментов.
+type AttendancePolicyRepository interface {
+	// Resolve политика предмета, а без неё — департамента группы groupCode.
+	// false — ни одной политики нет.
+	Resolve(ctx context.Context, subjectID int64, groupCode *string) (domain.AttendancePolicy, bool, error)
+	List(ctx context.Context) ([]domain.AttendancePolicyRule, error)
+	// Upsert создаёт или заменяет политику rule.SubjectID либо rule.DepartmentID.
+	Upsert(ctx context.Context, rule domain.AttendancePolicyRule) (domain.AttendancePolicyRule, error)
+	// Delete удаляет политику предмета или департамента; domain.ErrAttendancePolicyNotFound, если её нет.
+	Delete(ctx context.Context, subjectID, departmentID *int64) error
 }
 
-type PracticeGroupRepository interface {
-	AddGroup(ctx context.Context, practiceID int64, groupCode string) error
-	RemoveGroup(ctx context.Context, practiceID int64, groupCode string) error
-	ListGroups(ctx context.Context, practiceID int64) ([]string, error)
-	ListPracticesByGroup(ctx context.Context, groupCode string, from, to time.Time) ([]domain.Practice, error)
+// RecognitionThresholdRepository пороги similarity распознавания для департаментов.
+type RecognitionThresholdRepository interface {
+	// ByStudents пороги департаментов групп студентов isus; студентов без порогов в результате нет.
+	ByStudents(ctx context.Context, isus []string) (map[string]domain.RecognitionThresholds, error)
+	List(ctx context.Context) ([]domain.RecognitionThresholdRule, error)
+	// Upsert domain.ErrorDepartmentNotFound, если департамента нет.
+	Upsert(ctx context.Context, rule domain.RecognitionThresholdRule) (domain.RecognitionThresholdRule, error)
+	// Delete domain.ErrRecognitionThresholdNotFound, если у департамента нет своих порогов.
+	Delete(ctx context.Context, departmentID int64) error
+}
+
+// RoomRepository справочник аудиторий и их камер.
+type RoomRepository interface {
+	Create(ctx context.Context, room domain.Room) error
+	// GetByCode аудитория вместе с камерами.
+	GetByCode(ctx context.Context, code string) (domain.Room, error)
+	List(ctx context.Context) ([]domain.Room, error)
+	Update(ctx context.Context, room domain.Room) error
+	// Delete domain.ErrRoomInUse, если в аудитории есть занятия; камеры удаляются вместе с ней.
+	Delete(ctx context.Context, code string) error
+	// ClassRoute очередь аудитории занятия; "" — у занятия нет аудитории или у аудитории нет очереди.
+	ClassRoute(ctx context.Context, kind domain.ClassKind, classID int64) (string, error)
+
+	AddCamera(ctx context.Context, camera domain.Camera) (domain.Camera, error)
+	DeleteCamera(ctx context.Context, roomCode, cameraID string) error
 }
@@ -8,25 +8,100 @@ import (
 	"monitoring_backend/internal/domain"
 )
 
-type LectureVisitRepository interface {
-	Add(ctx context.Context, v domain.LectureVisit) (*domain.User, error)
+// ClassVisitRepository снапшоты и посещаемость занятий одного вида (см. NewClassVisitsRepository).
+type ClassVisitRepository interface {
+	// AddBatch вставляет снапшоты одним запросом, пропуская уже записанные
+	// (kind, class_id, user_id, captured_at). Возвращает только реально вставленные строки.
+	AddBatch(ctx context.Context, visits []domain.ClassVisit) ([]domain.ClassVisit, error)
+	// AddPendingBatch то же для снапшотов на проверке (visits.pending_sightings).
+	AddPendingBatch(ctx context.Context, visits []domain.ClassVisit) ([]domain.ClassVisit, error)
+	ListStudentsByISU(ctx context.Context, isus []string) (map[string]domain.User, error)
+	// Presence присутствие каждого студента групп занятия (и всех, кто на нём распознан)
+	// со склейкой снапшотов через gapSeconds. Второе значение — id последнего снапшота занятия.
+	Presence(ctx context.Context, lectureID int64, gapSeconds int) ([]domain.StudentPresence, int64, error)
+	// ListVisitsSince снапшоты занятия с id больше afterID по возрастанию id.
+	ListVisitsSince(ctx context.Context, lectureID int64, afterID int64, limit int) ([]domain.ClassVisitDetails, error)
 	Exists(ctx context.Context, lectureID int64, userID string) (bool, error)
-	ListByLecture(ctx context.Context, lectureID int64) ([]domain.LectureVisit, error)
-	ListByUser(ctx context.Context, userID string, from, to time.Time) ([]domain.LectureVisit, error)
+	// ListByClass сырые снапшоты занятия по captured_at с пагинацией; второе значение — всего по фильтру.
+	ListByClass(ctx context.Context, lectureID int64, filter domain.ClassVisitFilter) ([]domain.ClassVisit, int, error)
+	// ListByUser снапшоты студента на занятиях этого вида за [from, to].
+	ListByUser(ctx context.Context, userID string, from, to time.Time) ([]domain.ClassVisit, error)
 
 	ListVisitedSubjectsByISU(ctx context.Context, isu string) ([]domain.Subject, error)
-	ListStudentLecturesBySubject(ctx context.Context, isu string, subjectID int64, filter visits.GetLecturesFilter) ([]visits.LectureAttendance, int, error)
+	ListStudentClassesBySubject(ctx context.Context, isu string, subjectID int64, filter visits.GetLecturesFilter) ([]visits.ClassAttendance, int, error)
 
-	ListTeacherLecturesBySubject(ctx context.Context, teacherISU string, subjectID int64, filter visits.TeacherLecturesFilter) ([]visits.TeacherLecture, int, error)
-	ListLectureGroups(ctx context.Context, teacherISU string, lectureID int64) ([]string, error)
-	ListLectureGroupStudents(ctx context.Context, teacherISU string, lectureID int64, groupCode string, page int, pageSize int, gapSeconds int) ([]visits.StudentOnLecture, int, error)
+	ListTeacherClassesBySubject(ctx context.Context, teacherISU string, subjectID int64, filter visits.TeacherLecturesFilter) ([]visits.TeacherClass, int, error)
+	ListClassGroups(ctx context.Context, teacherISU string, lectureID int64) ([]string, error)
+	ListClassGroupStudents(ctx context.Context, teacherISU string, lectureID int64, groupCode string, page int, pageSize int, gapSeconds int) ([]visits.StudentOnLecture, int, error)
 
 	ListTeacherSubjects(ctx context.Context, teacherISU string) ([]visits.SubjectDTO, error)
 }
 
-type PracticeVisitRepository interface {
-	Add(ctx context.Context, v domain.PracticeVisit) error
-	Exists(ctx context.Context, practiceID int64, userID string) (bool, error)
-	ListByPractice(ctx context.Context, practiceID int64) ([]domain.PracticeVisit, error)
-	ListByUser(ctx context.Context, userID string, from, to time.Time) ([]domain.PracticeVisit, error)
+type ClassSessionRepository interface {
+	Start(ctx context.Context, s domain.ClassSession) (domain.ClassSession, error)
+	Finish(ctx context.Context, lectureID int64, state domain.ClassSessionState, stoppedBy *string) (domain.ClassSession, error)
+	ListRunning(ctx context.Context) ([]domain.ClassSession, error)
+	List(ctx context.Context, filter domain.ClassSessionFilter) ([]domain.ClassSession, int, error)
+}
+
+// ClassScheduleRepository данные планировщика мониторинга по всем видам занятий.
+type ClassScheduleRepository interface {
+	// ListDueToStart занятия в режиме auto, которые уже пора мониторить (начало не позже now+lead,
+	// конец позже now) и у которых нет сессии или последняя завершилась без пользователя:
+	// упала или закончилась сама. Остановленное пользователем занятие не возвращается.
+	ListDueToStart(ctx context.Context, now time.Time, lead time.Duration) ([]domain.Class, error)
+	// ListDueToStop запущенные сессии занятий в режиме auto, закончившихся не позже now-grace.
+	ListDueToStop(ctx context.Context, now time.Time, grace time.Duration) ([]domain.ClassSession, error)
+	// GetOverride возвращает pgx.ErrNoRows, если режим занятия не менялся.
+	GetOverride(ctx context.Context, kind domain.ClassKind, classID int64) (domain.ScheduleOverride, error)
+	// SetOverride сохраняет режим и пишет его смену в журнал одной транзакцией.
+	SetOverride(ctx context.Context, o domain.ScheduleOverride) (domain.ScheduleOverride, error)
+	AddAudit(ctx context.Context, e domain.ScheduleAuditEntry) error
+	ListAudit(ctx context.Context, filter domain.ScheduleAuditFilter) ([]domain.ScheduleAuditEntry, int, error)
+}
+
+// CameraStatusRepository состояние камер по heartbeat и camera_status из очереди распознавания.
+type CameraStatusRepository interface {
+	Heartbeat(ctx context.Context, hb domain.CameraHeartbeat) error
+	Report(ctx context.Context, r domain.CameraReport) error
+	// List камеры справочника вместе с камерами, которые присылают сообщения, но в справочник не добавлены.
+	// roomCode nil — все аудитории.
+	List(ctx context.Context, roomCode *string) ([]domain.CameraStatus, error)
+	// ClaimOutages помечает отказавшие камеры аудиторий, где идут лекции (камера сама прислала down
+	// или heartbeat старше timeout), про которые этим лекциям ещё не сообщали, и возвращает их
+	// вместе с запущенными лекциями аудитории. Один отказ получает только одна реплика.
+	ClaimOutages(ctx context.Context, now time.Time, timeout time.Duration) ([]domain.CameraOutage, error)
+}
+
+// SightingReviewRepository снапшоты на проверке у преподавателя по всем видам занятий.
+type SightingReviewRepository interface {
+	List(ctx context.Context, filter domain.PendingSightingFilter) ([]domain.PendingSighting, int, error)
+	// Review переводит pending-снапшоты занятия в status одной транзакцией; подтверждённые копируются
+	// в visits.classes_visiting. Возвращает только реально изменённые и снапшоты, записанные при подтверждении
+	// (повторы уже учтённых снапшотов не записываются).
+	Review(ctx context.Context, kind domain.ClassKind, classID int64, ids []int64, status domain.SightingStatus, reviewedBy *string) ([]domain.PendingSighting, []domain.ClassVisit, error)
+}
+
+// AttendanceOverrideRepository ручные отметки посещаемости по всем видам занятий и их журнал.
+type AttendanceOverrideRepository interface {
+	// Set ставит или меняет отметку и пишет её в журнал вместе с прежним статусом одной транзакцией.
+	// Студент не из групп занятия — domain.ErrStudentNotFound.
+	Set(ctx context.Context, o domain.AttendanceOverride) (domain.AttendanceOverride, error)
+	// Clear снимает отметку и пишет это в журнал; без отметки — domain.ErrAttendanceOverrideNotFound.
+	Clear(ctx context.Context, kind domain.ClassKind, classID int64, userID string, reason, actor *string) (domain.AttendanceOverride, error)
+	// ListByClass отметки занятия вместе со студентами.
+	ListByClass(ctx context.Context, kind domain.ClassKind, classID int64) ([]domain.AttendanceOverride, error)
+	// ListByStudents отметки студентов userIDs на занятиях classIDs.
+	ListByStudents(ctx context.Context, kind domain.ClassKind, classIDs []int64, userIDs []string) ([]domain.AttendanceOverride, error)
+	ListAudit(ctx context.Context, filter domain.AttendanceAuditFilter) ([]domain.AttendanceAuditEntry, int, error)
+}
+
+type DeadLetterRepository interface {
+	Add(ctx context.Context, dl domain.DeadLetter) (int64, error)
+	GetByID(ctx context.Context, id int64) (domain.DeadLetter, error)
+	ListByIDs(ctx context.Context, ids []int64) ([]domain.DeadLetter, error)
+	List(ctx context.Context, filter domain.DeadLetterFilter) ([]domain.DeadLetter, int, error)
+	// Resolve переводит pending-сообщения в status. Возвращает id реально изменённых.
+	Resolve(ctx context.Context, ids []int64, status domain.DeadLetterStatus, resolvedBy *string) ([]int64, error)
+	RecordReplayFailure(ctx context.Context, id int64, reason, errText string) error
 }
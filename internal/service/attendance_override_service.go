@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+
+	"monitoring_backend/internal/domain"
+	aodto "monitoring_backend/internal/http/handlers/attendance_override"
+	postgres "monitoring_backend/internal/repository/postgres"
+)
+
+// AttendanceOverrideService ручные отметки посещаемости преподавателем и их журнал.
+type AttendanceOverrideService struct {
+	classes   postgres.ClassRepository
+	overrides postgres.AttendanceOverrideRepository
+}
+
+func NewAttendanceOverrideService(classes postgres.ClassRepository, overrides postgres.AttendanceOverrideRepository) *AttendanceOverrideService {
+	return &AttendanceOverrideService{
+		classes:   classes,
+		overrides: overrides,
+	}
+}
+
+func (s *AttendanceOverrideService) List(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) (aodto.ListOverridesResponse, error) {
+	if _, err := authorizeClass(ctx, s.classes, userID, role, kind, classID); err != nil {
+		return aodto.ListOverridesResponse{}, err
+	}
+
+	overrides, err := s.overrides.ListByClass(ctx, kind, classID)
+	if err != nil {
+		return aodto.ListOverridesResponse{}, err
+	}
+
+	items := make([]aodto.OverrideItem, 0, len(overrides))
+	for _, o := range overrides {
+		items = append(items, aodto.OverrideItem{
+			ISU:        o.UserID,
+			FirstName:  o.User.FirstName,
+			LastName:   o.User.LastName,
+			Patronymic: o.User.Patronymic,
+			Group:      o.User.GroupCode,
+			Status:     string(o.Status),
+			Reason:     o.Reason,
+			UpdatedAt:  o.UpdatedAt,
+			UpdatedBy:  o.UpdatedBy,
+		})
+	}
+
+	return aodto.ListOverridesResponse{
+		Kind:    string(kind),
+		ClassID: classID,
+		Items:   items,
+	}, nil
+}
+
+func (s *AttendanceOverrideService) Set(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, isu string, req aodto.SetOverrideRequest) (aodto.OverrideResponse, error) {
+	if _, err := authorizeClass(ctx, s.classes, userID, role, kind, classID); err != nil {
+		return aodto.OverrideResponse{}, err
+	}
+
+	o, err := s.overrides.Set(ctx, domain.AttendanceOverride{
+		Kind:      kind,
+		ClassID:   classID,
+		UserID:    isu,
+		Status:    domain.AttendanceStatus(req.Status),
+		Reason:    req.Reason,
+		UpdatedBy: &userID,
+	})
+	if err != nil {
+		return aodto.OverrideResponse{}, err
+	}
+
+	return toOverrideResponse(o), nil
+}
+
+func (s *AttendanceOverrideService) Clear(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, isu string, reason *string) (aodto.OverrideResponse, error) {
+	if _, err := authorizeClass(ctx, s.classes, userID, role, kind, classID); err != nil {
+		return aodto.OverrideResponse{}, err
+	}
+
+	o, err := s.overrides.Clear(ctx, kind, classID, isu, reason, &userID)
+	if err != nil {
+		return aodto.OverrideResponse{}, err
+	}
+
+	return toOverrideResponse(o), nil
+}
+
+func (s *AttendanceOverrideService) ListAudit(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, isu *string, page, pageSize int) (aodto.ListAuditResponse, error) {
+	if _, err := authorizeClass(ctx, s.classes, userID, role, kind, classID); err != nil {
+		return aodto.ListAuditResponse{}, err
+	}
+
+	entries, total, err := s.overrides.ListAudit(ctx, domain.AttendanceAuditFilter{
+		Kind:     kind,
+		ClassID:  classID,
+		UserID:   isu,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return aodto.ListAuditResponse{}, err
+	}
+
+	items := make([]aodto.AuditItem, 0, len(entries))
+	for _, e := range entries {
+		item := aodto.AuditItem{
+			ID:        e.ID,
+			ISU:       e.UserID,
+			Action:    string(e.Action),
+			Reason:    e.Reason,
+			Actor:     e.Actor,
+			CreatedAt: e.CreatedAt,
+		}
+		if e.Status != nil {
+			status := string(*e.Status)
+			item.Status = &status
+		}
+		if e.PreviousStatus != nil {
+			previous := string(*e.PreviousStatus)
+			item.PreviousStatus = &previous
+		}
+		items = append(items, item)
+	}
+
+	return aodto.ListAuditResponse{
+		Items: items,
+		Meta: aodto.PageMeta{
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+func toOverrideResponse(o domain.AttendanceOverride) aodto.OverrideResponse {
+	return aodto.OverrideResponse{
+		Kind:      string(o.Kind),
+		ClassID:   o.ClassID,
+		ISU:       o.UserID,
+		Status:    string(o.Status),
+		Reason:    o.Reason,
+		UpdatedAt: o.UpdatedAt,
+		UpdatedBy: o.UpdatedBy,
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"monitoring_backend/internal/domain"
+	apdto "monitoring_backend/internal/http/handlers/attendance_policy"
+	postgres "monitoring_backend/internal/repository/postgres"
+)
+
+// AttendancePolicyService пороги статуса посещаемости: политика предмета, иначе департамента
+// группы студента, иначе defaults из конфига.
+type AttendancePolicyService struct {
+	repo     postgres.AttendancePolicyRepository
+	defaults domain.AttendancePolicy
+}
+
+// NewAttendancePolicyService нулевые поля defaults заменяются на domain.DefaultAttendancePolicy.
+func NewAttendancePolicyService(repo postgres.AttendancePolicyRepository, defaults domain.AttendancePolicy) *AttendancePolicyService {
+	if defaults.LateAfter <= 0 {
+		defaults.LateAfter = domain.DefaultAttendancePolicy.LateAfter
+	}
+	if defaults.LeftEarlyAfter <= 0 {
+		defaults.LeftEarlyAfter = domain.DefaultAttendancePolicy.LeftEarlyAfter
+	}
+	if defaults.MinCoveragePercent <= 0 {
+		defaults.MinCoveragePercent = domain.DefaultAttendancePolicy.MinCoveragePercent
+	}
+	return &AttendancePolicyService{
+		repo:     repo,
+		defaults: defaults,
+	}
+}
+
+// Resolve политика для студента группы groupCode на занятии предмета subjectID.
+func (s *AttendancePolicyService) Resolve(ctx context.Context, subjectID int64, groupCode *string) (domain.AttendancePolicy, error) {
+	p, ok, err := s.repo.Resolve(ctx, subjectID, groupCode)
+	if err != nil {
+		return domain.AttendancePolicy{}, err
+	}
+	if !ok {
+		return s.defaults, nil
+	}
+	return p, nil
+}
+
+func (s *AttendancePolicyService) List(ctx context.Context) (apdto.ListPoliciesResponse, error) {
+	rules, err := s.repo.List(ctx)
+	if err != nil {
+		return apdto.ListPoliciesResponse{}, err
+	}
+
+	items := make([]apdto.PolicyResponse, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, toPolicyResponse(rule))
+	}
+
+	return apdto.ListPoliciesResponse{
+		Default: toPolicyRequest(s.defaults),
+		Items:   items,
+	}, nil
+}
+
+func (s *AttendancePolicyService) SetForSubject(ctx context.Context, subjectID int64, req apdto.PolicyRequest, by *string) (apdto.PolicyResponse, error) {
+	rule, err := s.repo.Upsert(ctx, domain.AttendancePolicyRule{
+		SubjectID: &subjectID,
+		Policy:    fromPolicyRequest(req),
+		UpdatedBy: by,
+	})
+	if err != nil {
+		return apdto.PolicyResponse{}, err
+	}
+	return toPolicyResponse(rule), nil
+}
+
+func (s *AttendancePolicyService) SetForDepartment(ctx context.Context, departmentID int64, req apdto.PolicyRequest, by *string) (apdto.PolicyResponse, error) {
+	rule, err := s.repo.Upsert(ctx, domain.AttendancePolicyRule{
+		DepartmentID: &departmentID,
+		Policy:       fromPolicyRequest(req),
+		UpdatedBy:    by,
+	})
+	if err != nil {
+		return apdto.PolicyResponse{}, err
+	}
+	return toPolicyResponse(rule), nil
+}
+
+func (s *AttendancePolicyService) DeleteForSubject(ctx context.Context, subjectID int64) error {
+	return s.repo.Delete(ctx, &subjectID, nil)
+}
+
+func (s *AttendancePolicyService) DeleteForDepartment(ctx context.Context, departmentID int64) error {
+	return s.repo.Delete(ctx, nil, &departmentID)
+}
+
+func fromPolicyRequest(req apdto.PolicyRequest) domain.AttendancePolicy {
+	return domain.AttendancePolicy{
+		LateAfter:          time.Duration(req.LateAfterSeconds) * time.Second,
+		LeftEarlyAfter:     time.Duration(req.LeftEarlyAfterSeconds) * time.Second,
+		MinCoveragePercent: req.MinCoveragePercent,
+	}
+}
+
+func toPolicyRequest(p domain.AttendancePolicy) apdto.PolicyRequest {
+	return apdto.PolicyRequest{
+		LateAfterSeconds:      int(p.LateAfter / time.Second),
+		LeftEarlyAfterSeconds: int(p.LeftEarlyAfter / time.Second),
+		MinCoveragePercent:    p.MinCoveragePercent,
+	}
+}
+
+func toPolicyResponse(rule domain.AttendancePolicyRule) apdto.PolicyResponse {
+	p := toPolicyRequest(rule.Policy)
+	return apdto.PolicyResponse{
+		ID:                    rule.ID,
+		SubjectID:             rule.SubjectID,
+		DepartmentID:          rule.DepartmentID,
+		LateAfterSeconds:      p.LateAfterSeconds,
+		LeftEarlyAfterSeconds: p.LeftEarlyAfterSeconds,
+		MinCoveragePercent:    p.MinCoveragePercent,
+		UpdatedAt:             rule.UpdatedAt,
+		UpdatedBy:             rule.UpdatedBy,
+	}
+}
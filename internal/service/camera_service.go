@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"monitoring_backend/internal/domain"
+	camdto "monitoring_backend/internal/http/handlers/camera"
+	postgres "monitoring_backend/internal/repository/postgres"
+)
+
+type CameraService struct {
+	repo             postgres.CameraStatusRepository
+	heartbeatTimeout time.Duration
+}
+
+func NewCameraService(repo postgres.CameraStatusRepository, heartbeatTimeout time.Duration) *CameraService {
+	if heartbeatTimeout <= 0 {
+		heartbeatTimeout = domain.DefaultCameraHeartbeatTimeout
+	}
+	return &CameraService{repo: repo, heartbeatTimeout: heartbeatTimeout}
+}
+
+func (s *CameraService) Status(ctx context.Context, roomCode *string) ([]camdto.CameraStatusResponse, error) {
+	statuses, err := s.repo.List(ctx, roomCode)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	out := make([]camdto.CameraStatusResponse, 0, len(statuses))
+	for _, st := range statuses {
+		out = append(out, camdto.CameraStatusResponse{
+			CameraID:    st.CameraID,
+			Room:        st.RoomCode,
+			Registered:  st.Registered,
+			Status:      string(st.Health(now, s.heartbeatTimeout)),
+			LastSeenAt:  st.LastSeenAt,
+			FPS:         st.FPS,
+			ErrorCount:  st.ErrorCount,
+			LastError:   st.LastError,
+			LastErrorAt: st.LastErrorAt,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"monitoring_backend/internal/domain"
+	postgres "monitoring_backend/internal/repository/postgres"
+)
+
+// ClassAccessService решает, кто может видеть посещаемость занятия в реальном времени:
+// администратор или преподаватель, который это занятие ведёт.
+type ClassAccessService struct {
+	classes postgres.ClassRepository
+}
+
+func NewClassAccessService(classes postgres.ClassRepository) *ClassAccessService {
+	return &ClassAccessService{classes: classes}
+}
+
+// AuthorizeClass возвращает domain.ErrClassNotFound или domain.ErrClassAccessDenied при отказе.
+func (s *ClassAccessService) AuthorizeClass(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) error {
+	_, err := authorizeClass(ctx, s.classes, userID, role, kind, classID)
+	return err
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"monitoring_backend/internal/domain"
+	cldto "monitoring_backend/internal/http/handlers/class"
+	postgres "monitoring_backend/internal/repository/postgres"
+)
+
+// ClassService CRUD занятий любого вида; лекции и практики доступны и через свои сервисы.
+type ClassService struct {
+	classes postgres.ClassRepository
+	groups  postgres.ClassGroupRepository
+}
+
+func NewClassService(classes postgres.ClassRepository, groups postgres.ClassGroupRepository) *ClassService {
+	return &ClassService{
+		classes: classes,
+		groups:  groups,
+	}
+}
+
+func (s *ClassService) Create(ctx context.Context, req cldto.CreateClassRequest) (cldto.ClassResponse, error) {
+	kind := domain.ClassKind(req.Kind)
+	groups := uniqueStrings(req.GroupIDs)
+
+	c := domain.Class{
+		ID:        req.ID,
+		Kind:      kind,
+		Date:      req.Date,
+		SubjectID: req.SubjectID,
+		TeacherID: req.TeacherID,
+		EndsAt:    req.EndsAt,
+		Room:      req.Room,
+		Online:    req.Online,
+	}
+	id, err := s.classes.Create(ctx, c)
+	if err != nil {
+		return cldto.ClassResponse{}, err
+	}
+
+	for _, g := range groups {
+		if err := s.groups.AddGroup(ctx, kind, id, g); err != nil {
+			return cldto.ClassResponse{}, err
+		}
+	}
+
+	c.ID = id
+	return toClassResponse(c, groups), nil
+}
+
+func (s *ClassService) GetByID(ctx context.Context, req cldto.GetClassByIDRequest) (cldto.ClassResponse, error) {
+	kind := domain.ClassKind(req.Kind)
+
+	c, err := s.classes.GetByID(ctx, kind, req.ID)
+	if err != nil {
+		return cldto.ClassResponse{}, err
+	}
+
+	groups, err := s.groups.ListGroups(ctx, kind, req.ID)
+	if err != nil {
+		return cldto.ClassResponse{}, err
+	}
+
+	return toClassResponse(c, groups), nil
+}
+
+func (s *ClassService) List(ctx context.Context, req cldto.ListClassesRequest) ([]cldto.ClassListItemResponse, error) {
+	kind := domain.ClassKind(req.Kind)
+
+	var (
+		cs  []domain.Class
+		err error
+	)
+	switch {
+	case req.TeacherID != "":
+		cs, err = s.classes.ListByTeacher(ctx, kind, req.TeacherID, req.From, req.To)
+	case req.SubjectID > 0:
+		cs, err = s.classes.ListBySubject(ctx, kind, req.SubjectID, req.From, req.To)
+	default:
+		cs, err = s.groups.ListByGroup(ctx, kind, req.GroupCode, req.From, req.To)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]cldto.ClassListItemResponse, 0, len(cs))
+	for _, c := range cs {
+		out = append(out, toClassListItem(c))
+	}
+	return out, nil
+}
+
+// SetRoom аудиторию занятия меняет администратор или преподаватель занятия: по ней выбирается очередь мониторинга.
+func (s *ClassService) SetRoom(ctx context.Context, userID, role string, req cldto.SetClassRoomRequest) (cldto.ClassResponse, error) {
+	if _, err := authorizeClass(ctx, s.classes, userID, role, domain.ClassKind(req.Kind), req.ID); err != nil {
+		return cldto.ClassResponse{}, err
+	}
+	if err := s.classes.SetRoom(ctx, domain.ClassKind(req.Kind), req.ID, req.Room); err != nil {
+		return cldto.ClassResponse{}, err
+	}
+	return s.GetByID(ctx, cldto.GetClassByIDRequest{Kind: req.Kind, ID: req.ID})
+}
+
+// authorizeClass занятием управляет администратор или преподаватель, который его ведёт.
+func authorizeClass(ctx context.Context, classes postgres.ClassRepository, userID, role string, kind domain.ClassKind, classID int64) (domain.Class, error) {
+	c, err := classes.GetByID(ctx, kind, classID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Class{}, domain.ErrClassNotFound
+	}
+	if err != nil {
+		return domain.Class{}, err
+	}
+
+	if role == domain.RoleAdmin || (role == domain.RoleTeacher && c.TeacherID == userID) {
+		return c, nil
+	}
+
+	return domain.Class{}, domain.ErrClassAccessDenied
+}
+
+func toClassResponse(c domain.Class, groups []string) cldto.ClassResponse {
+	return cldto.ClassResponse{
+		Kind:            string(c.Kind),
+		ID:              c.ID,
+		Date:            c.Date,
+		SubjectID:       c.SubjectID,
+		TeacherID:       c.TeacherID,
+		GroupIDs:        groups,
+		EndsAt:          c.End(),
+		DurationMinutes: int(c.Duration().Minutes()),
+		Room:            c.Room,
+		Online:          c.Online,
+	}
+}
+
+func toClassListItem(c domain.Class) cldto.ClassListItemResponse {
+	return cldto.ClassListItemResponse{
+		Kind:            string(c.Kind),
+		ID:              c.ID,
+		Date:            c.Date,
+		SubjectID:       c.SubjectID,
+		TeacherID:       c.TeacherID,
+		EndsAt:          c.End(),
+		DurationMinutes: int(c.Duration().Minutes()),
+		Room:            c.Room,
+		Online:          c.Online,
+	}
+}
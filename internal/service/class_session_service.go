@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"monitoring_backend/internal/domain"
+	csdto "monitoring_backend/internal/http/handlers/class_session"
+	"monitoring_backend/internal/lecture"
+	postgres "monitoring_backend/internal/repository/postgres"
+)
+
+// ClassSessionService запуск и остановка консьюмеров занятий через менеджер их вида.
+// Запускать и останавливать мониторинг может администратор или преподаватель занятия;
+// ручной старт и стоп пишутся в журнал расписания с пользователем.
+type ClassSessionService struct {
+	classes  postgres.ClassRepository
+	schedule postgres.ClassScheduleRepository
+	managers *lecture.Registry
+}
+
+func NewClassSessionService(classes postgres.ClassRepository, schedule postgres.ClassScheduleRepository, managers *lecture.Registry) *ClassSessionService {
+	return &ClassSessionService{
+		classes:  classes,
+		schedule: schedule,
+		managers: managers,
+	}
+}
+
+func (s *ClassSessionService) Start(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, queue string) error {
+	m, err := s.manager(kind)
+	if err != nil {
+		return err
+	}
+	if _, err := authorizeClass(ctx, s.classes, userID, role, kind, classID); err != nil {
+		return err
+	}
+
+	if _, err = m.Start(ctx, classID, queue, &userID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, userID, kind, classID, domain.ScheduleActionManualStart)
+	return nil
+}
+
+func (s *ClassSessionService) Stop(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) error {
+	m, err := s.manager(kind)
+	if err != nil {
+		return err
+	}
+	if _, err := authorizeClass(ctx, s.classes, userID, role, kind, classID); err != nil {
+		return err
+	}
+
+	if _, err = m.Stop(ctx, classID, domain.ClassSessionStopped, &userID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, userID, kind, classID, domain.ScheduleActionManualStop)
+	return nil
+}
+
+func (s *ClassSessionService) List(ctx context.Context, kind domain.ClassKind, filter domain.ClassSessionFilter) (csdto.ListSessionsResponse, error) {
+	m, err := s.manager(kind)
+	if err != nil {
+		return csdto.ListSessionsResponse{}, err
+	}
+
+	sessions, total, err := m.ListSessions(ctx, filter)
+	if err != nil {
+		return csdto.ListSessionsResponse{}, err
+	}
+
+	health := m.ConsumersHealth()
+
+	items := make([]csdto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		var status *csdto.ConsumerStatus
+		if h, ok := health[session.ID]; ok && session.State == domain.ClassSessionRunning {
+			status = &csdto.ConsumerStatus{
+				State:     string(h.State),
+				Attempt:   h.Attempt,
+				LastError: h.LastError,
+				Since:     h.Since,
+			}
+		}
+
+		item := csdto.SessionResponse{
+			ID:        session.ID,
+			Kind:      string(kind),
+			ClassID:   session.LectureID,
+			Queue:     session.Queue,
+			State:     string(session.State),
+			StartedAt: session.StartedAt,
+			StoppedAt: session.StoppedAt,
+			StartedBy: session.StartedBy,
+			StoppedBy: session.StoppedBy,
+			Consumer:  status,
+		}
+		if kind == domain.ClassLecture {
+			item.LectureID = session.LectureID
+		}
+		items = append(items, item)
+	}
+
+	return csdto.ListSessionsResponse{
+		Items: items,
+		Meta: csdto.PageMeta{
+			Page:     filter.Page,
+			PageSize: filter.PageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+// audit пишет ручной старт или стоп в журнал расписания. Консьюмер к этому моменту уже
+// запущен или остановлен, поэтому ошибка журнала только логируется.
+func (s *ClassSessionService) audit(ctx context.Context, userID string, kind domain.ClassKind, classID int64, action domain.ScheduleAction) {
+	err := s.schedule.AddAudit(ctx, domain.ScheduleAuditEntry{
+		Kind:    kind,
+		ClassID: classID,
+		Action:  action,
+		Actor:   &userID,
+	})
+	if err != nil {
+		log.Printf("ERROR: audit %s (%s id=%d) by %s: %v", action, kind, classID, userID, err)
+	}
+}
+
+// manager менеджер консьюмеров вида kind; вид без менеджера — как неизвестное занятие.
+func (s *ClassSessionService) manager(kind domain.ClassKind) (*lecture.Manager, error) {
+	m := s.managers.Manager(kind)
+	if m == nil {
+		return nil, domain.ErrClassNotFound
+	}
+	return m, nil
+}
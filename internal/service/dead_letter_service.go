@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"monitoring_backend/internal/domain"
+	dldto "monitoring_backend/internal/http/handlers/dead_letter"
+	"monitoring_backend/internal/rabbit"
+	"monitoring_backend/internal/repository/postgres"
+)
+
+const (
+	defaultBulkLimit = 100
+	maxBulkLimit     = 500
+	// replayWorkers сообщений переигрываются параллельно, чтобы writer собирал их в пачки,
+	// а не ждал flush_interval на каждое сообщение
+	replayWorkers = 100
+)
+
+// Replayer обработчик, через который сообщение из dead-letter прогоняется повторно.
+type Replayer interface {
+	Replay(ctx context.Context, lectureID int64, body []byte) error
+}
+
+type DeadLetterService struct {
+	repo     postgres.DeadLetterRepository
+	handlers map[domain.ClassKind]Replayer
+}
+
+// NewDeadLetterService handlers — конвейеры тех же видов занятий, что и у консьюмеров:
+// переигрывание прогоняет сообщение через конвейер его вида напрямую, минуя RabbitMQ.
+func NewDeadLetterService(repo postgres.DeadLetterRepository, handlers map[domain.ClassKind]Replayer) *DeadLetterService {
+	return &DeadLetterService{
+		repo:     repo,
+		handlers: handlers,
+	}
+}
+
+func (s *DeadLetterService) List(ctx context.Context, req dldto.ListDeadLettersRequest) (dldto.ListDeadLettersResponse, error) {
+	filter := domain.DeadLetterFilter{
+		Reason:    req.Reason,
+		LectureID: req.LectureID,
+		Kind:      classKind(req.Kind),
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+	}
+	if req.Status != nil {
+		status := domain.DeadLetterStatus(*req.Status)
+		filter.Status = &status
+	}
+
+	letters, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return dldto.ListDeadLettersResponse{}, err
+	}
+
+	items := make([]dldto.DeadLetterItem, 0, len(letters))
+	for _, dl := range letters {
+		items = append(items, toDeadLetterItem(dl))
+	}
+
+	return dldto.ListDeadLettersResponse{
+		Items: items,
+		Meta: dldto.PageMeta{
+			Page:     req.Page,
+			PageSize: req.PageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+func (s *DeadLetterService) Get(ctx context.Context, id int64) (dldto.DeadLetterResponse, error) {
+	dl, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return dldto.DeadLetterResponse{}, err
+	}
+
+	resp := dldto.DeadLetterResponse{
+		DeadLetterItem: toDeadLetterItem(dl),
+		Headers:        dl.Headers,
+		ContentType:    dl.ContentType,
+	}
+	if json.Valid(dl.Body) {
+		resp.Body = dl.Body
+	} else {
+		resp.BodyBase64 = base64.StdEncoding.EncodeToString(dl.Body)
+	}
+
+	return resp, nil
+}
+
+// Replay прогоняет pending-сообщения через обработчик ещё раз. Успешные помечаются replayed,
+// упавшие остаются pending с новой причиной — их можно переиграть позже или отбросить.
+func (s *DeadLetterService) Replay(ctx context.Context, req dldto.BulkRequest, by *string) (dldto.ReplayResponse, error) {
+	letters, skipped, err := s.selectPending(ctx, req)
+	if err != nil {
+		return dldto.ReplayResponse{}, err
+	}
+
+	resp := dldto.ReplayResponse{
+		Replayed: make([]int64, 0, len(letters)),
+		Failed:   make([]dldto.ReplayFailure, 0),
+		Skipped:  skipped,
+	}
+
+	type failure struct {
+		dl  domain.DeadLetter
+		err error
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, replayWorkers)
+
+		succeeded = make([]int64, 0, len(letters))
+		failures  = make([]failure, 0)
+	)
+
+	for _, dl := range letters {
+		if dl.LectureID == nil {
+			resp.Failed = append(resp.Failed, dldto.ReplayFailure{ID: dl.ID, Reason: dl.Reason, Error: "message has no lecture_id"})
+			continue
+		}
+
+		handler, ok := s.handler(dl.Kind)
+		if !ok {
+			resp.Failed = append(resp.Failed, dldto.ReplayFailure{ID: dl.ID, Reason: dl.Reason, Error: "no handler for kind " + string(dl.Kind)})
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(dl domain.DeadLetter) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// legacy-снапшот без времени кадра получит тот же ключ, что и при первой попытке
+			replayCtx := ctx
+			if at, ok := rabbit.ReceivedAtHeader(dl.Headers); ok {
+				replayCtx = rabbit.WithReceivedAt(ctx, at)
+			}
+
+			err := handler.Replay(replayCtx, *dl.LectureID, dl.Body)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err == nil || errors.Is(err, rabbit.ErrLectureEnd) {
+				succeeded = append(succeeded, dl.ID)
+				return
+			}
+			failures = append(failures, failure{dl: dl, err: err})
+		}(dl)
+	}
+	wg.Wait()
+
+	// причины отказа записываем после воркеров, чтобы не держать запросы к БД под mu
+	for _, f := range failures {
+		reason := rabbit.Reason(f.err, "replay_failed")
+		item := dldto.ReplayFailure{ID: f.dl.ID, Reason: reason, Error: f.err.Error()}
+		if recErr := s.repo.RecordReplayFailure(ctx, f.dl.ID, reason, f.err.Error()); recErr != nil {
+			item.Error += "; " + recErr.Error()
+		}
+		resp.Failed = append(resp.Failed, item)
+	}
+
+	if len(succeeded) > 0 {
+		replayed, err := s.repo.Resolve(ctx, succeeded, domain.DeadLetterReplayed, by)
+		if err != nil {
+			return dldto.ReplayResponse{}, err
+		}
+		resp.Replayed = replayed
+	}
+
+	return resp, nil
+}
+
+func (s *DeadLetterService) Discard(ctx context.Context, req dldto.BulkRequest, by *string) (dldto.DiscardResponse, error) {
+	letters, skipped, err := s.selectPending(ctx, req)
+	if err != nil {
+		return dldto.DiscardResponse{}, err
+	}
+
+	resp := dldto.DiscardResponse{
+		Discarded: make([]int64, 0, len(letters)),
+		Skipped:   skipped,
+	}
+	if len(letters) == 0 {
+		return resp, nil
+	}
+
+	ids := make([]int64, 0, len(letters))
+	for _, dl := range letters {
+		ids = append(ids, dl.ID)
+	}
+
+	discarded, err := s.repo.Resolve(ctx, ids, domain.DeadLetterDiscarded, by)
+	if err != nil {
+		return dldto.DiscardResponse{}, err
+	}
+	resp.Discarded = discarded
+
+	return resp, nil
+}
+
+// selectPending выбирает pending-сообщения по ids или по фильтру. Второе значение — ids,
+// которые не найдены или уже обработаны.
+func (s *DeadLetterService) selectPending(ctx context.Context, req dldto.BulkRequest) ([]domain.DeadLetter, []int64, error) {
+	skipped := make([]int64, 0)
+
+	if len(req.IDs) > 0 {
+		letters, err := s.repo.ListByIDs(ctx, req.IDs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		byID := make(map[int64]domain.DeadLetter, len(letters))
+		for _, dl := range letters {
+			byID[dl.ID] = dl
+		}
+
+		pending := make([]domain.DeadLetter, 0, len(letters))
+		seen := make(map[int64]struct{}, len(req.IDs))
+		for _, id := range req.IDs {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+
+			dl, ok := byID[id]
+			if !ok || dl.Status != domain.DeadLetterPending {
+				skipped = append(skipped, id)
+				continue
+			}
+			pending = append(pending, dl)
+		}
+
+		return pending, skipped, nil
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultBulkLimit
+	}
+	if limit > maxBulkLimit {
+		limit = maxBulkLimit
+	}
+
+	status := domain.DeadLetterPending
+	letters, _, err := s.repo.List(ctx, domain.DeadLetterFilter{
+		Status:    &status,
+		Reason:    req.Reason,
+		LectureID: req.LectureID,
+		Kind:      classKind(req.Kind),
+		Page:      1,
+		PageSize:  limit,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return letters, skipped, nil
+}
+
+// handler выбирает обработчик по виду занятия; сообщения без вида считаются лекционными.
+func (s *DeadLetterService) handler(kind domain.ClassKind) (Replayer, bool) {
+	if kind == "" {
+		kind = domain.ClassLecture
+	}
+	h, ok := s.handlers[kind]
+	return h, ok && h != nil
+}
+
+func classKind(kind *string) *domain.ClassKind {
+	if kind == nil {
+		return nil
+	}
+	k := domain.ClassKind(*kind)
+	return &k
+}
+
+func toDeadLetterItem(dl domain.DeadLetter) dldto.DeadLetterItem {
+	return dldto.DeadLetterItem{
+		ID:             dl.ID,
+		SourceQueue:    dl.SourceQueue,
+		Kind:           string(dl.Kind),
+		LectureID:      dl.LectureID,
+		Reason:         dl.Reason,
+		Error:          dl.Error,
+		Status:         string(dl.Status),
+		ReplayAttempts: dl.ReplayAttempts,
+		FailedAt:       dl.FailedAt,
+		ArchivedAt:     dl.ArchivedAt,
+		ResolvedAt:     dl.ResolvedAt,
+		ResolvedBy:     dl.ResolvedBy,
+	}
+}
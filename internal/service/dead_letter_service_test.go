@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"monitoring_backend/internal/domain"
+	dldto "monitoring_backend/internal/http/handlers/dead_letter"
+	"monitoring_backend/internal/rabbit"
+)
+
+type fakeDeadLetters struct {
+	letters []domain.DeadLetter
+	// inFlight сколько Replay ещё выполняется; RecordReplayFailure не должен их застать
+	inFlight *atomic.Int64
+
+	mu       sync.Mutex
+	recorded []int64
+	resolved []int64
+	// overlapped RecordReplayFailure вызван, пока воркеры ещё работали
+	overlapped bool
+}
+
+func (f *fakeDeadLetters) Add(context.Context, domain.DeadLetter) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (f *fakeDeadLetters) GetByID(context.Context, int64) (domain.DeadLetter, error) {
+	return domain.DeadLetter{}, errors.New("not implemented")
+}
+
+func (f *fakeDeadLetters) ListByIDs(_ context.Context, ids []int64) ([]domain.DeadLetter, error) {
+	out := make([]domain.DeadLetter, 0, len(ids))
+	for _, dl := range f.letters {
+		if slices.Contains(ids, dl.ID) {
+			out = append(out, dl)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeDeadLetters) List(_ context.Context, filter domain.DeadLetterFilter) ([]domain.DeadLetter, int, error) {
+	return f.letters[:min(filter.PageSize, len(f.letters))], len(f.letters), nil
+}
+
+func (f *fakeDeadLetters) Resolve(_ context.Context, ids []int64, _ domain.DeadLetterStatus, _ *string) ([]int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resolved = append(f.resolved, ids...)
+	return ids, nil
+}
+
+func (f *fakeDeadLetters) RecordReplayFailure(_ context.Context, id int64, _, _ string) error {
+	if f.inFlight.Load() != 0 {
+		f.overlapped = true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recorded = append(f.recorded, id)
+	return nil
+}
+
+// scriptedReplayer отвечает ошибкой по id сообщения (id кладётся в lecture_id).
+type scriptedReplayer struct {
+	inFlight *atomic.Int64
+	calls    atomic.Int64
+	result   func(lectureID int64) error
+}
+
+func (r *scriptedReplayer) Replay(_ context.Context, lectureID int64, _ []byte) error {
+	r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+	r.calls.Add(1)
+
+	// разная длительность, чтобы воркеры завершались вперемешку
+	time.Sleep(time.Duration(lectureID%7) * 100 * time.Microsecond)
+	return r.result(lectureID)
+}
+
+func TestDeadLetterServiceReplay(t *testing.T) {
+	errBroken := errors.New("db is down")
+
+	tests := []struct {
+		name    string
+		letters int
+		kind    func(id int64) domain.ClassKind
+		result  func(id int64) error
+		// noLecture сообщения без lecture_id
+		noLecture    func(id int64) bool
+		wantReplayed int
+		wantFailed   int
+		wantRecorded int
+	}{
+		{
+			name:         "all succeed",
+			letters:      250,
+			result:       func(int64) error { return nil },
+			wantReplayed: 250,
+		},
+		{
+			name:         "lecture end counts as replayed",
+			letters:      10,
+			result:       func(int64) error { return rabbit.ErrLectureEnd },
+			wantReplayed: 10,
+		},
+		{
+			name:    "every third fails",
+			letters: 300,
+			result: func(id int64) error {
+				if id%3 == 0 {
+					return fmt.Errorf("write visit: %w", errBroken)
+				}
+				return nil
+			},
+			wantReplayed: 200,
+			wantFailed:   100,
+			wantRecorded: 100,
+		},
+		{
+			name:    "unreplayable messages are not recorded",
+			letters: 40,
+			kind: func(id int64) domain.ClassKind {
+				if id%4 == 0 {
+					return "seminar"
+				}
+				return domain.ClassLecture
+			},
+			noLecture:    func(id int64) bool { return id%4 == 1 },
+			result:       func(int64) error { return nil },
+			wantReplayed: 20,
+			wantFailed:   20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inFlight atomic.Int64
+			repo := &fakeDeadLetters{inFlight: &inFlight}
+			for i := range tt.letters {
+				id := int64(i + 1)
+				dl := domain.DeadLetter{ID: id, Kind: domain.ClassLecture, Status: domain.DeadLetterPending, Reason: "constraint_violation"}
+				if tt.kind != nil {
+					dl.Kind = tt.kind(id)
+				}
+				if tt.noLecture == nil || !tt.noLecture(id) {
+					dl.LectureID = &id
+				}
+				repo.letters = append(repo.letters, dl)
+			}
+
+			replayer := &scriptedReplayer{inFlight: &inFlight, result: tt.result}
+			svc := NewDeadLetterService(repo, map[domain.ClassKind]Replayer{domain.ClassLecture: replayer})
+
+			resp, err := svc.Replay(context.Background(), dldto.BulkRequest{Limit: maxBulkLimit}, nil)
+			if err != nil {
+				t.Fatalf("Replay: %v", err)
+			}
+
+			if len(resp.Replayed) != tt.wantReplayed {
+				t.Errorf("replayed = %d, want %d", len(resp.Replayed), tt.wantReplayed)
+			}
+			if len(resp.Failed) != tt.wantFailed {
+				t.Errorf("failed = %d, want %d", len(resp.Failed), tt.wantFailed)
+			}
+			if len(repo.recorded) != tt.wantRecorded {
+				t.Errorf("recorded failures = %d, want %d", len(repo.recorded), tt.wantRecorded)
+			}
+			if repo.overlapped {
+				t.Error("RecordReplayFailure was called while replay workers were still running")
+			}
+
+			// каждое сообщение попадает ровно в один список
+			seen := make(map[int64]int, tt.letters)
+			for _, id := range resp.Replayed {
+				seen[id]++
+			}
+			for _, f := range resp.Failed {
+				seen[f.ID]++
+			}
+			for _, dl := range repo.letters {
+				if seen[dl.ID] != 1 {
+					t.Errorf("letter %d reported %d times", dl.ID, seen[dl.ID])
+				}
+			}
+			for _, f := range resp.Failed {
+				if f.Error == "" || f.Reason == "" {
+					t.Errorf("failure %+v has no reason or error", f)
+				}
+			}
+		})
+	}
+}
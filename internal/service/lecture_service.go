@@ -12,11 +12,11 @@ import (
 
 type LectureService struct {
 	db        *pgxpool.Pool
-	lectures  postgres.LectureRepository
-	lecGroups postgres.LectureGroupRepository
+	lectures  postgres.ClassRepository
+	lecGroups postgres.ClassGroupRepository
 }
 
-func NewLectureService(db *pgxpool.Pool, lectures postgres.LectureRepository, lecGroups postgres.LectureGroupRepository) *LectureService {
+func NewLectureService(db *pgxpool.Pool, lectures postgres.ClassRepository, lecGroups postgres.ClassGroupRepository) *LectureService {
 	return &LectureService{
 		db:        db,
 		lectures:  lectures,
@@ -26,9 +26,13 @@ func NewLectureService(db *pgxpool.Pool, lectures postgres.LectureRepository, le
 
 func (s *LectureService) Create(ctx context.Context, req lectdto.CreateLectureRequest) (lectdto.LectureResponse, error) {
 	l := domain.Lecture{
+		Kind:      domain.ClassLecture,
 		Date:      req.Date,
 		SubjectID: req.SubjectID,
 		TeacherID: req.TeacherID,
+		EndsAt:    req.EndsAt,
+		Room:      req.Room,
+		Online:    req.Online,
 	}
 
 	id, err := s.lectures.Create(ctx, l)
@@ -37,82 +41,92 @@ func (s *LectureService) Create(ctx context.Context, req lectdto.CreateLectureRe
 	}
 
 	for _, g := range uniqueStrings(req.GroupIDs) {
-		if err := s.lecGroups.AddGroup(ctx, id, g); err != nil {
+		if err := s.lecGroups.AddGroup(ctx, domain.ClassLecture, id, g); err != nil {
 			return lectdto.LectureResponse{}, err
 		}
 	}
 
-	return lectdto.LectureResponse{
-		ID:        id,
-		Date:      req.Date,
-		SubjectID: req.SubjectID,
-		TeacherID: req.TeacherID,
-		GroupIDs:  uniqueStrings(req.GroupIDs),
-	}, nil
+	l.ID = id
+	return toLectureResponse(l, uniqueStrings(req.GroupIDs)), nil
 }
 
 func (s *LectureService) GetByID(ctx context.Context, req lectdto.GetLectureByIDRequest) (lectdto.LectureResponse, error) {
-	l, err := s.lectures.GetByID(ctx, req.ID)
+	l, err := s.lectures.GetByID(ctx, domain.ClassLecture, req.ID)
 	if err != nil {
 		return lectdto.LectureResponse{}, err
 	}
 
-	groups, err := s.lecGroups.ListGroups(ctx, req.ID)
+	groups, err := s.lecGroups.ListGroups(ctx, domain.ClassLecture, req.ID)
 	if err != nil {
 		return lectdto.LectureResponse{}, err
 	}
 
-	return lectdto.LectureResponse{
-		ID:        l.ID,
-		Date:      l.Date,
-		SubjectID: l.SubjectID,
-		TeacherID: l.TeacherID,
-		GroupIDs:  groups,
-	}, nil
+	return toLectureResponse(l, groups), nil
 }
 
 func (s *LectureService) ListByTeacher(ctx context.Context, req lectdto.ListLecturesByTeacherRequest) ([]lectdto.LectureListItemResponse, error) {
-	ls, err := s.lectures.ListByTeacher(ctx, req.TeacherID, req.From, req.To)
+	ls, err := s.lectures.ListByTeacher(ctx, domain.ClassLecture, req.TeacherID, req.From, req.To)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]lectdto.LectureListItemResponse, 0, len(ls))
 	for _, l := range ls {
-		out = append(out, lectdto.LectureListItemResponse{
-			ID: l.ID, Date: l.Date, SubjectID: l.SubjectID, TeacherID: l.TeacherID,
-		})
+		out = append(out, toLectureListItem(l))
 	}
 	return out, nil
 }
 
 func (s *LectureService) ListBySubject(ctx context.Context, req lectdto.ListLecturesBySubjectRequest) ([]lectdto.LectureListItemResponse, error) {
-	ls, err := s.lectures.ListBySubject(ctx, req.SubjectID, req.From, req.To)
+	ls, err := s.lectures.ListBySubject(ctx, domain.ClassLecture, req.SubjectID, req.From, req.To)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]lectdto.LectureListItemResponse, 0, len(ls))
 	for _, l := range ls {
-		out = append(out, lectdto.LectureListItemResponse{
-			ID: l.ID, Date: l.Date, SubjectID: l.SubjectID, TeacherID: l.TeacherID,
-		})
+		out = append(out, toLectureListItem(l))
 	}
 	return out, nil
 }
 
 func (s *LectureService) ListByGroup(ctx context.Context, req lectdto.ListLecturesByGroupRequest) ([]lectdto.LectureListItemResponse, error) {
-	ls, err := s.lecGroups.ListLecturesByGroup(ctx, req.GroupCode, req.From, req.To)
+	ls, err := s.lecGroups.ListByGroup(ctx, domain.ClassLecture, req.GroupCode, req.From, req.To)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]lectdto.LectureListItemResponse, 0, len(ls))
 	for _, l := range ls {
-		out = append(out, lectdto.LectureListItemResponse{
-			ID: l.ID, Date: l.Date, SubjectID: l.SubjectID, TeacherID: l.TeacherID,
-		})
+		out = append(out, toLectureListItem(l))
 	}
 	return out, nil
 }
 
+func toLectureResponse(l domain.Lecture, groups []string) lectdto.LectureResponse {
+	return lectdto.LectureResponse{
+		ID:              l.ID,
+		Date:            l.Date,
+		SubjectID:       l.SubjectID,
+		TeacherID:       l.TeacherID,
+		GroupIDs:        groups,
+		EndsAt:          l.End(),
+		DurationMinutes: int(l.Duration().Minutes()),
+		Room:            l.Room,
+		Online:          l.Online,
+	}
+}
+
+func toLectureListItem(l domain.Lecture) lectdto.LectureListItemResponse {
+	return lectdto.LectureListItemResponse{
+		ID:              l.ID,
+		Date:            l.Date,
+		SubjectID:       l.SubjectID,
+		TeacherID:       l.TeacherID,
+		EndsAt:          l.End(),
+		DurationMinutes: int(l.Duration().Minutes()),
+		Room:            l.Room,
+		Online:          l.Online,
+	}
+}
+
 func uniqueStrings(in []string) []string {
 	seen := make(map[string]struct{}, len(in))
 	out := make([]string, 0, len(in))
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"monitoring_backend/internal/domain"
+	rtdto "monitoring_backend/internal/http/handlers/recognition_threshold"
+	postgres "monitoring_backend/internal/repository/postgres"
+)
+
+// RecognitionThresholdService пороги similarity: пороги департамента группы студента, иначе defaults из конфига.
+type RecognitionThresholdService struct {
+	repo     postgres.RecognitionThresholdRepository
+	defaults domain.RecognitionThresholds
+}
+
+// NewRecognitionThresholdService нулевые defaults принимают все снапшоты.
+func NewRecognitionThresholdService(repo postgres.RecognitionThresholdRepository, defaults domain.RecognitionThresholds) *RecognitionThresholdService {
+	return &RecognitionThresholdService{
+		repo:     repo,
+		defaults: defaults,
+	}
+}
+
+// ForStudents пороги для каждого из студентов isus (для ingest.BatchWriter).
+func (s *RecognitionThresholdService) ForStudents(ctx context.Context, isus []string) (map[string]domain.RecognitionThresholds, error) {
+	byStudent, err := s.repo.ByStudents(ctx, isus)
+	if err != nil {
+		return nil, err
+	}
+	for _, isu := range isus {
+		t, ok := byStudent[isu]
+		if !ok {
+			byStudent[isu] = s.defaults
+			continue
+		}
+		// снапшоты без similarity обрабатываются одинаково для всех департаментов
+		t.AcceptMissing = s.defaults.AcceptMissing
+		byStudent[isu] = t
+	}
+	return byStudent, nil
+}
+
+func (s *RecognitionThresholdService) List(ctx context.Context) (rtdto.ListThresholdsResponse, error) {
+	rules, err := s.repo.List(ctx)
+	if err != nil {
+		return rtdto.ListThresholdsResponse{}, err
+	}
+
+	items := make([]rtdto.ThresholdResponse, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, toThresholdResponse(rule))
+	}
+
+	return rtdto.ListThresholdsResponse{
+		Default: rtdto.ThresholdRequest{
+			AcceptThreshold: s.defaults.Accept,
+			ReviewThreshold: s.defaults.Review,
+		},
+		Items: items,
+	}, nil
+}
+
+func (s *RecognitionThresholdService) SetForDepartment(ctx context.Context, departmentID int64, req rtdto.ThresholdRequest, by *string) (rtdto.ThresholdResponse, error) {
+	rule, err := s.repo.Upsert(ctx, domain.RecognitionThresholdRule{
+		DepartmentID: departmentID,
+		Thresholds: domain.RecognitionThresholds{
+			Accept: req.AcceptThreshold,
+			Review: req.ReviewThreshold,
+		},
+		UpdatedBy: by,
+	})
+	if err != nil {
+		return rtdto.ThresholdResponse{}, err
+	}
+	return toThresholdResponse(rule), nil
+}
+
+func (s *RecognitionThresholdService) DeleteForDepartment(ctx context.Context, departmentID int64) error {
+	return s.repo.Delete(ctx, departmentID)
+}
+
+func toThresholdResponse(rule domain.RecognitionThresholdRule) rtdto.ThresholdResponse {
+	return rtdto.ThresholdResponse{
+		DepartmentID:    rule.DepartmentID,
+		AcceptThreshold: rule.Thresholds.Accept,
+		ReviewThreshold: rule.Thresholds.Review,
+		UpdatedAt:       rule.UpdatedAt,
+		UpdatedBy:       rule.UpdatedBy,
+	}
+}
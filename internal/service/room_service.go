@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+
+	"monitoring_backend/internal/domain"
+	roomdto "monitoring_backend/internal/http/handlers/room"
+	postgres "monitoring_backend/internal/repository/postgres"
+)
+
+type RoomService struct {
+	repo postgres.RoomRepository
+}
+
+func NewRoomService(repo postgres.RoomRepository) *RoomService {
+	return &RoomService{repo: repo}
+}
+
+func (s *RoomService) Create(ctx context.Context, req roomdto.CreateRoomRequest) (roomdto.RoomResponse, error) {
+	room := domain.Room{
+		Code:     req.Code,
+		Building: req.Building,
+		Capacity: req.Capacity,
+		Queue:    req.Queue,
+	}
+	if err := s.repo.Create(ctx, room); err != nil {
+		return roomdto.RoomResponse{}, err
+	}
+	return toRoomResponse(room), nil
+}
+
+func (s *RoomService) GetByCode(ctx context.Context, code string) (roomdto.RoomResponse, error) {
+	room, err := s.repo.GetByCode(ctx, code)
+	if err != nil {
+		return roomdto.RoomResponse{}, err
+	}
+	return toRoomResponse(room), nil
+}
+
+func (s *RoomService) List(ctx context.Context) ([]roomdto.RoomResponse, error) {
+	rooms, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]roomdto.RoomResponse, 0, len(rooms))
+	for _, room := range rooms {
+		out = append(out, toRoomResponse(room))
+	}
+	return out, nil
+}
+
+func (s *RoomService) Update(ctx context.Context, code string, req roomdto.UpdateRoomRequest) (roomdto.RoomResponse, error) {
+	err := s.repo.Update(ctx, domain.Room{
+		Code:     code,
+		Building: req.Building,
+		Capacity: req.Capacity,
+		Queue:    req.Queue,
+	})
+	if err != nil {
+		return roomdto.RoomResponse{}, err
+	}
+	return s.GetByCode(ctx, code)
+}
+
+func (s *RoomService) Delete(ctx context.Context, code string) error {
+	return s.repo.Delete(ctx, code)
+}
+
+func (s *RoomService) AddCamera(ctx context.Context, roomCode string, req roomdto.AddCameraRequest) (roomdto.CameraResponse, error) {
+	camera, err := s.repo.AddCamera(ctx, domain.Camera{
+		ID:          req.ID,
+		RoomCode:    roomCode,
+		Description: req.Description,
+	})
+	if err != nil {
+		return roomdto.CameraResponse{}, err
+	}
+	return toCameraResponse(camera), nil
+}
+
+func (s *RoomService) DeleteCamera(ctx context.Context, roomCode, cameraID string) error {
+	return s.repo.DeleteCamera(ctx, roomCode, cameraID)
+}
+
+// Route очередь аудитории занятия для lecture.Manager.
+func (s *RoomService) Route(ctx context.Context, kind domain.ClassKind, classID int64) (string, error) {
+	return s.repo.ClassRoute(ctx, kind, classID)
+}
+
+func toRoomResponse(room domain.Room) roomdto.RoomResponse {
+	cameras := make([]roomdto.CameraResponse, 0, len(room.Cameras))
+	for _, c := range room.Cameras {
+		cameras = append(cameras, toCameraResponse(c))
+	}
+	return roomdto.RoomResponse{
+		Code:     room.Code,
+		Building: room.Building,
+		Capacity: room.Capacity,
+		Queue:    room.Queue,
+		Cameras:  cameras,
+	}
+}
+
+func toCameraResponse(c domain.Camera) roomdto.CameraResponse {
+	return roomdto.CameraResponse{
+		ID:          c.ID,
+		RoomCode:    c.RoomCode,
+		Description: c.Description,
+		CreatedAt:   c.CreatedAt,
+	}
+}
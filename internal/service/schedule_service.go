@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"monitoring_backend/internal/domain"
+	scdto "monitoring_backend/internal/http/handlers/schedule"
+	postgres "monitoring_backend/internal/repository/postgres"
+)
+
+// ScheduleService ручное управление планировщиком мониторинга: режим занятия и журнал.
+type ScheduleService struct {
+	classes     postgres.ClassRepository
+	schedule    postgres.ClassScheduleRepository
+	startBefore time.Duration
+	stopAfter   time.Duration
+}
+
+func NewScheduleService(classes postgres.ClassRepository, schedule postgres.ClassScheduleRepository, startBefore, stopAfter time.Duration) *ScheduleService {
+	return &ScheduleService{
+		classes:     classes,
+		schedule:    schedule,
+		startBefore: startBefore,
+		stopAfter:   stopAfter,
+	}
+}
+
+func (s *ScheduleService) Get(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) (scdto.ScheduleResponse, error) {
+	c, err := s.authorize(ctx, userID, role, kind, classID)
+	if err != nil {
+		return scdto.ScheduleResponse{}, err
+	}
+
+	o, err := s.schedule.GetOverride(ctx, kind, classID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.toResponse(c, nil), nil
+	}
+	if err != nil {
+		return scdto.ScheduleResponse{}, err
+	}
+
+	return s.toResponse(c, &o), nil
+}
+
+func (s *ScheduleService) SetMode(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, req scdto.SetModeRequest) (scdto.ScheduleResponse, error) {
+	c, err := s.authorize(ctx, userID, role, kind, classID)
+	if err != nil {
+		return scdto.ScheduleResponse{}, err
+	}
+
+	var reason *string
+	if req.Reason != "" {
+		reason = &req.Reason
+	}
+
+	o, err := s.schedule.SetOverride(ctx, domain.ScheduleOverride{
+		Kind:      kind,
+		ClassID:   classID,
+		Mode:      domain.ScheduleMode(req.Mode),
+		Reason:    reason,
+		UpdatedBy: &userID,
+	})
+	if err != nil {
+		return scdto.ScheduleResponse{}, err
+	}
+
+	return s.toResponse(c, &o), nil
+}
+
+func (s *ScheduleService) ListAudit(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, page, pageSize int) (scdto.ListAuditResponse, error) {
+	if _, err := s.authorize(ctx, userID, role, kind, classID); err != nil {
+		return scdto.ListAuditResponse{}, err
+	}
+
+	entries, total, err := s.schedule.ListAudit(ctx, domain.ScheduleAuditFilter{
+		Kind:     kind,
+		ClassID:  classID,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return scdto.ListAuditResponse{}, err
+	}
+
+	items := make([]scdto.AuditItem, 0, len(entries))
+	for _, e := range entries {
+		item := scdto.AuditItem{
+			ID:        e.ID,
+			Action:    string(e.Action),
+			Reason:    e.Reason,
+			Actor:     e.Actor,
+			CreatedAt: e.CreatedAt,
+		}
+		if e.Mode != nil {
+			mode := string(*e.Mode)
+			item.Mode = &mode
+		}
+		items = append(items, item)
+	}
+
+	return scdto.ListAuditResponse{
+		Items: items,
+		Meta: scdto.PageMeta{
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+func (s *ScheduleService) authorize(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) (domain.Class, error) {
+	return authorizeClass(ctx, s.classes, userID, role, kind, classID)
+}
+
+func (s *ScheduleService) toResponse(c domain.Class, o *domain.ScheduleOverride) scdto.ScheduleResponse {
+	resp := scdto.ScheduleResponse{
+		Kind:    string(c.Kind),
+		ClassID: c.ID,
+		Mode:    string(domain.ScheduleAuto),
+		StartAt: c.Date.Add(-s.startBefore),
+		StopAt:  c.End().Add(s.stopAfter),
+	}
+	if o != nil {
+		resp.Mode = string(o.Mode)
+		resp.Reason = o.Reason
+		resp.UpdatedAt = &o.UpdatedAt
+		resp.UpdatedBy = o.UpdatedBy
+	}
+	return resp
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"monitoring_backend/internal/domain"
+	srdto "monitoring_backend/internal/http/handlers/sighting_review"
+	postgres "monitoring_backend/internal/repository/postgres"
+	"monitoring_backend/internal/ws"
+)
+
+// SightingReviewService проверка преподавателем снапшотов с similarity между порогами.
+type SightingReviewService struct {
+	classes   postgres.ClassRepository
+	reviews   postgres.SightingReviewRepository
+	publisher ws.Broadcaster
+}
+
+// NewSightingReviewService publisher получает события visit по подтверждённым снапшотам лекций, как
+// и снапшоты из очереди распознавания; nil — события не публикуются.
+func NewSightingReviewService(classes postgres.ClassRepository, reviews postgres.SightingReviewRepository, publisher ws.Broadcaster) *SightingReviewService {
+	return &SightingReviewService{
+		classes:   classes,
+		reviews:   reviews,
+		publisher: publisher,
+	}
+}
+
+func (s *SightingReviewService) List(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, status *domain.SightingStatus, page, pageSize int) (srdto.ListSightingsResponse, error) {
+	if _, err := authorizeClass(ctx, s.classes, userID, role, kind, classID); err != nil {
+		return srdto.ListSightingsResponse{}, err
+	}
+
+	sightings, total, err := s.reviews.List(ctx, domain.PendingSightingFilter{
+		Kind:     kind,
+		ClassID:  classID,
+		Status:   status,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return srdto.ListSightingsResponse{}, err
+	}
+
+	return srdto.ListSightingsResponse{
+		Kind:    string(kind),
+		ClassID: classID,
+		Items:   toSightingItems(sightings),
+		Meta: srdto.PageMeta{
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+func (s *SightingReviewService) Review(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64, ids []int64, status domain.SightingStatus) (srdto.ReviewResponse, error) {
+	if _, err := authorizeClass(ctx, s.classes, userID, role, kind, classID); err != nil {
+		return srdto.ReviewResponse{}, err
+	}
+
+	sightings, confirmed, err := s.reviews.Review(ctx, kind, classID, ids, status, &userID)
+	if err != nil {
+		return srdto.ReviewResponse{}, err
+	}
+
+	s.publishVisits(kind, sightings, confirmed)
+
+	return srdto.ReviewResponse{Items: toSightingItems(sightings)}, nil
+}
+
+// publishVisits рассылает события visit по снапшотам, записанным при подтверждении. У дашборда
+// присутствие считается по событиям, поэтому без них подтверждённый студент появится только после переподключения.
+func (s *SightingReviewService) publishVisits(kind domain.ClassKind, sightings []domain.PendingSighting, confirmed []domain.ClassVisit) {
+	// WebSocket-дашборд есть только у лекций
+	if s.publisher == nil || kind != domain.ClassLecture || len(confirmed) == 0 {
+		return
+	}
+
+	users := make(map[string]domain.User, len(sightings))
+	for _, sighting := range sightings {
+		users[sighting.Visit.UserID] = sighting.User
+	}
+
+	for _, visit := range confirmed {
+		// id события = id снапшота, как у событий из очереди распознавания
+		data, err := ws.Encode(ws.TypeVisit, strconv.FormatInt(visit.ID, 10), ws.NewVisitResponse(visit, users[visit.UserID]))
+		if err != nil {
+			log.Printf("ERROR: marshal visit event for %s: %v", visit.UserID, err)
+			continue
+		}
+		s.publisher.Broadcast(visit.LectureID, data)
+	}
+}
+
+func toSightingItems(sightings []domain.PendingSighting) []srdto.SightingItem {
+	items := make([]srdto.SightingItem, 0, len(sightings))
+	for _, s := range sightings {
+		items = append(items, srdto.SightingItem{
+			ID:         s.Visit.ID,
+			ISU:        s.User.ISU,
+			FirstName:  s.User.FirstName,
+			LastName:   s.User.LastName,
+			Patronymic: s.User.Patronymic,
+			Group:      s.User.GroupCode,
+			CapturedAt: s.Visit.CapturedAt,
+			CameraID:   s.Visit.CameraID,
+			Confidence: s.Visit.Confidence,
+			Status:     string(s.Status),
+			ReviewedBy: s.ReviewedBy,
+			ReviewedAt: s.ReviewedAt,
+		})
+	}
+	return items
+}
@@ -6,37 +6,39 @@ import (
 	"monitoring_backend/internal/domain"
 	"monitoring_backend/internal/http/handlers/visits"
 	"monitoring_backend/internal/repository/postgres"
-	"monitoring_backend/internal/ws"
 	"strings"
 	"time"
 )
 
-type visitService struct {
-	repo postgres.LectureVisitRepository
+// attendancePolicyResolver пороги статуса посещаемости (см. AttendancePolicyService).
+type attendancePolicyResolver interface {
+	Resolve(ctx context.Context, subjectID int64, groupCode *string) (domain.AttendancePolicy, error)
 }
 
-func NewVisitService(repo postgres.LectureVisitRepository) *visitService {
-	return &visitService{repo: repo}
+// visitService посещаемость занятий вида kind; repo должен быть создан для того же вида.
+type visitService struct {
+	kind     domain.ClassKind
+	repo     postgres.ClassVisitRepository
+	classes  postgres.ClassRepository
+	policies attendancePolicyResolver
+	// overrides ручные отметки преподавателя, важнее статуса по снапшотам
+	overrides postgres.AttendanceOverrideRepository
 }
 
-func (v *visitService) AddUserVisitsLecture(ctx context.Context, userID string, lectureID int64) (*ws.UserVisitsLectureResponse, error) {
-	user, err := v.repo.Add(ctx, domain.LectureVisit{UserID: userID, LectureID: lectureID, Date: time.Now()})
-	if err != nil {
-		return nil, err
-	}
-
-	response := ws.UserVisitsLectureResponse{
-		LectureID: lectureID,
-		User: ws.UserResponse{
-			ISU:        user.ISU,
-			Name:       user.FirstName,
-			LastName:   user.LastName,
-			Patronymic: user.Patronymic,
-		},
-		Group: user.GroupCode,
+func NewVisitService(
+	kind domain.ClassKind,
+	repo postgres.ClassVisitRepository,
+	classes postgres.ClassRepository,
+	policies attendancePolicyResolver,
+	overrides postgres.AttendanceOverrideRepository,
+) *visitService {
+	return &visitService{
+		kind:      kind,
+		repo:      repo,
+		classes:   classes,
+		policies:  policies,
+		overrides: overrides,
 	}
-
-	return &response, nil
 }
 
 func (s *visitService) GetVisitedSubjectsByISU(ctx context.Context, isu string) ([]domain.Subject, error) {
@@ -48,7 +50,7 @@ func (s *visitService) GetVisitedSubjectsByISU(ctx context.Context, isu string)
 	return s.repo.ListVisitedSubjectsByISU(ctx, isu)
 }
 
-func (s *visitService) GetStudentLecturesBySubject(ctx context.Context, isu string, subjectID int64, filter visits.GetLecturesFilter) ([]visits.LectureAttendance, int, error) {
+func (s *visitService) GetStudentClassesBySubject(ctx context.Context, isu string, subjectID int64, filter visits.GetLecturesFilter) ([]visits.ClassAttendance, int, error) {
 	isu = strings.TrimSpace(isu)
 	if isu == "" {
 		return nil, 0, fmt.Errorf("isu is empty")
@@ -56,10 +58,46 @@ func (s *visitService) GetStudentLecturesBySubject(ctx context.Context, isu stri
 	if subjectID <= 0 {
 		return nil, 0, fmt.Errorf("invalid subject_id")
 	}
-	return s.repo.ListStudentLecturesBySubject(ctx, isu, subjectID, filter)
+
+	items, total, err := s.repo.ListStudentClassesBySubject(ctx, isu, subjectID, filter)
+	if err != nil || len(items) == 0 {
+		return items, total, err
+	}
+
+	// политика департамента определяется по группе студента
+	students, err := s.repo.ListStudentsByISU(ctx, []string{isu})
+	if err != nil {
+		return nil, 0, err
+	}
+	var groupCode *string
+	if student, ok := students[isu]; ok {
+		groupCode = student.GroupCode
+	}
+
+	policy, err := s.policies.Resolve(ctx, subjectID, groupCode)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	classIDs := make([]int64, 0, len(items))
+	for _, it := range items {
+		classIDs = append(classIDs, it.ClassID)
+	}
+	overrides, err := s.listOverrides(ctx, classIDs, []string{isu})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range items {
+		it := &items[i]
+		it.Attendance = policy.Classify(it.Date, it.EndsAt, it.FirstSeen, it.LastSeen, it.PresentSeconds).
+			WithOverride(overrides[overrideKey{classID: it.ClassID, userID: isu}])
+	}
+
+	return items, total, nil
 }
 
-func (s *visitService) GetTeacherLecturesBySubject(ctx context.Context, teacherISU string, subjectID int64, filter visits.TeacherLecturesFilter) ([]visits.TeacherLecture, int, error) {
+func (s *visitService) GetTeacherClassesBySubject(ctx context.Context, teacherISU string, subjectID int64, filter visits.TeacherLecturesFilter) ([]visits.TeacherClass, int, error) {
 	teacherISU = strings.TrimSpace(teacherISU)
 	if teacherISU == "" {
 		return nil, 0, fmt.Errorf("teacher isu is empty")
@@ -67,28 +105,28 @@ func (s *visitService) GetTeacherLecturesBySubject(ctx context.Context, teacherI
 	if subjectID <= 0 {
 		return nil, 0, fmt.Errorf("invalid subject_id")
 	}
-	return s.repo.ListTeacherLecturesBySubject(ctx, teacherISU, subjectID, filter)
+	return s.repo.ListTeacherClassesBySubject(ctx, teacherISU, subjectID, filter)
 }
 
-func (s *visitService) GetLectureGroups(ctx context.Context, teacherISU string, lectureID int64) ([]string, error) {
+func (s *visitService) GetClassGroups(ctx context.Context, teacherISU string, classID int64) ([]string, error) {
 	teacherISU = strings.TrimSpace(teacherISU)
 	if teacherISU == "" {
 		return nil, fmt.Errorf("teacher isu is empty")
 	}
-	if lectureID <= 0 {
-		return nil, fmt.Errorf("invalid lecture_id")
+	if classID <= 0 {
+		return nil, fmt.Errorf("invalid class id")
 	}
-	return s.repo.ListLectureGroups(ctx, teacherISU, lectureID)
+	return s.repo.ListClassGroups(ctx, teacherISU, classID)
 }
 
-func (s *visitService) GetLectureGroupStudents(ctx context.Context, teacherISU string, lectureID int64, groupCode string, page int, pageSize int, gapSeconds int) ([]visits.StudentOnLecture, int, error) {
+func (s *visitService) GetClassGroupStudents(ctx context.Context, teacherISU string, classID int64, groupCode string, page int, pageSize int, gapSeconds int) ([]visits.StudentOnLecture, int, error) {
 	teacherISU = strings.TrimSpace(teacherISU)
 	groupCode = strings.TrimSpace(groupCode)
 	if teacherISU == "" {
 		return nil, 0, fmt.Errorf("teacher isu is empty")
 	}
-	if lectureID <= 0 {
-		return nil, 0, fmt.Errorf("invalid lecture_id")
+	if classID <= 0 {
+		return nil, 0, fmt.Errorf("invalid class id")
 	}
 	if groupCode == "" {
 		return nil, 0, fmt.Errorf("invalid group_code")
@@ -102,7 +140,60 @@ func (s *visitService) GetLectureGroupStudents(ctx context.Context, teacherISU s
 	if gapSeconds < 1 {
 		gapSeconds = 120
 	}
-	return s.repo.ListLectureGroupStudents(ctx, teacherISU, lectureID, groupCode, page, pageSize, gapSeconds)
+
+	items, total, err := s.repo.ListClassGroupStudents(ctx, teacherISU, classID, groupCode, page, pageSize, gapSeconds)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	class, err := s.classes.GetByID(ctx, s.kind, classID)
+	if err != nil {
+		return nil, 0, err
+	}
+	policy, err := s.policies.Resolve(ctx, class.SubjectID, &groupCode)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]string, 0, len(items))
+	for _, it := range items {
+		userIDs = append(userIDs, it.ISU)
+	}
+	overrides, err := s.listOverrides(ctx, []int64{classID}, userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range items {
+		it := &items[i]
+		it.Attendance = policy.Classify(class.Date, class.End(), it.FirstSeen, it.LastSeen, it.PresentSeconds).
+			WithOverride(overrides[overrideKey{classID: classID, userID: it.ISU}])
+	}
+
+	return items, total, nil
+}
+
+type overrideKey struct {
+	classID int64
+	userID  string
+}
+
+// listOverrides ручные отметки студентов userIDs на занятиях classIDs.
+func (s *visitService) listOverrides(ctx context.Context, classIDs []int64, userIDs []string) (map[overrideKey]*domain.AttendanceOverride, error) {
+	out := make(map[overrideKey]*domain.AttendanceOverride)
+	if len(classIDs) == 0 || len(userIDs) == 0 {
+		return out, nil
+	}
+
+	overrides, err := s.overrides.ListByStudents(ctx, s.kind, classIDs, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range overrides {
+		o := &overrides[i]
+		out[overrideKey{classID: o.ClassID, userID: o.UserID}] = o
+	}
+	return out, nil
 }
 
 func (s *visitService) GetTeacherSubjects(ctx context.Context, teacherISU string) ([]visits.SubjectDTO, error) {
@@ -112,3 +203,24 @@ func (s *visitService) GetTeacherSubjects(ctx context.Context, teacherISU string
 	}
 	return s.repo.ListTeacherSubjects(ctx, teacherISU)
 }
+
+func (s *visitService) GetClassVisits(ctx context.Context, classID int64, filter domain.ClassVisitFilter) ([]domain.ClassVisit, int, error) {
+	if classID <= 0 {
+		return nil, 0, fmt.Errorf("invalid class id")
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = 100
+	}
+	return s.repo.ListByClass(ctx, classID, filter)
+}
+
+func (s *visitService) GetUserVisits(ctx context.Context, isu string, from, to time.Time) ([]domain.ClassVisit, error) {
+	isu = strings.TrimSpace(isu)
+	if isu == "" {
+		return nil, fmt.Errorf("isu is empty")
+	}
+	return s.repo.ListByUser(ctx, isu, from, to)
+}
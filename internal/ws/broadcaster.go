@@ -0,0 +1,9 @@
+package ws
+
+// Broadcaster доставляет событие лекции всем подписчикам.
+// Продюсеры (ingest pipeline, менеджер лекций) публикуют через него, а не напрямую в Hub:
+//   - Hub — в памяти процесса, подходит для одной реплики;
+//   - PGBroadcaster — через Postgres LISTEN/NOTIFY, событие получают клиенты на всех репликах.
+type Broadcaster interface {
+	Broadcast(lectureID int64, data []byte)
+}
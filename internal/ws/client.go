@@ -1,75 +1,246 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"net"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/websocket"
+
+	"monitoring_backend/internal/config"
+	"monitoring_backend/internal/domain"
 )
 
+// authorizeTimeout ограничивает проверку прав на лекцию при subscribe.
+const authorizeTimeout = 5 * time.Second
+
 type Client struct {
+	// после Close писатель отправляет close frame с кодом и причиной и выходит,
+	// соединение закроется, когда клиент ответит (или через write_timeout)
+	*outbox
+
 	conn *websocket.Conn
-	send chan []byte
 	hub  *Hub
+
+	authorizer Authorizer
+	presence   PresenceProvider
+	role       string
+
+	writerDone chan struct{}
 }
 
-func NewClient(conn *websocket.Conn, hub *Hub) *Client {
+func NewClient(conn *websocket.Conn, hub *Hub, authorizer Authorizer, presence PresenceProvider, cfg config.WSConfig, userID, role string) *Client {
 	return &Client{
-		conn: conn,
-		send: make(chan []byte, 256),
-		hub:  hub,
+		outbox:     newOutbox(newOptions(cfg), conn.RemoteAddr().String(), userID),
+		conn:       conn,
+		hub:        hub,
+		authorizer: authorizer,
+		presence:   presence,
+		role:       role,
+		writerDone: make(chan struct{}),
 	}
 }
 
 func (c *Client) Read() {
 	defer func() {
-		c.hub.RemoveClient(c)
+		// даём писателю отправить close frame, потом закрываем соединение
+		select {
+		case <-c.writerDone:
+		case <-time.After(c.opts.writeTimeout):
+		}
 		_ = c.conn.Close()
+		c.hub.RemoveClient(c)
 	}()
 
+	c.conn.SetReadLimit(c.opts.maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.opts.pongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.opts.pongTimeout))
+	})
+
 	for {
 		_, msg, err := c.conn.ReadMessage()
 		if err != nil {
+			var netErr net.Error
+			switch {
+			case errors.As(err, &netErr) && netErr.Timeout():
+				c.Close(ClosePingTimeout, "ping timeout")
+			case errors.Is(err, websocket.ErrReadLimit):
+				c.Close(websocket.CloseMessageTooBig, "message too big")
+			default:
+				c.Close(websocket.CloseNormalClosure, "")
+			}
 			return
 		}
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.opts.pongTimeout))
 		log.Printf("INFO: recv from conn: %s", msg)
 
-		var cmd struct {
-			Action    string `json:"action"`
-			LectureID string `json:"lecture_id"`
-		}
-
-		if json.Unmarshal(msg, &cmd) != nil {
+		cmd, err := decodeCommand(msg)
+		if err != nil {
+			c.sendError("", 0, ErrCodeInvalidMessage, "message is not valid JSON")
 			continue
 		}
 
-		lectureID, err := strconv.ParseInt(cmd.LectureID, 10, 64)
-		if err != nil {
-			log.Printf("WARN: failed to parse lecture id from conn: %s", cmd.LectureID)
-			continue
+		switch cmd.Type {
+		case TypeSubscribe:
+			c.subscribe(cmd)
+		case TypeUnsubscribe:
+			c.unsubscribe(cmd)
+		default:
+			c.sendError(cmd.ID, 0, ErrCodeUnknownType, "unknown message type: "+cmd.Type)
 		}
+	}
+}
+
+func (c *Client) subscribe(cmd Envelope) {
+	var p SubscribePayload
+	if err := json.Unmarshal(cmd.Payload, &p); err != nil || p.LectureID <= 0 {
+		c.sendError(cmd.ID, 0, ErrCodeInvalidPayload, "payload.lecture_id is required")
+		return
+	}
 
-		if cmd.Action == "subscribe" {
-			c.hub.Subscribe(c, lectureID)
-			log.Println("INFO: subscribed", cmd.LectureID)
+	var since int64
+	if p.Since != "" {
+		var err error
+		if since, err = strconv.ParseInt(p.Since, 10, 64); err != nil || since < 0 {
+			c.sendError(cmd.ID, p.LectureID, ErrCodeInvalidPayload, "payload.since must be a visit event id")
+			return
 		}
+	}
+	if p.GapSeconds <= 0 {
+		p.GapSeconds = defaultGapSeconds
+	}
 
-		if cmd.Action == "unsubscribe" {
-			c.hub.Unsubscribe(c, lectureID)
-			log.Println("INFO: unsubscribed", cmd.LectureID)
+	if err := c.authorize(p.LectureID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrClassAccessDenied):
+			c.sendError(cmd.ID, p.LectureID, ErrCodeForbidden, "no access to lecture")
+		case errors.Is(err, domain.ErrClassNotFound):
+			c.sendError(cmd.ID, p.LectureID, ErrCodeLectureNotFound, "lecture not found")
+		default:
+			log.Printf("ERROR: authorize %s for lecture %d: %v", c.userID, p.LectureID, err)
+			c.sendError(cmd.ID, p.LectureID, ErrCodeInternal, "failed to check access")
 		}
+		return
+	}
+
+	c.hub.Subscribe(c, p.LectureID)
+	log.Printf("INFO: %s subscribed to %d", c.userID, p.LectureID)
+
+	c.sendMessage(TypeSubscribeAck, cmd.ID, SubscribeAckPayload{LectureID: p.LectureID})
+
+	// подписка оформлена до выборки: событие может прийти дважды (клиент сверяет по id),
+	// но не потеряется между снапшотом и подпиской
+	ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+	defer cancel()
+
+	if p.Since != "" && c.replay(ctx, p.LectureID, since) {
+		return
+	}
+	c.sendSnapshot(ctx, cmd.ID, p.LectureID, p.GapSeconds)
+}
+
+// replay досылает события visit после since. false — пропущено слишком много
+// или выборка не удалась, тогда клиенту нужен полный снапшот.
+func (c *Client) replay(ctx context.Context, lectureID, since int64) bool {
+	visits, ok := missedVisits(ctx, c.presence, lectureID, since)
+	if !ok {
+		return false
 	}
+
+	for _, v := range visits {
+		c.sendMessage(TypeVisit, strconv.FormatInt(v.Visit.ID, 10), NewVisitResponse(v.Visit, v.User))
+	}
+	return true
+}
+
+func (c *Client) sendSnapshot(ctx context.Context, id string, lectureID int64, gapSeconds int) {
+	payload, err := presenceSnapshot(ctx, c.presence, lectureID, gapSeconds)
+	if err != nil {
+		log.Printf("ERROR: build presence snapshot (lecture_id=%d): %v", lectureID, err)
+		c.sendError(id, lectureID, ErrCodeInternal, "failed to load presence")
+		return
+	}
+
+	c.sendMessage(TypePresenceSnapshot, payload.LastEventID, payload)
 }
 
+func (c *Client) unsubscribe(cmd Envelope) {
+	var p SubscribePayload
+	if err := json.Unmarshal(cmd.Payload, &p); err != nil || p.LectureID <= 0 {
+		c.sendError(cmd.ID, 0, ErrCodeInvalidPayload, "payload.lecture_id is required")
+		return
+	}
+
+	c.hub.Unsubscribe(c, p.LectureID)
+	log.Printf("INFO: %s unsubscribed from %d", c.userID, p.LectureID)
+
+	c.sendMessage(TypeUnsubscribeAck, cmd.ID, SubscribeAckPayload{LectureID: p.LectureID})
+}
+
+func (c *Client) authorize(lectureID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), authorizeTimeout)
+	defer cancel()
+
+	return c.authorizer.AuthorizeClass(ctx, c.userID, c.role, domain.ClassLecture, lectureID)
+}
+
+func (c *Client) sendError(id string, lectureID int64, code, message string) {
+	c.sendMessage(TypeError, id, ErrorPayload{Code: code, Message: message, LectureID: lectureID})
+}
+
+// sendMessage отправляет ответ на команду только этому клиенту.
+func (c *Client) sendMessage(msgType, id string, payload any) {
+	data, err := Encode(msgType, id, payload)
+	if err != nil {
+		log.Printf("ERROR: encode %s message: %v", msgType, err)
+		return
+	}
+
+	c.push(data)
+}
+
+// Write отправляет сообщения из очереди и пингует клиента раз в ping_interval.
+// После Close отправляет close frame и выходит; соединение закрывает Read.
 func (c *Client) Write() {
-	defer func() {
-		_ = c.conn.Close()
-	}()
+	defer close(c.writerDone)
+
+	ticker := time.NewTicker(c.opts.pingInterval)
+	defer ticker.Stop()
 
-	for msg := range c.send {
-		if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+	for {
+		select {
+		case <-c.done:
+			msg := websocket.FormatCloseMessage(c.closeCode, c.closeReason)
+			if err := c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(c.opts.writeTimeout)); err != nil &&
+				!errors.Is(err, websocket.ErrCloseSent) {
+				log.Printf("WARN: send close frame to %s: %v", c.remote, err)
+			}
+			// ждём ответный close frame не дольше write_timeout
+			_ = c.conn.SetReadDeadline(time.Now().Add(c.opts.writeTimeout))
 			return
+
+		case msg := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(c.opts.writeTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				_ = c.conn.Close()
+				return
+			}
+
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.opts.writeTimeout)); err != nil {
+				_ = c.conn.Close()
+				return
+			}
 		}
 	}
 }
+
+// abort закрывает соединение, не дожидаясь ответа на close frame.
+func (c *Client) abort() {
+	_ = c.conn.Close()
+}
@@ -1,5 +1,11 @@
 package ws
 
+import (
+	"time"
+
+	"monitoring_backend/internal/domain"
+)
+
 type UserResponse struct {
 	ISU        string  `json:"isu"`
 	Name       string  `json:"name"`
@@ -7,8 +13,30 @@ type UserResponse struct {
 	Patronymic *string `json:"patronymic"`
 }
 
+// UserVisitsLectureResponse payload события visit.
 type UserVisitsLectureResponse struct {
-	User      UserResponse `json:"user"`
-	LectureID int64        `json:"lecture_id"`
-	Group     *string      `json:"group"`
+	User       UserResponse `json:"user"`
+	LectureID  int64        `json:"lecture_id"`
+	Group      *string      `json:"group"`
+	CapturedAt time.Time    `json:"captured_at"`
+	CameraID   *string      `json:"camera_id,omitempty"`
+}
+
+func NewUserResponse(u domain.User) UserResponse {
+	return UserResponse{
+		ISU:        u.ISU,
+		Name:       u.FirstName,
+		LastName:   u.LastName,
+		Patronymic: u.Patronymic,
+	}
+}
+
+func NewVisitResponse(v domain.ClassVisit, u domain.User) UserVisitsLectureResponse {
+	return UserVisitsLectureResponse{
+		User:       NewUserResponse(u),
+		LectureID:  v.LectureID,
+		Group:      u.GroupCode,
+		CapturedAt: v.CapturedAt,
+		CameraID:   v.CameraID,
+	}
 }
@@ -1,55 +1,157 @@
 package ws
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/gorilla/websocket"
+
+	"monitoring_backend/internal/auth"
+	"monitoring_backend/internal/config"
+	"monitoring_backend/internal/domain"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+// bearerSubprotocol браузерный WebSocket не умеет слать заголовок Authorization,
+// поэтому токен можно передать подпротоколами: new WebSocket(url, ["bearer", token]).
+const bearerSubprotocol = "bearer"
+
+// Authorizer проверяет, может ли пользователь подписаться на занятие.
+// Возвращает domain.ErrClassAccessDenied или domain.ErrClassNotFound при отказе.
+type Authorizer interface {
+	AuthorizeClass(ctx context.Context, userID, role string, kind domain.ClassKind, classID int64) error
 }
 
 // WebSocketHandler godoc
 // @Summary WebSocket connection for lecture streaming
 // @Description Establishes a WebSocket connection for real-time lecture data streaming.
 // @Description
-// @Description Connection flow:
-// @Description 1. Client opens WebSocket connection to this endpoint.
-// @Description 2. After connection client sends control messages to manage subscriptions.
-// @Description 3. Server sends data only for lectures the client is subscribed to.
+// @Description Authentication (one of):
+// @Description - Authorization: Bearer <JWT> header;
+// @Description - subprotocols ["bearer", "<JWT>"] (Sec-WebSocket-Protocol), server selects "bearer";
+// @Description - query parameter token=<JWT>.
+// @Description Without a valid token the handshake is rejected with 401.
 // @Description
-// @Description Client control messages:
-// @Description - Subscribe to lecture: action=subscribe, lecture_id=<lecture identifier>
-// @Description - Unsubscribe from lecture: action=unsubscribe, lecture_id=<lecture identifier>
+// @Description Protocol: every message in both directions is a JSON envelope
+// @Description {"type": "<message type>", "id": "<optional id>", "payload": {...}}.
+// @Description The id of a client command is echoed in the reply to it (subscribe_ack or error).
 // @Description
-// @Description Subscription rules:
-// @Description - One client may subscribe to multiple lectures.
-// @Description - Unsubscribed lectures will no longer send data.
-// @Description - If the client disconnects, all subscriptions are removed automatically.
+// @Description Client messages:
+// @Description - subscribe {"lecture_id": 42, "since"?: "<last visit id>", "gap_seconds"?: 120} — start receiving events of the lecture;
+// @Description - unsubscribe {"lecture_id": 42} — stop receiving them.
+// @Description Legacy {"action": "subscribe", "lecture_id": "42"} is still accepted.
 // @Description
 // @Description Server messages:
-// @Description - Server sends lecture data as JSON messages.
-// @Description - Message payload corresponds to data received from RabbitMQ.
-// @Description - Exact message schema depends on the physical model and may be extended in the future.
+// @Description - subscribe_ack / unsubscribe_ack {"lecture_id"} — command accepted;
+// @Description - error {"code", "message", "lecture_id"?} — codes: invalid_message, unknown_type,
+// @Description   invalid_payload, forbidden, lecture_not_found, internal_error;
+// @Description - visit (id = visit id) {"user": {...}, "lecture_id", "group", "captured_at", "camera_id"?} — student recognized;
+// @Description - lecture_started / lecture_ended {"lecture_id", "session_id", "state", "at"} —
+// @Description   state is running, stopped (manually), finished (lecture end message) or failed (RabbitMQ unavailable);
+// @Description - consumer_status {"lecture_id", "state", "attempt"?, "last_error"?, "since"} —
+// @Description   state is connecting, connected, reconnecting or failed;
+// @Description - presence_snapshot (id = last_event_id) {"lecture_id", "gap_seconds", "last_event_id", "generated_at",
+// @Description   "students": [{"user", "group", "present_seconds", "snapshots", "first_seen"?, "last_seen"?}]} —
+// @Description   presence of every student of the lecture, sent after subscribe_ack. Visits with id <= last_event_id are included.
+// @Description   If subscribe has "since", missed visit events with id > since are replayed instead
+// @Description   (a snapshot is sent when more than 500 events were missed). Events may be delivered twice, deduplicate by id.
 // @Description
-// @Description Future extensions:
-// @Description - Additional message types (errors, control events).
-// @Description - Extended payload schemas.
+// @Description Keep-alive: the server pings every ping_interval; a client that sends no pong or message
+// @Description within pong_timeout is disconnected.
+// @Description
+// @Description Close codes:
+// @Description - 1009 — incoming message exceeds max_message_size;
+// @Description - 1012 — server restarting, reconnect and resubscribe with "since";
+// @Description - 4008 — slow consumer: client did not read fast enough and its send queue overflowed;
+// @Description - 4009 — ping timeout.
+// @Description
+// @Description Subscription rules:
+// @Description - Only the lecture's teacher or an admin may subscribe, otherwise error forbidden.
+// @Description - One client may subscribe to multiple lectures.
+// @Description - If the client disconnects, all subscriptions are removed automatically.
 // @Tags websocket
 // @Produce application/json
+// @Param token query string false "JWT, если его нельзя передать заголовком или подпротоколом"
 // @Success 101 {string} string "Switching Protocols"
+// @Failure 401 {string} string "authorization required"
+// @Failure 403 {string} string "origin not allowed"
 // @Router /api/ws [get]
-func Handler(hub *Hub) http.HandlerFunc {
+func Handler(hub *Hub, jwtManager *auth.JWTManager, authorizer Authorizer, presence PresenceProvider, cfg config.WSConfig) http.HandlerFunc {
+	upgrader := websocket.Upgrader{
+		CheckOrigin:  checkOrigin(cfg.AllowedOrigins),
+		Subprotocols: []string{bearerSubprotocol},
+	}
+
 	return func(w http.ResponseWriter, r *http.Request) {
+		token := tokenFromRequest(r)
+		if token == "" {
+			http.Error(w, "authorization required", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := jwtManager.Parse(token)
+		if err != nil {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		// Upgrade сам отвечает 403 при недопустимом Origin
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			return
 		}
 
-		client := NewClient(conn, hub)
+		client := NewClient(conn, hub, authorizer, presence, cfg, claims.UserID, claims.Role)
+		hub.AddClient(client)
 
 		go client.Read()
 		go client.Write()
 	}
 }
+
+func tokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	if protocols := websocket.Subprotocols(r); len(protocols) >= 2 && protocols[0] == bearerSubprotocol {
+		return protocols[1]
+	}
+
+	return r.URL.Query().Get("token")
+}
+
+// checkOrigin пустой список — только тот же host (как проверка gorilla по умолчанию), "*" — любой Origin.
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	if slices.Contains(allowed, "*") {
+		return func(*http.Request) bool { return true }
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			// не браузер — Origin не присылают
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		if strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+
+		origin = strings.TrimSuffix(origin, "/")
+		for _, a := range allowed {
+			if strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -2,50 +2,121 @@ package ws
 
 import (
 	"context"
-	"encoding/json"
-	"log"
+	"fmt"
 	"sync"
-	"time"
+
+	"github.com/gorilla/websocket"
 )
 
-type VisitsService interface {
-	AddUserVisitsLecture(ctx context.Context, userID string, lectureID int64) (*UserVisitsLectureResponse, error)
+// subscriber получатель событий hub: WebSocket-клиент или SSE-поток.
+type subscriber interface {
+	enqueue(data []byte) bool
+	Close(code int, reason string)
+	// abort обрывает соединение, если клиент не завершился сам после Close
+	abort()
 }
 
 type Hub struct {
-	mu   sync.Mutex
-	serv VisitsService
+	mu sync.Mutex
+	wg sync.WaitGroup
+	// все подключённые клиенты (в том числе без подписок)
+	clients map[subscriber]bool
 	// lecture_id → clients
-	lectures map[int64]map[*Client]bool
+	lectures map[int64]map[subscriber]bool
 }
 
-func NewHub(serv VisitsService) *Hub {
+func NewHub() *Hub {
 	return &Hub{
-		lectures: make(map[int64]map[*Client]bool),
-		serv:     serv,
+		clients:  make(map[subscriber]bool),
+		lectures: make(map[int64]map[subscriber]bool),
 	}
 }
 
-func (h *Hub) Subscribe(c *Client, lectureID int64) {
+func (h *Hub) AddClient(c subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.clients[c] = true
+	h.wg.Add(1)
+}
+
+func (h *Hub) Subscribe(c subscriber, lectureID int64) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	if h.lectures[lectureID] == nil {
-		h.lectures[lectureID] = make(map[*Client]bool)
+		h.lectures[lectureID] = make(map[subscriber]bool)
 	}
 	h.lectures[lectureID][c] = true
 }
 
-func (h *Hub) RemoveClient(c *Client) {
+func (h *Hub) RemoveClient(c subscriber) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	for _, clients := range h.lectures {
+	if !h.clients[c] {
+		return
+	}
+	delete(h.clients, c)
+	h.wg.Done()
+
+	for lectureID, clients := range h.lectures {
 		delete(clients, c)
+		if len(clients) == 0 {
+			delete(h.lectures, lectureID)
+		}
 	}
 }
 
-func (h *Hub) Unsubscribe(c *Client, lectureID int64) {
+// CloseStreams закрывает SSE-потоки: http.Server.Shutdown ждёт завершения запросов,
+// а SSE-запрос сам не завершится. WebSocket-соединения закрывает Shutdown.
+func (h *Hub) CloseStreams() {
+	h.mu.Lock()
+	streams := make([]*stream, 0)
+	for c := range h.clients {
+		if s, ok := c.(*stream); ok {
+			streams = append(streams, s)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, s := range streams {
+		s.Close(websocket.CloseServiceRestart, "server restarting")
+	}
+}
+
+// Shutdown отправляет всем клиентам close frame "server restarting" и ждёт,
+// пока они закроют соединения. По истечении ctx оставшиеся соединения закрываются принудительно.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	clients := make([]subscriber, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range clients {
+		c.Close(websocket.CloseServiceRestart, "server restarting")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, c := range clients {
+			c.abort()
+		}
+		return fmt.Errorf("wait websocket clients: %w", ctx.Err())
+	}
+}
+
+func (h *Hub) Unsubscribe(c subscriber, lectureID int64) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -59,44 +130,20 @@ func (h *Hub) Unsubscribe(c *Client, lectureID int64) {
 	}
 }
 
+// Broadcast рассылает уже сохранённое событие всем клиентам, подписанным на лекцию.
 func (h *Hub) Broadcast(lectureID int64, data []byte) {
 	// 1. snapshot клиентов
 	h.mu.Lock()
 	clientsMap := h.lectures[lectureID]
 
-	clients := make([]*Client, 0, len(clientsMap))
+	clients := make([]subscriber, 0, len(clientsMap))
 	for c := range clientsMap {
 		clients = append(clients, c)
 	}
 	h.mu.Unlock()
 
-	request := struct {
-		LectureID int64  `json:"lecture_id"`
-		PersonID  string `json:"person_id"`
-	}{}
-
-	err := json.Unmarshal(data, &request)
-	if err != nil {
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*60)
-	defer cancel()
-
-	user, err := h.serv.AddUserVisitsLecture(ctx, request.PersonID, request.LectureID)
-	if err != nil {
-		log.Printf("ERROR: apply time of visit for %s - %v", request.PersonID, err)
-		return
-	}
-
-	data, err = json.Marshal(user)
-	if err != nil {
-		return
-	}
-
-	// 2. отправка БЕЗ mutex
+	// 2. отправка БЕЗ mutex и без блокировки: медленный клиент не тормозит консьюмер лекции
 	for _, c := range clients {
-		c.send <- data
-		log.Printf("INFO: send to %s - body: %v", c.conn.RemoteAddr().String(), *user)
+		c.enqueue(data)
 	}
 }
@@ -0,0 +1,59 @@
+package ws
+
+import (
+	"time"
+
+	"monitoring_backend/internal/config"
+)
+
+// Коды закрытия соединения, которые сервер отправляет клиенту.
+// 4000–4999 — коды приложения по RFC 6455.
+const (
+	// CloseSlowConsumer клиент не успевал читать сообщения и его очередь переполнилась.
+	CloseSlowConsumer = 4008
+	// ClosePingTimeout клиент не отвечал на ping дольше pong_timeout.
+	ClosePingTimeout = 4009
+)
+
+const (
+	SlowClientDisconnect = "disconnect"
+	SlowClientDrop       = "drop"
+)
+
+// options параметры соединения клиента, собранные из config.WSConfig с умолчаниями.
+type options struct {
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+	writeTimeout   time.Duration
+	sendBuffer     int
+	dropSlow       bool
+	maxMessageSize int64
+}
+
+func newOptions(cfg config.WSConfig) options {
+	o := options{
+		pingInterval:   cfg.PingInterval,
+		pongTimeout:    cfg.PongTimeout,
+		writeTimeout:   cfg.WriteTimeout,
+		sendBuffer:     cfg.SendBuffer,
+		dropSlow:       cfg.SlowClientPolicy == SlowClientDrop,
+		maxMessageSize: cfg.MaxMessageSize,
+	}
+	if o.pongTimeout <= 0 {
+		o.pongTimeout = 60 * time.Second
+	}
+	// ping должен успеть дойти и вернуться до истечения read deadline
+	if o.pingInterval <= 0 || o.pingInterval >= o.pongTimeout {
+		o.pingInterval = o.pongTimeout * 9 / 10
+	}
+	if o.writeTimeout <= 0 {
+		o.writeTimeout = 10 * time.Second
+	}
+	if o.sendBuffer <= 0 {
+		o.sendBuffer = 256
+	}
+	if o.maxMessageSize <= 0 {
+		o.maxMessageSize = 4096
+	}
+	return o
+}
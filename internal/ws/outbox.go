@@ -0,0 +1,78 @@
+package ws
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// outbox очередь исходящих событий подписчика hub — WebSocket-клиента или SSE-потока.
+// Общая для обоих транспортов политика медленного клиента и сигнал закрытия.
+type outbox struct {
+	send   chan []byte
+	opts   options
+	remote string
+	userID string
+
+	done        chan struct{} // закрывается Close: транспорт сообщает клиенту причину и завершается
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+
+	dropped atomic.Int64 // сообщений выброшено из-за переполненной очереди (политика drop)
+}
+
+func newOutbox(opts options, remote, userID string) *outbox {
+	return &outbox{
+		send:   make(chan []byte, opts.sendBuffer),
+		opts:   opts,
+		remote: remote,
+		userID: userID,
+		done:   make(chan struct{}),
+	}
+}
+
+// enqueue ставит событие в очередь, не блокируясь. Если очередь переполнена,
+// по политике slow_client_policy сообщение выбрасывается или клиент отключается с кодом 4008.
+func (o *outbox) enqueue(data []byte) bool {
+	select {
+	case <-o.done:
+		return false
+	default:
+	}
+
+	select {
+	case o.send <- data:
+		return true
+	default:
+	}
+
+	if o.opts.dropSlow {
+		if n := o.dropped.Add(1); n == 1 || n%100 == 0 {
+			log.Printf("WARN: slow client %s (%s), dropped %d messages", o.remote, o.userID, n)
+		}
+		return false
+	}
+
+	log.Printf("WARN: slow client %s (%s), disconnecting", o.remote, o.userID)
+	o.Close(CloseSlowConsumer, "slow consumer")
+	return false
+}
+
+// push отправляет ответ только этому клиенту: в отличие от enqueue ждёт места в очереди.
+func (o *outbox) push(data []byte) {
+	select {
+	case o.send <- data:
+	case <-o.done:
+	}
+}
+
+// Close просит транспорт сообщить клиенту код и причину закрытия и остановиться.
+// Не блокируется, поэтому его можно вызывать из Broadcast.
+func (o *outbox) Close(code int, reason string) {
+	o.closeOnce.Do(func() {
+		o.closeCode = code
+		o.closeReason = reason
+		close(o.done)
+	})
+}
@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultNotifyChannel = "ws_events"
+	// лимит payload у NOTIFY — 8000 байт
+	maxNotifyPayload = 7999
+
+	notifyTimeout        = 5 * time.Second
+	listenInitialBackoff = 1 * time.Second
+	listenMaxBackoff     = 30 * time.Second
+)
+
+// notification payload NOTIFY: событие лекции в том виде, в каком его получит клиент.
+type notification struct {
+	LectureID int64           `json:"lecture_id"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// PGBroadcaster рассылает события через Postgres NOTIFY. Каждая реплика слушает канал (Run)
+// и отдаёт полученные события своим клиентам через локальный Hub, в том числе свои же —
+// поэтому клиент получает событие ровно один раз независимо от того, какая реплика его создала.
+type PGBroadcaster struct {
+	pool    *pgxpool.Pool
+	hub     *Hub
+	channel string
+}
+
+func NewPGBroadcaster(pool *pgxpool.Pool, hub *Hub, channel string) *PGBroadcaster {
+	if channel == "" {
+		channel = defaultNotifyChannel
+	}
+	return &PGBroadcaster{
+		pool:    pool,
+		hub:     hub,
+		channel: channel,
+	}
+}
+
+// Broadcast публикует событие в канал. Если событие не влезает в NOTIFY или БД недоступна,
+// оно доставляется только клиентам этой реплики: остальные догонят пропущенное по since.
+func (b *PGBroadcaster) Broadcast(lectureID int64, data []byte) {
+	payload, err := json.Marshal(notification{LectureID: lectureID, Data: data})
+	if err != nil {
+		log.Printf("ERROR: marshal ws notification for lecture %d: %v", lectureID, err)
+		return
+	}
+	if len(payload) > maxNotifyPayload {
+		log.Printf("WARN: ws event for lecture %d is %d bytes, too large for NOTIFY, delivering locally", lectureID, len(payload))
+		b.hub.Broadcast(lectureID, data)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	if _, err := b.pool.Exec(ctx, `SELECT pg_notify($1, $2)`, b.channel, string(payload)); err != nil {
+		log.Printf("WARN: notify ws event for lecture %d: %v, delivering locally", lectureID, err)
+		b.hub.Broadcast(lectureID, data)
+	}
+}
+
+// Run слушает канал и рассылает события локальным клиентам, пока не отменён ctx.
+// При потере соединения переподключается с экспоненциальной паузой.
+func (b *PGBroadcaster) Run(ctx context.Context) {
+	backoff := listenInitialBackoff
+	for {
+		err := b.listen(ctx, func() { backoff = listenInitialBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("WARN: ws listener on %q stopped: %v, reconnect in %s", b.channel, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, listenMaxBackoff)
+	}
+}
+
+// listen держит отдельное соединение с LISTEN. Соединение забирается из пула насовсем
+// (Hijack), чтобы подписка на канал не досталась другому запросу.
+func (b *PGBroadcaster) listen(ctx context.Context, ready func()) error {
+	pc, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	conn := pc.Hijack()
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	ready()
+	log.Printf("INFO: ws listener subscribed to %q", b.channel)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return fmt.Errorf("wait notification: %w", err)
+		}
+
+		var msg notification
+		if err := json.Unmarshal([]byte(n.Payload), &msg); err != nil {
+			log.Printf("WARN: bad ws notification: %v", err)
+			continue
+		}
+		b.hub.Broadcast(msg.LectureID, msg.Data)
+	}
+}
@@ -0,0 +1,76 @@
+package ws
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"monitoring_backend/internal/domain"
+)
+
+const (
+	// presenceTimeout ограничивает построение presence_snapshot и догрузку пропущенных событий.
+	presenceTimeout = 10 * time.Second
+	// replayLimit если пропущено больше событий, вместо них отправляется presence_snapshot.
+	replayLimit = 500
+	// defaultGapSeconds совпадает с умолчанием gap_seconds в REST API посещаемости.
+	defaultGapSeconds = 120
+)
+
+// PresenceProvider источник текущего присутствия на лекции для новых подписчиков.
+type PresenceProvider interface {
+	Presence(ctx context.Context, lectureID int64, gapSeconds int) ([]domain.StudentPresence, int64, error)
+	ListVisitsSince(ctx context.Context, lectureID int64, afterID int64, limit int) ([]domain.ClassVisitDetails, error)
+}
+
+// missedVisits события visit лекции после since. false — пропущено больше replayLimit
+// или выборка не удалась, тогда подписчику нужен полный снапшот.
+func missedVisits(ctx context.Context, presence PresenceProvider, lectureID, since int64) ([]domain.ClassVisitDetails, bool) {
+	visits, err := presence.ListVisitsSince(ctx, lectureID, since, replayLimit+1)
+	if err != nil {
+		log.Printf("ERROR: replay visits (lecture_id=%d since=%d): %v", lectureID, since, err)
+		return nil, false
+	}
+	if len(visits) > replayLimit {
+		return nil, false
+	}
+	return visits, true
+}
+
+func presenceSnapshot(ctx context.Context, presence PresenceProvider, lectureID int64, gapSeconds int) (PresenceSnapshotPayload, error) {
+	students, lastID, err := presence.Presence(ctx, lectureID, gapSeconds)
+	if err != nil {
+		return PresenceSnapshotPayload{}, err
+	}
+
+	payload := PresenceSnapshotPayload{
+		LectureID:   lectureID,
+		GapSeconds:  gapSeconds,
+		GeneratedAt: time.Now(),
+		Students:    make([]StudentPresence, 0, len(students)),
+	}
+	if lastID > 0 {
+		payload.LastEventID = strconv.FormatInt(lastID, 10)
+	}
+	for _, s := range students {
+		item := StudentPresence{
+			User:           NewUserResponse(s.User),
+			Group:          s.User.GroupCode,
+			PresentSeconds: s.PresentSeconds,
+			Snapshots:      s.Snapshots,
+			FirstSeen:      s.FirstSeen,
+			LastSeen:       s.LastSeen,
+		}
+		if o := s.Override; o != nil {
+			item.Override = &AttendanceOverridePayload{
+				Status:    string(o.Status),
+				Reason:    o.Reason,
+				UpdatedAt: o.UpdatedAt,
+				UpdatedBy: o.UpdatedBy,
+			}
+		}
+		payload.Students = append(payload.Students, item)
+	}
+	return payload, nil
+}
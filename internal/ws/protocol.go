@@ -0,0 +1,205 @@
+package ws
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitoring_backend/internal/domain"
+)
+
+// Все сообщения в обе стороны — конверт {"type": ..., "id": ..., "payload": {...}}.
+// id команды клиента возвращается в ответе на неё (subscribe_ack, error),
+// у событий сервера id — идентификатор события, если он есть.
+const (
+	// клиент → сервер
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+
+	// сервер → клиент
+	TypeSubscribeAck     = "subscribe_ack"
+	TypeUnsubscribeAck   = "unsubscribe_ack"
+	TypeError            = "error"
+	TypeVisit            = "visit"
+	TypeLectureStarted   = "lecture_started"
+	TypeLectureEnded     = "lecture_ended"
+	TypeConsumerStatus   = "consumer_status"
+	TypePresenceSnapshot = "presence_snapshot"
+	TypeCameraDown       = "camera_down"
+
+	// только SSE: последнее событие перед закрытием потока
+	TypeClose = "close"
+)
+
+// Коды ошибок в ErrorPayload.
+const (
+	ErrCodeInvalidMessage  = "invalid_message"
+	ErrCodeUnknownType     = "unknown_type"
+	ErrCodeInvalidPayload  = "invalid_payload"
+	ErrCodeForbidden       = "forbidden"
+	ErrCodeLectureNotFound = "lecture_not_found"
+	ErrCodeInternal        = "internal_error"
+)
+
+type Envelope struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type outgoing struct {
+	Type    string `json:"type"`
+	ID      string `json:"id,omitempty"`
+	Payload any    `json:"payload,omitempty"`
+}
+
+// Encode собирает сообщение сервера.
+func Encode(msgType, id string, payload any) ([]byte, error) {
+	return json.Marshal(outgoing{Type: msgType, ID: id, Payload: payload})
+}
+
+type SubscribePayload struct {
+	LectureID int64 `json:"lecture_id"`
+	// Since id последнего полученного события visit: сервер досылает пропущенные события
+	// вместо presence_snapshot (если их не слишком много).
+	Since string `json:"since,omitempty"`
+	// GapSeconds максимальный разрыв между снапшотами для склейки присутствия, по умолчанию 120.
+	GapSeconds int `json:"gap_seconds,omitempty"`
+}
+
+type SubscribeAckPayload struct {
+	LectureID int64 `json:"lecture_id"`
+}
+
+type ErrorPayload struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	LectureID int64  `json:"lecture_id,omitempty"`
+}
+
+// ClosePayload причина закрытия SSE-потока, коды те же, что у close frame WebSocket.
+type ClosePayload struct {
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// LectureStatePayload событие lecture_started / lecture_ended.
+type LectureStatePayload struct {
+	LectureID int64     `json:"lecture_id"`
+	SessionID int64     `json:"session_id"`
+	State     string    `json:"state"` // running, stopped, finished, failed
+	At        time.Time `json:"at"`
+}
+
+// PresenceSnapshotPayload присутствие всех студентов лекции на момент подписки.
+// Снапшоты с id <= LastEventID уже учтены — события visit с такими id клиент может пропустить.
+type PresenceSnapshotPayload struct {
+	LectureID   int64             `json:"lecture_id"`
+	GapSeconds  int               `json:"gap_seconds"`
+	LastEventID string            `json:"last_event_id,omitempty"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Students    []StudentPresence `json:"students"`
+}
+
+type StudentPresence struct {
+	User           UserResponse `json:"user"`
+	Group          *string      `json:"group"`
+	PresentSeconds int64        `json:"present_seconds"`
+	Snapshots      int          `json:"snapshots"`
+	FirstSeen      *time.Time   `json:"first_seen,omitempty"`
+	LastSeen       *time.Time   `json:"last_seen,omitempty"`
+	// Override ручная отметка преподавателя; её статус заменяет посчитанный по снапшотам.
+	Override *AttendanceOverridePayload `json:"override,omitempty"`
+}
+
+type AttendanceOverridePayload struct {
+	Status    string    `json:"status"` // present, absent, excused
+	Reason    string    `json:"reason"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy *string   `json:"updated_by,omitempty"`
+}
+
+// ConsumerStatusPayload состояние подключения консьюмера лекции к RabbitMQ.
+// Отправляется подписчикам при каждом изменении: reconnecting, failed и обратно connected.
+type ConsumerStatusPayload struct {
+	LectureID int64     `json:"lecture_id"`
+	State     string    `json:"state"`
+	Attempt   int       `json:"attempt,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Since     time.Time `json:"since"`
+}
+
+func NewConsumerStatusPayload(lectureID int64, h domain.ConsumerHealth) ConsumerStatusPayload {
+	return ConsumerStatusPayload{
+		LectureID: lectureID,
+		State:     string(h.State),
+		Attempt:   h.Attempt,
+		LastError: h.LastError,
+		Since:     h.Since,
+	}
+}
+
+// CameraDownPayload камера в аудитории лекции перестала присылать heartbeat (reason = heartbeat_timeout)
+// или сама сообщила об отказе (reason = reported). Пока камера не работает, её студенты не распознаются.
+type CameraDownPayload struct {
+	LectureID  int64      `json:"lecture_id"`
+	CameraID   string     `json:"camera_id"`
+	Room       string     `json:"room"`
+	Reason     string     `json:"reason"`
+	Message    string     `json:"message,omitempty"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+const (
+	CameraDownHeartbeatTimeout = "heartbeat_timeout"
+	CameraDownReported         = "reported"
+)
+
+func NewCameraDownPayload(lectureID int64, s domain.CameraStatus) CameraDownPayload {
+	p := CameraDownPayload{
+		LectureID:  lectureID,
+		CameraID:   s.CameraID,
+		Reason:     CameraDownHeartbeatTimeout,
+		LastSeenAt: s.LastSeenAt,
+	}
+	if s.RoomCode != nil {
+		p.Room = *s.RoomCode
+	}
+	if s.Reported == domain.CameraDown {
+		p.Reason = CameraDownReported
+		if s.LastError != nil {
+			p.Message = *s.LastError
+		}
+	}
+	return p
+}
+
+// decodeCommand разбирает команду клиента. Старый формат {"action": "subscribe", "lecture_id": "42"}
+// приводится к конверту, чтобы не ломать клиентов, которые ещё не перешли на новый протокол.
+func decodeCommand(data []byte) (Envelope, error) {
+	var raw struct {
+		Envelope
+		Action    string          `json:"action"`
+		LectureID json.RawMessage `json:"lecture_id"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Envelope{}, err
+	}
+
+	env := raw.Envelope
+	if env.Type != "" || raw.Action == "" {
+		return env, nil
+	}
+
+	env.Type = raw.Action
+	lectureID, err := strconv.ParseInt(strings.Trim(string(raw.LectureID), `"`), 10, 64)
+	if err != nil {
+		// тип известен, а payload битый — пусть обработчик ответит invalid_payload
+		env.Payload = json.RawMessage(`{}`)
+		return env, nil
+	}
+	env.Payload, _ = json.Marshal(SubscribePayload{LectureID: lectureID})
+
+	return env, nil
+}
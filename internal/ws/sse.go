@@ -0,0 +1,241 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitoring_backend/internal/auth"
+	"monitoring_backend/internal/config"
+	"monitoring_backend/internal/domain"
+	"monitoring_backend/internal/http/response"
+)
+
+// stream SSE-подписка на одну лекцию. События приходят из hub так же, как WebSocket-клиентам.
+type stream struct {
+	*outbox
+	cancel context.CancelFunc
+}
+
+// abort обрывает запрос, если поток не завершился сам после Close.
+func (s *stream) abort() {
+	s.cancel()
+}
+
+// EventsHandler godoc
+// @Summary Live feed of a lecture via Server-Sent Events
+// @Description Alternative to /api/ws for clients that can't use WebSocket (kiosks, scripts, proxies).
+// @Description Streams the same events as the WebSocket subscription of the lecture:
+// @Description "event:" is the message type (visit, lecture_started, lecture_ended, consumer_status,
+// @Description presence_snapshot), "data:" is its payload, "id:" is the event id when it has one.
+// @Description
+// @Description Authentication: Authorization: Bearer <JWT> header or query parameter token=<JWT>
+// @Description (EventSource can't send headers). Only the lecture's teacher or an admin has access.
+// @Description
+// @Description Resume: on reconnect EventSource sends Last-Event-ID, the server replays missed visit events
+// @Description (or sends presence_snapshot when more than 500 were missed). Without it presence_snapshot is sent first.
+// @Description Events may be delivered twice, deduplicate by id.
+// @Description
+// @Description The server sends a ": ping" comment every ping_interval. Before closing the stream it sends
+// @Description event "close" {"code", "reason"}: 1012 server restarting, 4008 slow consumer.
+// @Tags websocket
+// @Produce text/event-stream
+// @Param id path int true "Lecture ID"
+// @Param token query string false "JWT, если его нельзя передать заголовком"
+// @Param Last-Event-ID header string false "id последнего полученного события"
+// @Param last_event_id query string false "то же, что Last-Event-ID, для первого подключения"
+// @Param gap_seconds query int false "максимальный разрыв между снапшотами для presence_snapshot, по умолчанию 120"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {string} string "authorization required"
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /api/lectures/{id}/events [get]
+func EventsHandler(hub *Hub, jwtManager *auth.JWTManager, authorizer Authorizer, presence PresenceProvider, cfg config.WSConfig) http.HandlerFunc {
+	opts := newOptions(cfg)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := tokenFromRequest(r)
+		if token == "" {
+			http.Error(w, "authorization required", http.StatusUnauthorized)
+			return
+		}
+		claims, err := jwtManager.Parse(token)
+		if err != nil {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		lectureID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil || lectureID <= 0 {
+			response.WriteError(w, http.StatusBadRequest, "invalid lecture id")
+			return
+		}
+
+		lastEventID := r.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = r.URL.Query().Get("last_event_id")
+		}
+		var since int64
+		if lastEventID != "" {
+			if since, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || since < 0 {
+				response.WriteError(w, http.StatusBadRequest, "Last-Event-ID must be a visit event id")
+				return
+			}
+		}
+
+		gapSeconds := defaultGapSeconds
+		if v := r.URL.Query().Get("gap_seconds"); v != "" {
+			if gapSeconds, err = strconv.Atoi(v); err != nil || gapSeconds <= 0 {
+				response.WriteError(w, http.StatusBadRequest, "gap_seconds must be a positive integer")
+				return
+			}
+		}
+
+		authCtx, cancel := context.WithTimeout(r.Context(), authorizeTimeout)
+		err = authorizer.AuthorizeClass(authCtx, claims.UserID, claims.Role, domain.ClassLecture, lectureID)
+		cancel()
+		switch {
+		case errors.Is(err, domain.ErrClassAccessDenied):
+			response.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		case errors.Is(err, domain.ErrClassNotFound):
+			response.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case err != nil:
+			log.Printf("ERROR: authorize %s for lecture %d: %v", claims.UserID, lectureID, err)
+			response.WriteError(w, http.StatusInternalServerError, "failed to check access")
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		s := &stream{outbox: newOutbox(opts, r.RemoteAddr, claims.UserID), cancel: cancel}
+		hub.AddClient(s)
+		defer hub.RemoveClient(s)
+		hub.Subscribe(s, lectureID)
+		log.Printf("INFO: %s subscribed to %d via SSE", claims.UserID, lectureID)
+
+		rc := http.NewResponseController(w)
+		// таймауты http.Server рассчитаны на обычные запросы и оборвали бы поток
+		_ = rc.SetReadDeadline(time.Time{})
+
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("X-Accel-Buffering", "no") // nginx не должен буферизовать поток
+		w.WriteHeader(http.StatusOK)
+
+		write := func(data []byte) error {
+			if err := rc.SetWriteDeadline(time.Now().Add(opts.writeTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+			if err := writeEvent(w, data); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+
+		// подписка оформлена до выборки, поэтому между догрузкой и живыми событиями ничего не теряется;
+		// догрузка пишется напрямую, чтобы не переполнить очередь потока
+		if err := s.catchUp(ctx, presence, lectureID, lastEventID != "", since, gapSeconds, write); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(opts.pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-s.done:
+				data, err := Encode(TypeClose, "", ClosePayload{Code: s.closeCode, Reason: s.closeReason})
+				if err == nil {
+					_ = write(data)
+				}
+				return
+
+			case data := <-s.send:
+				if err := write(data); err != nil {
+					return
+				}
+
+			case <-ticker.C:
+				_ = rc.SetWriteDeadline(time.Now().Add(opts.writeTimeout))
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// catchUp досылает пропущенные visit после since или, если resume невозможен, presence_snapshot.
+func (s *stream) catchUp(ctx context.Context, presence PresenceProvider, lectureID int64, resume bool, since int64, gapSeconds int, write func([]byte) error) error {
+	ctx, cancel := context.WithTimeout(ctx, presenceTimeout)
+	defer cancel()
+
+	if resume {
+		if visits, ok := missedVisits(ctx, presence, lectureID, since); ok {
+			for _, v := range visits {
+				data, err := Encode(TypeVisit, strconv.FormatInt(v.Visit.ID, 10), NewVisitResponse(v.Visit, v.User))
+				if err != nil {
+					return err
+				}
+				if err := write(data); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	payload, err := presenceSnapshot(ctx, presence, lectureID, gapSeconds)
+	if err != nil {
+		log.Printf("ERROR: build presence snapshot (lecture_id=%d): %v", lectureID, err)
+		data, err := Encode(TypeError, "", ErrorPayload{Code: ErrCodeInternal, Message: "failed to load presence", LectureID: lectureID})
+		if err != nil {
+			return err
+		}
+		return write(data)
+	}
+
+	data, err := Encode(TypePresenceSnapshot, payload.LastEventID, payload)
+	if err != nil {
+		return err
+	}
+	return write(data)
+}
+
+// writeEvent переводит конверт сообщения в формат SSE: type → event, id → id, payload → data.
+func writeEvent(w http.ResponseWriter, data []byte) error {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return fmt.Errorf("decode event: %w", err)
+	}
+
+	if env.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", env.ID); err != nil {
+			return err
+		}
+	}
+	payload := env.Payload
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", env.Type, payload)
+	return err
+}
@@ -0,0 +1,23 @@
+create table if not exists visits.lecture_sessions (
+    id SERIAL PRIMARY KEY,
+    lecture_id BIGINT NOT NULL,
+    queue TEXT NOT NULL,
+    state VARCHAR(25) NOT NULL,
+    started_at timestamptz NOT NULL,
+    stopped_at timestamptz,
+    started_by TEXT,
+    stopped_by TEXT,
+    foreign key (lecture_id) references universities_data.lectures(id),
+    foreign key (started_by) references cores.users(isu),
+    foreign key (stopped_by) references cores.users(isu)
+);
+
+-- у лекции может быть только одна активная сессия
+create unique index if not exists uq_lecture_sessions_running
+    on visits.lecture_sessions(lecture_id) where state = 'running';
+
+create index if not exists idx_lecture_sessions_lecture_id
+    on visits.lecture_sessions(lecture_id);
+
+create index if not exists idx_lecture_sessions_state
+    on visits.lecture_sessions(state);
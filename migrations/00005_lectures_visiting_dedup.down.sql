@@ -0,0 +1 @@
+drop index if exists visits.uq_lecture_visiting_lecture_user_date;
@@ -0,0 +1,10 @@
+-- убираем дубли, которые успели накопиться от повторных доставок из RabbitMQ
+delete from visits.lectures_visiting a
+    using visits.lectures_visiting b
+where a.id > b.id
+  and a.lecture_id = b.lecture_id
+  and a.user_id = b.user_id
+  and a.date = b.date;
+
+create unique index if not exists uq_lecture_visiting_lecture_user_date
+    on visits.lectures_visiting(lecture_id, user_id, date);
@@ -0,0 +1,7 @@
+alter table visits.lectures_visiting
+    drop column if exists confidence,
+    drop column if exists camera_id,
+    drop column if exists received_at;
+
+alter table visits.lectures_visiting
+    rename column captured_at to date;
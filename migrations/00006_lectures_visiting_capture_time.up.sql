@@ -0,0 +1,18 @@
+-- date фактически хранил время обработки сообщения; теперь это время кадра на камере
+alter table visits.lectures_visiting
+    rename column date to captured_at;
+
+alter table visits.lectures_visiting
+    add column if not exists received_at timestamptz;
+
+update visits.lectures_visiting
+    set received_at = captured_at
+    where received_at is null;
+
+alter table visits.lectures_visiting
+    alter column received_at set not null,
+    alter column received_at set default now();
+
+alter table visits.lectures_visiting
+    add column if not exists camera_id TEXT,
+    add column if not exists confidence REAL;
@@ -0,0 +1,26 @@
+create table if not exists visits.dead_letters (
+    id SERIAL PRIMARY KEY,
+    source_queue TEXT NOT NULL,
+    lecture_id BIGINT,
+    reason VARCHAR(64) NOT NULL,
+    error TEXT NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    content_type TEXT,
+    body BYTEA NOT NULL,
+    failed_at timestamptz NOT NULL,
+    archived_at timestamptz NOT NULL DEFAULT now(),
+    status VARCHAR(25) NOT NULL DEFAULT 'pending',
+    replay_attempts INT NOT NULL DEFAULT 0,
+    resolved_at timestamptz,
+    resolved_by TEXT,
+    foreign key (resolved_by) references cores.users(isu)
+);
+
+create index if not exists idx_dead_letters_status
+    on visits.dead_letters(status);
+
+create index if not exists idx_dead_letters_reason
+    on visits.dead_letters(reason);
+
+create index if not exists idx_dead_letters_lecture_id
+    on visits.dead_letters(lecture_id);
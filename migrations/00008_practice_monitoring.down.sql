@@ -0,0 +1,14 @@
+alter table visits.dead_letters
+    drop column if exists kind;
+
+drop table if exists visits.practice_sessions;
+
+drop index if exists visits.uq_practices_visiting_practice_user_date;
+
+alter table visits.practices_visiting
+    drop column if exists confidence,
+    drop column if exists camera_id,
+    drop column if exists received_at;
+
+alter table visits.practices_visiting
+    rename column captured_at to date;
@@ -0,0 +1,56 @@
+-- practices_visiting приводим к тому же виду, что и lectures_visiting (00005, 00006)
+delete from visits.practices_visiting a
+    using visits.practices_visiting b
+where a.id > b.id
+  and a.practice_id = b.practice_id
+  and a.user_id = b.user_id
+  and a.date = b.date;
+
+alter table visits.practices_visiting
+    rename column date to captured_at;
+
+alter table visits.practices_visiting
+    add column if not exists received_at timestamptz;
+
+update visits.practices_visiting
+    set received_at = captured_at
+    where received_at is null;
+
+alter table visits.practices_visiting
+    alter column received_at set not null,
+    alter column received_at set default now();
+
+alter table visits.practices_visiting
+    add column if not exists camera_id TEXT,
+    add column if not exists confidence REAL;
+
+create unique index if not exists uq_practices_visiting_practice_user_date
+    on visits.practices_visiting(practice_id, user_id, captured_at);
+
+create table if not exists visits.practice_sessions (
+    id SERIAL PRIMARY KEY,
+    practice_id BIGINT NOT NULL,
+    queue TEXT NOT NULL,
+    state VARCHAR(25) NOT NULL,
+    started_at timestamptz NOT NULL,
+    stopped_at timestamptz,
+    started_by TEXT,
+    stopped_by TEXT,
+    foreign key (practice_id) references universities_data.practices(id),
+    foreign key (started_by) references cores.users(isu),
+    foreign key (stopped_by) references cores.users(isu)
+);
+
+-- у практики может быть только одна активная сессия
+create unique index if not exists uq_practice_sessions_running
+    on visits.practice_sessions(practice_id) where state = 'running';
+
+create index if not exists idx_practice_sessions_practice_id
+    on visits.practice_sessions(practice_id);
+
+create index if not exists idx_practice_sessions_state
+    on visits.practice_sessions(state);
+
+-- вид занятия, к которому относится упавшее сообщение: по нему выбирается конвейер при переигрывании
+alter table visits.dead_letters
+    add column if not exists kind VARCHAR(25) NOT NULL DEFAULT 'lecture';
@@ -0,0 +1,191 @@
+-- занятия других видов (lab, exam, consultation) в старой схеме хранить негде — они теряются
+create table if not exists universities_data.lectures (
+    id SERIAL PRIMARY KEY,
+    date timestamptz not null,
+    subject_id BIGINT NOT NULL,
+    teacher_id TEXT NOT NULL,
+    foreign key (subject_id) REFERENCES universities_data.subjects(id),
+    foreign key (teacher_id) REFERENCES cores.users(isu)
+);
+
+create index if not exists idx_teacher_lectures
+    ON universities_data.lectures(teacher_id);
+
+create table if not exists universities_data.practices (
+    id SERIAL PRIMARY KEY,
+    date timestamptz NOT NULL,
+    subject_id BIGINT NOT NULL,
+    teacher_id TEXT NOT NULL,
+    foreign key (subject_id) references universities_data.subjects(id),
+    foreign key (teacher_id) references cores.users(isu)
+);
+
+create index if not exists idx_practices_teacher
+    ON universities_data.practices(teacher_id);
+
+insert into universities_data.lectures (id, date, subject_id, teacher_id)
+select id, date, subject_id, teacher_id from universities_data.classes where kind = 'lecture';
+
+insert into universities_data.practices (id, date, subject_id, teacher_id)
+select id, date, subject_id, teacher_id from universities_data.classes where kind = 'practice';
+
+select setval(pg_get_serial_sequence('universities_data.lectures', 'id'),
+    greatest((select coalesce(max(id), 0) from universities_data.lectures), 1));
+select setval(pg_get_serial_sequence('universities_data.practices', 'id'),
+    greatest((select coalesce(max(id), 0) from universities_data.practices), 1));
+
+create table if not exists universities_data.lectures_groups (
+    id SERIAL PRIMARY KEY,
+    lecture_id BIGINT NOT NULL,
+    group_id VARCHAR(25) NOT NULL,
+    foreign key (lecture_id) references universities_data.lectures(id),
+    foreign key (group_id) references universities_data.groups(code),
+    UNIQUE (lecture_id, group_id)
+);
+
+create index if not exists idx_lectures_groups_lecture_id
+    ON universities_data.lectures_groups(lecture_id);
+
+create index if not exists idx_lectures_groups_group_id
+    ON universities_data.lectures_groups(group_id);
+
+create table if not exists universities_data.practices_groups (
+    id SERIAL PRIMARY KEY,
+    practice_id BIGINT NOT NULL,
+    group_id VARCHAR(25) NOT NULL,
+    foreign key (practice_id) references universities_data.practices(id),
+    foreign key (group_id) references universities_data.groups(code),
+    UNIQUE (practice_id, group_id)
+);
+
+create index if not exists idx_practices_groups_practice_id
+    ON universities_data.practices_groups(practice_id);
+
+create index if not exists idx_practices_groups_group_id
+    ON universities_data.practices_groups(group_id);
+
+insert into universities_data.lectures_groups (lecture_id, group_id)
+select class_id, group_id from universities_data.classes_groups where kind = 'lecture';
+
+insert into universities_data.practices_groups (practice_id, group_id)
+select class_id, group_id from universities_data.classes_groups where kind = 'practice';
+
+create table if not exists visits.lectures_visiting (
+    id SERIAL PRIMARY KEY,
+    lecture_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    captured_at timestamptz NOT NULL,
+    received_at timestamptz NOT NULL DEFAULT now(),
+    camera_id TEXT,
+    confidence REAL,
+    foreign key (lecture_id) references universities_data.lectures(id),
+    foreign key (user_id) references cores.users(isu)
+);
+
+create index if not exists idx_lecture_visiting_lecture_id
+    on visits.lectures_visiting(lecture_id);
+
+create index if not exists idx_lecture_visiting_user_id_date
+    on visits.lectures_visiting(user_id, captured_at);
+
+create unique index if not exists uq_lecture_visiting_lecture_user_date
+    on visits.lectures_visiting(lecture_id, user_id, captured_at);
+
+create table if not exists visits.practices_visiting (
+    id SERIAL PRIMARY KEY,
+    practice_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    captured_at timestamptz NOT NULL,
+    received_at timestamptz NOT NULL DEFAULT now(),
+    camera_id TEXT,
+    confidence REAL,
+    foreign key (practice_id) references universities_data.practices(id),
+    foreign key (user_id) references cores.users(isu)
+);
+
+create index if not exists idx_practices_visiting_practice_id
+    on visits.practices_visiting(practice_id);
+
+create index if not exists idx_practices_visiting_user_id_date
+    on visits.practices_visiting(user_id, captured_at);
+
+create unique index if not exists uq_practices_visiting_practice_user_date
+    on visits.practices_visiting(practice_id, user_id, captured_at);
+
+insert into visits.lectures_visiting (id, lecture_id, user_id, captured_at, received_at, camera_id, confidence)
+select id, class_id, user_id, captured_at, received_at, camera_id, confidence
+from visits.classes_visiting where kind = 'lecture';
+
+insert into visits.practices_visiting (id, practice_id, user_id, captured_at, received_at, camera_id, confidence)
+select id, class_id, user_id, captured_at, received_at, camera_id, confidence
+from visits.classes_visiting where kind = 'practice';
+
+select setval(pg_get_serial_sequence('visits.lectures_visiting', 'id'),
+    greatest((select coalesce(max(id), 0) from visits.lectures_visiting), 1));
+select setval(pg_get_serial_sequence('visits.practices_visiting', 'id'),
+    greatest((select coalesce(max(id), 0) from visits.practices_visiting), 1));
+
+create table if not exists visits.lecture_sessions (
+    id SERIAL PRIMARY KEY,
+    lecture_id BIGINT NOT NULL,
+    queue TEXT NOT NULL,
+    state VARCHAR(25) NOT NULL,
+    started_at timestamptz NOT NULL,
+    stopped_at timestamptz,
+    started_by TEXT,
+    stopped_by TEXT,
+    foreign key (lecture_id) references universities_data.lectures(id),
+    foreign key (started_by) references cores.users(isu),
+    foreign key (stopped_by) references cores.users(isu)
+);
+
+create unique index if not exists uq_lecture_sessions_running
+    on visits.lecture_sessions(lecture_id) where state = 'running';
+
+create index if not exists idx_lecture_sessions_lecture_id
+    on visits.lecture_sessions(lecture_id);
+
+create index if not exists idx_lecture_sessions_state
+    on visits.lecture_sessions(state);
+
+create table if not exists visits.practice_sessions (
+    id SERIAL PRIMARY KEY,
+    practice_id BIGINT NOT NULL,
+    queue TEXT NOT NULL,
+    state VARCHAR(25) NOT NULL,
+    started_at timestamptz NOT NULL,
+    stopped_at timestamptz,
+    started_by TEXT,
+    stopped_by TEXT,
+    foreign key (practice_id) references universities_data.practices(id),
+    foreign key (started_by) references cores.users(isu),
+    foreign key (stopped_by) references cores.users(isu)
+);
+
+create unique index if not exists uq_practice_sessions_running
+    on visits.practice_sessions(practice_id) where state = 'running';
+
+create index if not exists idx_practice_sessions_practice_id
+    on visits.practice_sessions(practice_id);
+
+create index if not exists idx_practice_sessions_state
+    on visits.practice_sessions(state);
+
+insert into visits.lecture_sessions (id, lecture_id, queue, state, started_at, stopped_at, started_by, stopped_by)
+select id, class_id, queue, state, started_at, stopped_at, started_by, stopped_by
+from visits.class_sessions where kind = 'lecture';
+
+insert into visits.practice_sessions (id, practice_id, queue, state, started_at, stopped_at, started_by, stopped_by)
+select id, class_id, queue, state, started_at, stopped_at, started_by, stopped_by
+from visits.class_sessions where kind = 'practice';
+
+select setval(pg_get_serial_sequence('visits.lecture_sessions', 'id'),
+    greatest((select coalesce(max(id), 0) from visits.lecture_sessions), 1));
+select setval(pg_get_serial_sequence('visits.practice_sessions', 'id'),
+    greatest((select coalesce(max(id), 0) from visits.practice_sessions), 1));
+
+drop table if exists visits.class_sessions;
+drop table if exists visits.classes_visiting;
+drop table if exists universities_data.classes_groups;
+drop table if exists universities_data.classes;
+drop sequence if exists universities_data.classes_id_seq;
@@ -0,0 +1,135 @@
+-- Лекции и практики переезжают в общую таблицу занятий. Занятие идентифицируется парой (kind, id):
+-- id лекций и практик сохраняются как есть, а пересекаться они могли, потому что у таблиц были
+-- разные последовательности. Новые занятия любого вида берут id из общей последовательности.
+create sequence if not exists universities_data.classes_id_seq;
+
+create table if not exists universities_data.classes (
+    kind VARCHAR(25) NOT NULL,
+    id BIGINT NOT NULL DEFAULT nextval('universities_data.classes_id_seq'),
+    date timestamptz NOT NULL,
+    subject_id BIGINT NOT NULL,
+    teacher_id TEXT NOT NULL,
+    PRIMARY KEY (kind, id),
+    CHECK (kind IN ('lecture', 'practice', 'lab', 'exam', 'consultation')),
+    foreign key (subject_id) references universities_data.subjects(id),
+    foreign key (teacher_id) references cores.users(isu)
+);
+
+alter sequence universities_data.classes_id_seq owned by universities_data.classes.id;
+
+create index if not exists idx_classes_teacher
+    on universities_data.classes(teacher_id);
+
+create index if not exists idx_classes_subject_date
+    on universities_data.classes(subject_id, date);
+
+insert into universities_data.classes (kind, id, date, subject_id, teacher_id)
+select 'lecture', id, date, subject_id, teacher_id from universities_data.lectures;
+
+insert into universities_data.classes (kind, id, date, subject_id, teacher_id)
+select 'practice', id, date, subject_id, teacher_id from universities_data.practices;
+
+select setval('universities_data.classes_id_seq', greatest(
+    (select coalesce(max(id), 0) from universities_data.classes), 1
+));
+
+create table if not exists universities_data.classes_groups (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(25) NOT NULL,
+    class_id BIGINT NOT NULL,
+    group_id VARCHAR(25) NOT NULL,
+    foreign key (kind, class_id) references universities_data.classes(kind, id),
+    foreign key (group_id) references universities_data.groups(code),
+    UNIQUE (kind, class_id, group_id)
+);
+
+create index if not exists idx_classes_groups_group_id
+    on universities_data.classes_groups(group_id);
+
+insert into universities_data.classes_groups (kind, class_id, group_id)
+select 'lecture', lecture_id, group_id from universities_data.lectures_groups;
+
+insert into universities_data.classes_groups (kind, class_id, group_id)
+select 'practice', practice_id, group_id from universities_data.practices_groups;
+
+-- id снапшотов лекций сохраняются: по ним WebSocket и SSE догоняют пропущенные события.
+-- Снапшоты практик получают новые id после лекционных.
+create table if not exists visits.classes_visiting (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(25) NOT NULL,
+    class_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    captured_at timestamptz NOT NULL,
+    received_at timestamptz NOT NULL DEFAULT now(),
+    camera_id TEXT,
+    confidence REAL,
+    foreign key (kind, class_id) references universities_data.classes(kind, id),
+    foreign key (user_id) references cores.users(isu)
+);
+
+create unique index if not exists uq_classes_visiting_class_user_date
+    on visits.classes_visiting(kind, class_id, user_id, captured_at);
+
+create index if not exists idx_classes_visiting_user_id_date
+    on visits.classes_visiting(user_id, captured_at);
+
+insert into visits.classes_visiting (id, kind, class_id, user_id, captured_at, received_at, camera_id, confidence)
+select id, 'lecture', lecture_id, user_id, captured_at, received_at, camera_id, confidence
+from visits.lectures_visiting;
+
+select setval(pg_get_serial_sequence('visits.classes_visiting', 'id'), greatest(
+    (select coalesce(max(id), 0) from visits.classes_visiting), 1
+));
+
+insert into visits.classes_visiting (kind, class_id, user_id, captured_at, received_at, camera_id, confidence)
+select 'practice', practice_id, user_id, captured_at, received_at, camera_id, confidence
+from visits.practices_visiting
+order by id;
+
+-- id сессий лекций сохраняются, сессии практик нумеруются после них
+create table if not exists visits.class_sessions (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(25) NOT NULL,
+    class_id BIGINT NOT NULL,
+    queue TEXT NOT NULL,
+    state VARCHAR(25) NOT NULL,
+    started_at timestamptz NOT NULL,
+    stopped_at timestamptz,
+    started_by TEXT,
+    stopped_by TEXT,
+    foreign key (kind, class_id) references universities_data.classes(kind, id),
+    foreign key (started_by) references cores.users(isu),
+    foreign key (stopped_by) references cores.users(isu)
+);
+
+-- у занятия может быть только одна активная сессия
+create unique index if not exists uq_class_sessions_running
+    on visits.class_sessions(kind, class_id) where state = 'running';
+
+create index if not exists idx_class_sessions_class
+    on visits.class_sessions(kind, class_id);
+
+create index if not exists idx_class_sessions_state
+    on visits.class_sessions(state);
+
+insert into visits.class_sessions (id, kind, class_id, queue, state, started_at, stopped_at, started_by, stopped_by)
+select id, 'lecture', lecture_id, queue, state, started_at, stopped_at, started_by, stopped_by
+from visits.lecture_sessions;
+
+select setval(pg_get_serial_sequence('visits.class_sessions', 'id'), greatest(
+    (select coalesce(max(id), 0) from visits.class_sessions), 1
+));
+
+insert into visits.class_sessions (kind, class_id, queue, state, started_at, stopped_at, started_by, stopped_by)
+select 'practice', practice_id, queue, state, started_at, stopped_at, started_by, stopped_by
+from visits.practice_sessions
+order by id;
+
+drop table if exists visits.practice_sessions;
+drop table if exists visits.lecture_sessions;
+drop table if exists visits.practices_visiting;
+drop table if exists visits.lectures_visiting;
+drop table if exists universities_data.practices_groups;
+drop table if exists universities_data.lectures_groups;
+drop table if exists universities_data.practices;
+drop table if exists universities_data.lectures;
@@ -0,0 +1,7 @@
+drop table if exists universities_data.attendance_policies;
+
+alter table universities_data.classes
+    drop constraint if exists chk_classes_ends_after_start;
+
+alter table universities_data.classes
+    drop column if exists ends_at;
@@ -0,0 +1,25 @@
+-- конец занятия; NULL — занятие длится стандартное время (см. domain.DefaultClassDuration)
+alter table universities_data.classes
+    add column if not exists ends_at timestamptz;
+
+alter table universities_data.classes
+    add constraint chk_classes_ends_after_start check (ends_at IS NULL OR ends_at > date);
+
+-- пороги статуса посещаемости: для предмета или для департамента группы студента.
+-- Политика предмета важнее политики департамента, без обеих действуют значения из конфига.
+create table if not exists universities_data.attendance_policies (
+    id SERIAL PRIMARY KEY,
+    subject_id BIGINT UNIQUE,
+    department_id BIGINT UNIQUE,
+    late_after_seconds INT NOT NULL,
+    left_early_after_seconds INT NOT NULL,
+    min_coverage_percent INT NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    updated_by TEXT,
+    CHECK (num_nonnulls(subject_id, department_id) = 1),
+    CHECK (late_after_seconds >= 0 AND left_early_after_seconds >= 0),
+    CHECK (min_coverage_percent BETWEEN 0 AND 100),
+    foreign key (subject_id) references universities_data.subjects(id),
+    foreign key (department_id) references universities_data.departments(id),
+    foreign key (updated_by) references cores.users(isu)
+);
@@ -0,0 +1,5 @@
+alter table universities_data.classes
+    drop column if exists online;
+
+alter table universities_data.classes
+    drop column if exists room;
@@ -0,0 +1,6 @@
+-- аудитория занятия; у онлайн-занятий её может не быть
+alter table universities_data.classes
+    add column if not exists room TEXT;
+
+alter table universities_data.classes
+    add column if not exists online BOOLEAN NOT NULL DEFAULT false;
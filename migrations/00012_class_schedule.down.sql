@@ -0,0 +1,3 @@
+drop index if exists universities_data.idx_classes_date;
+drop table if exists visits.class_schedule_audit;
+drop table if exists visits.class_schedule_overrides;
@@ -0,0 +1,36 @@
+-- режим планировщика для занятия: manual — мониторинг запускают и останавливают только вручную.
+-- Занятия без строки здесь планировщик ведёт сам.
+create table if not exists visits.class_schedule_overrides (
+    kind VARCHAR(25) NOT NULL,
+    class_id BIGINT NOT NULL,
+    mode VARCHAR(25) NOT NULL,
+    reason TEXT,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    updated_by TEXT,
+    PRIMARY KEY (kind, class_id),
+    CHECK (mode IN ('auto', 'manual')),
+    foreign key (kind, class_id) references universities_data.classes(kind, id),
+    foreign key (updated_by) references cores.users(isu)
+);
+
+-- журнал расписания: кто менял режим занятия и что планировщик запускал и останавливал сам
+create table if not exists visits.class_schedule_audit (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(25) NOT NULL,
+    class_id BIGINT NOT NULL,
+    action VARCHAR(25) NOT NULL,
+    mode VARCHAR(25),
+    reason TEXT,
+    actor TEXT,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    CHECK (action IN ('set_mode', 'auto_start', 'auto_stop')),
+    foreign key (kind, class_id) references universities_data.classes(kind, id),
+    foreign key (actor) references cores.users(isu)
+);
+
+create index if not exists idx_class_schedule_audit_class
+    on visits.class_schedule_audit(kind, class_id, created_at);
+
+-- планировщик выбирает ближайшие занятия всех видов по времени начала
+create index if not exists idx_classes_date
+    on universities_data.classes(date);
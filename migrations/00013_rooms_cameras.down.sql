@@ -0,0 +1,8 @@
+alter table universities_data.classes
+    drop constraint if exists fk_classes_room;
+
+alter table universities_data.classes
+    alter column room type TEXT;
+
+drop table if exists universities_data.cameras;
+drop table if exists universities_data.rooms;
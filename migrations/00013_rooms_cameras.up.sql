@@ -0,0 +1,32 @@
+-- аудитории и камеры. queue — очередь камер аудитории (rabbit.mode = "queue")
+-- или routing key на topic exchange (rabbit.mode = "topic"); по нему запускается мониторинг занятия.
+create table if not exists universities_data.rooms (
+    code VARCHAR(50) PRIMARY KEY,
+    building TEXT,
+    capacity INT,
+    queue TEXT,
+    CHECK (capacity IS NULL OR capacity > 0)
+);
+
+-- id камеры совпадает с camera_id в сообщениях распознавания
+create table if not exists universities_data.cameras (
+    id TEXT PRIMARY KEY,
+    room_code VARCHAR(50) NOT NULL,
+    description TEXT,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    foreign key (room_code) references universities_data.rooms(code) on delete cascade
+);
+
+create index if not exists idx_cameras_room_code
+    on universities_data.cameras(room_code);
+
+-- аудитории, уже указанные у занятий, попадают в справочник без очереди
+insert into universities_data.rooms (code)
+select distinct room from universities_data.classes where room is not null
+on conflict (code) do nothing;
+
+alter table universities_data.classes
+    alter column room type VARCHAR(50);
+
+alter table universities_data.classes
+    add constraint fk_classes_room foreign key (room) references universities_data.rooms(code);
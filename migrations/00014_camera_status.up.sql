@@ -0,0 +1,15 @@
+-- состояние камер по heartbeat и camera_status из очереди распознавания.
+-- Без внешнего ключа на cameras: камера, которую ещё не добавили в справочник, тоже видна в статусе.
+create table if not exists visits.camera_status (
+    camera_id TEXT PRIMARY KEY,
+    last_seen_at timestamptz NOT NULL,
+    fps DOUBLE PRECISION,
+    -- последний статус, который камера прислала сама
+    status VARCHAR(25) NOT NULL DEFAULT 'ok',
+    error_count BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    last_error_at timestamptz,
+    -- когда подписчикам лекций ушло событие camera_down; сбрасывается, когда камера снова жива
+    down_notified_at timestamptz,
+    CHECK (status IN ('ok', 'degraded', 'down'))
+);
@@ -0,0 +1,2 @@
+drop table if exists visits.pending_sightings;
+drop table if exists universities_data.recognition_thresholds;
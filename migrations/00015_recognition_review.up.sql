@@ -0,0 +1,39 @@
+-- пороги similarity распознавания для департамента группы студента:
+-- не ниже accept_threshold — посещение, не ниже review_threshold — на проверку преподавателю, ниже — отбрасывается.
+-- Без строки здесь действуют пороги из конфига.
+create table if not exists universities_data.recognition_thresholds (
+    department_id BIGINT PRIMARY KEY,
+    accept_threshold DOUBLE PRECISION NOT NULL,
+    review_threshold DOUBLE PRECISION NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    updated_by TEXT,
+    CHECK (review_threshold >= 0 AND review_threshold <= accept_threshold AND accept_threshold <= 1),
+    foreign key (department_id) references universities_data.departments(id) on delete cascade,
+    foreign key (updated_by) references cores.users(isu)
+);
+
+-- снапшоты с similarity между порогами. В присутствии не учитываются, пока преподаватель
+-- не подтвердит их: подтверждённый снапшот копируется в visits.classes_visiting.
+create table if not exists visits.pending_sightings (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(25) NOT NULL,
+    class_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    captured_at timestamptz NOT NULL,
+    received_at timestamptz NOT NULL DEFAULT now(),
+    camera_id TEXT,
+    confidence REAL,
+    status VARCHAR(25) NOT NULL DEFAULT 'pending',
+    reviewed_by TEXT,
+    reviewed_at timestamptz,
+    CHECK (status IN ('pending', 'confirmed', 'rejected')),
+    foreign key (kind, class_id) references universities_data.classes(kind, id),
+    foreign key (user_id) references cores.users(isu),
+    foreign key (reviewed_by) references cores.users(isu)
+);
+
+create unique index if not exists uq_pending_sightings_class_user_date
+    on visits.pending_sightings(kind, class_id, user_id, captured_at);
+
+create index if not exists idx_pending_sightings_class_status
+    on visits.pending_sightings(kind, class_id, status);
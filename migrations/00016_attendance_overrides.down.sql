@@ -0,0 +1,2 @@
+drop table if exists visits.attendance_override_audit;
+drop table if exists visits.attendance_overrides;
@@ -0,0 +1,36 @@
+-- ручные отметки преподавателя: статус важнее посчитанного по снапшотам
+create table if not exists visits.attendance_overrides (
+    kind VARCHAR(25) NOT NULL,
+    class_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    status VARCHAR(25) NOT NULL,
+    reason TEXT NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    updated_by TEXT,
+    PRIMARY KEY (kind, class_id, user_id),
+    CHECK (status IN ('present', 'absent', 'excused')),
+    foreign key (kind, class_id) references universities_data.classes(kind, id),
+    foreign key (user_id) references cores.users(isu),
+    foreign key (updated_by) references cores.users(isu)
+);
+
+-- журнал ручных отметок: кто, когда и почему ставил или снимал отметку
+create table if not exists visits.attendance_override_audit (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(25) NOT NULL,
+    class_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    action VARCHAR(25) NOT NULL,
+    status VARCHAR(25),
+    previous_status VARCHAR(25),
+    reason TEXT,
+    actor TEXT,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    CHECK (action IN ('set', 'clear')),
+    foreign key (kind, class_id) references universities_data.classes(kind, id),
+    foreign key (user_id) references cores.users(isu),
+    foreign key (actor) references cores.users(isu)
+);
+
+create index if not exists idx_attendance_override_audit_class
+    on visits.attendance_override_audit(kind, class_id, created_at);
@@ -0,0 +1,2 @@
+delete from visits.camera_status where last_seen_at is null;
+alter table visits.camera_status alter column last_seen_at set not null;
@@ -0,0 +1,3 @@
+-- строка состояния заводится и для камеры из справочника, от которой не было ни одного сообщения:
+-- в ней хранится отметка о событии camera_down, last_seen_at остаётся пустым до первого heartbeat.
+alter table visits.camera_status alter column last_seen_at drop not null;
@@ -0,0 +1,4 @@
+delete from visits.class_schedule_audit where action in ('manual_start', 'manual_stop');
+alter table visits.class_schedule_audit drop constraint if exists class_schedule_audit_action_check;
+alter table visits.class_schedule_audit add constraint class_schedule_audit_action_check
+    check (action in ('set_mode', 'auto_start', 'auto_stop'));